@@ -1,8 +1,24 @@
 package main
 
-import "github.com/harshabose/socket-comm/pkg/transport/socket"
+import (
+	"context"
+	"log"
+
+	"github.com/harshabose/socket-comm/pkg/transport/socket"
+)
 
 func main() {
-	api := socket.NewAPI()
+	api, err := socket.NewAPI()
+	if err != nil {
+		log.Fatalf("error while creating api; err: %s", err.Error())
+	}
+
+	s, err := api.NewSocket(context.Background())
+	if err != nil {
+		log.Fatalf("error while creating socket; err: %s", err.Error())
+	}
 
+	if err := s.Serve(); err != nil {
+		log.Fatalf("error while serving; err: %s", err.Error())
+	}
 }
@@ -0,0 +1,212 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// DefaultBuffer is the number of events a subscription buffers before dropping
+const DefaultBuffer = 64
+
+var ErrBusClosed = errors.New("event bus closed")
+
+// Bus delivers the published events to the matching subscriptions. Publishing never blocks: every
+// subscription buffers up to its buffer size and drops the events published while its buffer is full.
+type Bus struct {
+	subscriptions []*Subscription
+	closed        bool
+	mux           sync.RWMutex
+}
+
+func NewBus() *Bus {
+	return &Bus{subscriptions: make([]*Subscription, 0)}
+}
+
+// Subscription receives the events of a bus matching its filters
+type Subscription struct {
+	bus     *Bus
+	events  chan Event
+	buffer  int
+	kinds   []Kind
+	clients []interceptor.ClientID
+	rooms   []string
+	filter  func(Event) bool
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+type Option = func(*Subscription) error
+
+// WithKinds only delivers events of the given kinds
+func WithKinds(kinds ...Kind) Option {
+	return func(s *Subscription) error {
+		s.kinds = append(s.kinds, kinds...)
+		return nil
+	}
+}
+
+// WithClients only delivers events of the given clients
+func WithClients(clients ...interceptor.ClientID) Option {
+	return func(s *Subscription) error {
+		s.clients = append(s.clients, clients...)
+		return nil
+	}
+}
+
+// WithRooms only delivers events of the given rooms
+func WithRooms(rooms ...string) Option {
+	return func(s *Subscription) error {
+		s.rooms = append(s.rooms, rooms...)
+		return nil
+	}
+}
+
+// WithFilter only delivers the events for which the filter returns true. The filter runs on the publisher's
+// goroutine; it must be fast and must not block.
+func WithFilter(filter func(Event) bool) Option {
+	return func(s *Subscription) error {
+		if filter == nil {
+			return errors.New("filter must not be nil")
+		}
+
+		s.filter = filter
+		return nil
+	}
+}
+
+// WithBuffer sets the number of events the subscription buffers before dropping
+func WithBuffer(size int) Option {
+	return func(s *Subscription) error {
+		if size <= 0 {
+			return fmt.Errorf("buffer must be positive; got %d", size)
+		}
+
+		s.buffer = size
+		return nil
+	}
+}
+
+// Subscribe creates a subscription to the events matching all the filters of the options
+func (b *Bus) Subscribe(options ...Option) (*Subscription, error) {
+	s := &Subscription{bus: b, buffer: DefaultBuffer}
+
+	for _, option := range options {
+		if err := option(s); err != nil {
+			return nil, err
+		}
+	}
+
+	s.events = make(chan Event, s.buffer)
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	b.subscriptions = append(b.subscriptions, s)
+	return s, nil
+}
+
+// SubscribeFunc calls the handler, on its own goroutine, with every event matching the options until the
+// subscription is closed
+func (b *Bus) SubscribeFunc(handler func(Event), options ...Option) (*Subscription, error) {
+	s, err := b.Subscribe(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for event := range s.events {
+			handler(event)
+		}
+	}()
+
+	return s, nil
+}
+
+// Publish delivers the event to the matching subscriptions without blocking
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	for _, s := range b.subscriptions {
+		if !s.matches(event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Close closes all the subscriptions; later subscriptions fail with ErrBusClosed
+func (b *Bus) Close() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.closed = true
+	for _, s := range b.subscriptions {
+		s.close()
+	}
+	b.subscriptions = nil
+
+	return nil
+}
+
+func (s *Subscription) matches(event Event) bool {
+	if len(s.kinds) > 0 && !slices.Contains(s.kinds, event.Kind) {
+		return false
+	}
+
+	if len(s.clients) > 0 && !slices.Contains(s.clients, event.Client) {
+		return false
+	}
+
+	if len(s.rooms) > 0 && !slices.Contains(s.rooms, event.Room) {
+		return false
+	}
+
+	return s.filter == nil || s.filter(event)
+}
+
+// Events returns the channel of the events; it is closed with the subscription
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the delivery of events and closes the events channel
+func (s *Subscription) Close() error {
+	s.bus.mux.Lock()
+	defer s.bus.mux.Unlock()
+
+	if index := slices.Index(s.bus.subscriptions, s); index >= 0 {
+		s.bus.subscriptions = slices.Delete(s.bus.subscriptions, index, index+1)
+	}
+	s.close()
+
+	return nil
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.events) })
+}
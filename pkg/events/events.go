@@ -0,0 +1,60 @@
+// Package events is the lifecycle event bus of the sockets. Sockets and interceptors publish events on the bus
+// carried by their context (see WithBus); applications subscribe to them, with filters, on the API's bus.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// Kind is the type of an event
+type Kind string
+
+const (
+	Connected          Kind = "connected"           // Connected is published when a connection is bound and initialised
+	Identified         Kind = "identified"          // Identified is published when the client of a connection is known
+	Disconnected       Kind = "disconnected"        // Disconnected is published when a connection is unbound
+	RoomCreated        Kind = "room_created"        // RoomCreated is published when a room is created
+	RoomDeleted        Kind = "room_deleted"        // RoomDeleted is published when a room is deleted, including on expiry
+	RoomJoined         Kind = "room_joined"         // RoomJoined is published when a client joins a room
+	RoomLeft           Kind = "room_left"           // RoomLeft is published when a client leaves a room
+	HandshakeCompleted Kind = "handshake_completed" // HandshakeCompleted is published when the key exchange of a connection completes
+	HandshakeFailed    Kind = "handshake_failed"    // HandshakeFailed is published when the key exchange of a connection fails
+)
+
+// Event is a lifecycle event. Fields which do not apply to the kind are left empty.
+type Event struct {
+	Kind       Kind                   `json:"kind"`
+	Time       time.Time              `json:"time"`             // Time is set by the bus when left empty
+	Source     interceptor.ClientID   `json:"source,omitempty"` // Source is the id of the connection or interceptor publishing
+	Connection interceptor.Connection `json:"-"`
+	Client     interceptor.ClientID   `json:"client,omitempty"` // Client is the id of the peer, once identified
+	Room       string                 `json:"room,omitempty"`
+	Err        error                  `json:"-"` // Err is the cause of failure events
+}
+
+type busKey struct{}
+
+// WithBus returns a context carrying the bus; the sockets and interceptors built with it publish to the bus
+func WithBus(ctx context.Context, bus *Bus) context.Context {
+	return context.WithValue(ctx, busKey{}, bus)
+}
+
+// BusFromContext returns the bus carried by the context, if any
+func BusFromContext(ctx context.Context) (*Bus, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	bus, ok := ctx.Value(busKey{}).(*Bus)
+	return bus, ok && bus != nil
+}
+
+// Publish publishes the event on the bus carried by the context; without one, the event is discarded
+func Publish(ctx context.Context, event Event) {
+	if bus, ok := BusFromContext(ctx); ok {
+		bus.Publish(event)
+	}
+}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+func subscribe(t *testing.T, bus *Bus, options ...Option) *Subscription {
+	t.Helper()
+
+	s, err := bus.Subscribe(options...)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+// received returns the events buffered in the subscription
+func received(s *Subscription) []Event {
+	events := make([]Event, 0)
+	for {
+		select {
+		case event := <-s.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	published := []Event{
+		{Kind: Connected},
+		{Kind: Identified, Client: "alice"},
+		{Kind: RoomJoined, Client: "alice", Room: "lobby"},
+		{Kind: RoomJoined, Client: "bob", Room: "lobby"},
+		{Kind: RoomLeft, Client: "alice", Room: "games"},
+		{Kind: HandshakeFailed, Err: errors.New("bad key")},
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    int
+	}{
+		{name: "all", want: 6},
+		{name: "kinds", options: []Option{WithKinds(RoomJoined, RoomLeft)}, want: 3},
+		{name: "clients", options: []Option{WithClients("alice")}, want: 3},
+		{name: "rooms", options: []Option{WithRooms("lobby")}, want: 2},
+		{name: "combined", options: []Option{WithKinds(RoomJoined), WithClients("bob")}, want: 1},
+		{name: "custom", options: []Option{WithFilter(func(e Event) bool { return e.Err != nil })}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+			s := subscribe(t, bus, tt.options...)
+
+			for _, event := range published {
+				bus.Publish(event)
+			}
+
+			got := received(s)
+			if len(got) != tt.want {
+				t.Errorf("received %d events; want %d", len(got), tt.want)
+			}
+
+			for _, event := range got {
+				if event.Time.IsZero() {
+					t.Errorf("event %s has no time", event.Kind)
+				}
+			}
+		})
+	}
+}
+
+func TestBoundedBuffer(t *testing.T) {
+	bus := NewBus()
+	s := subscribe(t, bus, WithBuffer(2))
+
+	for range 5 {
+		bus.Publish(Event{Kind: Connected})
+	}
+
+	if got := len(received(s)); got != 2 {
+		t.Errorf("received %d events; want the 2 buffered", got)
+	}
+
+	if got := s.Dropped(); got != 3 {
+		t.Errorf("Dropped() = %d; want 3", got)
+	}
+}
+
+func TestSubscribeFunc(t *testing.T) {
+	bus := NewBus()
+
+	got := make(chan Event, 1)
+	s, err := bus.SubscribeFunc(func(event Event) { got <- event }, WithKinds(Disconnected))
+	if err != nil {
+		t.Fatalf("SubscribeFunc() error = %v", err)
+	}
+	defer s.Close()
+
+	bus.Publish(Event{Kind: Connected})
+	bus.Publish(Event{Kind: Disconnected, Client: "alice"})
+
+	select {
+	case event := <-got:
+		if event.Kind != Disconnected || event.Client != "alice" {
+			t.Errorf("handler got %+v; want the disconnected event of alice", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+}
+
+func TestClose(t *testing.T) {
+	bus := NewBus()
+	first, second := subscribe(t, bus), subscribe(t, bus)
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	bus.Publish(Event{Kind: Connected})
+
+	if _, open := <-first.Events(); open {
+		t.Error("closed subscription received an event")
+	}
+
+	if got := len(received(second)); got != 1 {
+		t.Errorf("open subscription received %d events; want 1", got)
+	}
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, open := <-second.Events(); open {
+		t.Error("subscription not closed with the bus")
+	}
+
+	if _, err := bus.Subscribe(); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Subscribe() on a closed bus error = %v; want %v", err, ErrBusClosed)
+	}
+
+	// NOTE: PUBLISHING ON A CLOSED BUS IS A NO-OP
+	bus.Publish(Event{Kind: Connected})
+}
+
+func TestContext(t *testing.T) {
+	// NOTE: WITHOUT A BUS, EVENTS ARE DISCARDED
+	Publish(context.Background(), Event{Kind: Connected})
+
+	bus := NewBus()
+	s := subscribe(t, bus)
+
+	ctx := WithBus(context.Background(), bus)
+	if got, ok := BusFromContext(ctx); !ok || got != bus {
+		t.Fatalf("BusFromContext() = %v, %t; want the bus", got, ok)
+	}
+
+	Publish(ctx, Event{Kind: Identified, Source: interceptor.ClientID("socket"), Client: "alice"})
+
+	if got := received(s); len(got) != 1 || got[0].Client != "alice" || got[0].Source != "socket" {
+		t.Errorf("received %+v; want the identified event of alice", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	bus := NewBus()
+
+	if _, err := bus.Subscribe(WithBuffer(0)); err == nil {
+		t.Error("Subscribe(WithBuffer(0)) error = nil; want an error")
+	}
+
+	if _, err := bus.Subscribe(WithFilter(nil)); err == nil {
+		t.Error("Subscribe(WithFilter(nil)) error = nil; want an error")
+	}
+}
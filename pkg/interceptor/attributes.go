@@ -0,0 +1,153 @@
+package interceptor
+
+import (
+	"sync"
+)
+
+// Key identifies an attribute of type T. Keys are compared by identity, so two keys created with the same
+// name are different; declare keys once, as package-level variables, and share them.
+type Key[T any] struct {
+	name string
+}
+
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// Attributes is the concurrency-safe attribute store of a connection, shared by all the interceptors bound to
+// it. The chain attaches the store when it binds the connection and detaches it when it unbinds it.
+// A nil *Attributes is empty and ignores writes, so the attributes of a connection which is not bound, or
+// was already unbound, can be used without checks.
+type Attributes struct {
+	values map[any]any
+	mux    sync.RWMutex
+}
+
+func NewAttributes() *Attributes {
+	return &Attributes{values: make(map[any]any)}
+}
+
+// Get returns the attribute of the key
+func Get[T any](attributes *Attributes, key *Key[T]) (T, bool) {
+	var zero T
+	if attributes == nil {
+		return zero, false
+	}
+
+	attributes.mux.RLock()
+	defer attributes.mux.RUnlock()
+
+	value, ok := attributes.values[key]
+	if !ok {
+		return zero, false
+	}
+
+	return value.(T), true
+}
+
+// Set sets the attribute of the key
+func Set[T any](attributes *Attributes, key *Key[T], value T) {
+	if attributes == nil {
+		return
+	}
+
+	attributes.mux.Lock()
+	defer attributes.mux.Unlock()
+
+	attributes.values[key] = value
+}
+
+// Update atomically replaces the attribute of the key with the result of update, which gets the current
+// value and whether it is set; it returns the new value
+func Update[T any](attributes *Attributes, key *Key[T], update func(T, bool) T) T {
+	var zero T
+	if attributes == nil {
+		return update(zero, false)
+	}
+
+	attributes.mux.Lock()
+	defer attributes.mux.Unlock()
+
+	current := zero
+	value, ok := attributes.values[key]
+	if ok {
+		current = value.(T)
+	}
+
+	current = update(current, ok)
+	attributes.values[key] = current
+
+	return current
+}
+
+// Delete removes the attribute of the key
+func Delete[T any](attributes *Attributes, key *Key[T]) {
+	if attributes == nil {
+		return
+	}
+
+	attributes.mux.Lock()
+	defer attributes.mux.Unlock()
+
+	delete(attributes.values, key)
+}
+
+// attachment is the attribute store of a bound connection and the number of chains bound to it
+type attachment struct {
+	attributes *Attributes
+	bindings   int
+}
+
+// attached holds the attachments of the bound connections
+var attached = struct {
+	connections map[Connection]*attachment
+	mux         sync.RWMutex
+}{connections: make(map[Connection]*attachment)}
+
+// AttributesOf returns the attribute store of the connection; it is nil if the connection is not bound
+func AttributesOf(connection Connection) *Attributes {
+	attached.mux.RLock()
+	defer attached.mux.RUnlock()
+
+	if a, ok := attached.connections[connection]; ok {
+		return a.attributes
+	}
+
+	return nil
+}
+
+// AttachAttributes attaches an attribute store to the connection, or returns the one already attached.
+// The chain calls it on BindSocketConnection; interceptors bound without a chain (in tests, for example)
+// must attach and detach the store themselves.
+func AttachAttributes(connection Connection) *Attributes {
+	attached.mux.Lock()
+	defer attached.mux.Unlock()
+
+	a, ok := attached.connections[connection]
+	if !ok {
+		a = &attachment{attributes: NewAttributes()}
+		attached.connections[connection] = a
+	}
+	a.bindings++
+
+	return a.attributes
+}
+
+// DetachAttributes releases the attribute store of the connection; it is dropped once every attach is released
+func DetachAttributes(connection Connection) {
+	attached.mux.Lock()
+	defer attached.mux.Unlock()
+
+	a, ok := attached.connections[connection]
+	if !ok {
+		return
+	}
+
+	if a.bindings--; a.bindings <= 0 {
+		delete(attached.connections, connection)
+	}
+}
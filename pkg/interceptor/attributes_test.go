@@ -0,0 +1,146 @@
+package interceptor
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type attributeConnection struct {
+	testConnection
+	name string
+}
+
+var (
+	countKey = NewKey[int]("count")
+	nameKey  = NewKey[string]("name")
+	errKey   = NewKey[error]("err")
+)
+
+func TestAttributes_GetSet(t *testing.T) {
+	attributes := NewAttributes()
+
+	if _, ok := Get(attributes, countKey); ok {
+		t.Error("Get() of an unset key ok = true")
+	}
+
+	Set(attributes, countKey, 1)
+	Set(attributes, nameKey, "alice")
+
+	if got, ok := Get(attributes, countKey); !ok || got != 1 {
+		t.Errorf("Get(count) = %d, %t; want 1, true", got, ok)
+	}
+
+	if got, ok := Get(attributes, nameKey); !ok || got != "alice" {
+		t.Errorf("Get(name) = %q, %t; want alice, true", got, ok)
+	}
+
+	// NOTE: KEYS ARE COMPARED BY IDENTITY, NOT BY NAME
+	if _, ok := Get(attributes, NewKey[int]("count")); ok {
+		t.Error("Get() with another key of the same name ok = true")
+	}
+
+	Delete(attributes, countKey)
+	if _, ok := Get(attributes, countKey); ok {
+		t.Error("Get() after Delete() ok = true")
+	}
+}
+
+func TestAttributes_Update(t *testing.T) {
+	attributes := NewAttributes()
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Update(attributes, countKey, func(count int, _ bool) int { return count + 1 })
+		}()
+	}
+	wg.Wait()
+
+	if got, _ := Get(attributes, countKey); got != 100 {
+		t.Errorf("Get() after 100 updates = %d; want 100", got)
+	}
+
+	// NOTE: THE ZERO VALUE OF AN INTERFACE TYPE IS NIL
+	errUpdate := errors.New("update")
+	got := Update(attributes, errKey, func(err error, ok bool) error {
+		if ok || err != nil {
+			t.Errorf("update got %v, %t; want nil, false", err, ok)
+		}
+		return errUpdate
+	})
+	if got != errUpdate {
+		t.Errorf("Update() = %v; want %v", got, errUpdate)
+	}
+}
+
+func TestAttributes_Nil(t *testing.T) {
+	var attributes *Attributes
+
+	Set(attributes, countKey, 1)
+	Delete(attributes, countKey)
+
+	if _, ok := Get(attributes, countKey); ok {
+		t.Error("Get() on nil attributes ok = true")
+	}
+
+	if got := Update(attributes, countKey, func(count int, _ bool) int { return count + 1 }); got != 1 {
+		t.Errorf("Update() on nil attributes = %d; want 1", got)
+	}
+}
+
+func TestChain_Attributes(t *testing.T) {
+	connection := &attributeConnection{name: "attributes"}
+	layers, chain := newLayers("a", "b")
+	socket := &socketEnd{}
+
+	if AttributesOf(connection) != nil {
+		t.Fatal("AttributesOf() before binding is not nil")
+	}
+
+	if _, _, err := chain.BindSocketConnection(connection, socket, socket); err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	// NOTE: ANY LAYER SEES THE ATTRIBUTES SET BY ANOTHER
+	Set(AttributesOf(connection), nameKey, layers[0].name)
+	if got, ok := Get(AttributesOf(connection), nameKey); !ok || got != "a" {
+		t.Errorf("Get() = %q, %t; want a, true", got, ok)
+	}
+
+	chain.UnBindSocketConnection(connection)
+
+	if AttributesOf(connection) != nil {
+		t.Error("AttributesOf() after unbinding is not nil")
+	}
+
+	layers[1].bindErr = errors.New("bind failed")
+	if _, _, err := chain.BindSocketConnection(connection, socket, socket); err == nil {
+		t.Fatal("BindSocketConnection() error = nil; want the bind error")
+	}
+
+	if AttributesOf(connection) != nil {
+		t.Error("AttributesOf() after a failed bind is not nil")
+	}
+}
+
+func TestAttachAttributes(t *testing.T) {
+	connection := &attributeConnection{name: "attach"}
+
+	first := AttachAttributes(connection)
+	if second := AttachAttributes(connection); second != first {
+		t.Error("AttachAttributes() twice returned different stores")
+	}
+
+	DetachAttributes(connection)
+	if AttributesOf(connection) != first {
+		t.Error("store dropped before every attach was released")
+	}
+
+	DetachAttributes(connection)
+	if AttributesOf(connection) != nil {
+		t.Error("store not dropped after every attach was released")
+	}
+}
@@ -1,37 +1,161 @@
 package interceptor
 
-import "github.com/harshabose/socket-comm/internal/util"
+import (
+	"fmt"
+	"sync"
+
+	"github.com/harshabose/socket-comm/internal/util"
+)
 
 type Chain struct {
 	NoOpInterceptor
 	interceptors []Interceptor
+	names        []string
+	policies     []ErrorPolicy
+	counters     []errorCounters
+	selector     Selector
+	switches     map[Connection]*switches
+	mux          sync.Mutex
 }
 
+// CreateChain chains the interceptors; index 0 is the closest to the socket. The interceptors are named
+// after their types and return their errors (see ErrorPolicy) until described by the registry.
 func CreateChain(interceptors []Interceptor) *Chain {
-	return &Chain{interceptors: interceptors}
+	chain := &Chain{
+		interceptors: interceptors,
+		names:        make([]string, len(interceptors)),
+		policies:     make([]ErrorPolicy, len(interceptors)),
+		counters:     make([]errorCounters, len(interceptors)),
+		switches:     make(map[Connection]*switches),
+	}
+
+	for index, interceptor := range interceptors {
+		chain.names[index] = fmt.Sprintf("%T#%d", interceptor, index)
+	}
+
+	return chain
 }
 
+// describe names the interceptors and sets their error policies; descriptors are indexed like the interceptors
+func (chain *Chain) describe(descriptors []Descriptor, overrides map[string]ErrorPolicy) {
+	for index, descriptor := range descriptors {
+		chain.names[index] = descriptor.Name
+		chain.policies[index] = descriptor.ErrorPolicy
+
+		if policy, ok := overrides[descriptor.Name]; ok {
+			chain.policies[index] = policy
+		}
+	}
+}
+
+// ErrorStats returns the errors handled by the chain, by interceptor name
+func (chain *Chain) ErrorStats() map[string]ErrorStats {
+	stats := make(map[string]ErrorStats, len(chain.interceptors))
+	for index, name := range chain.names {
+		stats[name] = chain.counters[index].stats()
+	}
+
+	return stats
+}
+
+// BindSocketConnection binds the interceptors from the socket outwards. Each interceptor receives the
+// writer and reader intercepted by the layers beneath it only, and the result is wrapped by the interceptor
+// before it is handed to the next layer. The returned writer and reader traverse the whole chain and apply
+// the error policy of every layer (see ErrorPolicy). If an interceptor fails (or panics) while binding, the
+// layers already bound are unbound. The attribute store of the connection (see Attributes) is attached
+// before the first layer binds. Every layer is bound, even if the selector disables it for the connection,
+// so that it can be enabled later (see Chain.Enable); a disabled layer is passed by.
 func (chain *Chain) BindSocketConnection(connection Connection, writer Writer, reader Reader) (Writer, Reader, error) {
-	var (
-		w   Writer
-		r   Reader
-		err error
-	)
+	chain.mux.Lock()
+	selector := chain.selector
+	chain.mux.Unlock()
 
-	for _, interceptor := range chain.interceptors {
-		if w, r, err = interceptor.BindSocketConnection(connection, chain.InterceptSocketWriter(writer), chain.InterceptSocketReader(reader)); err != nil {
-			return nil, nil, err
+	// NOTE: THE SELECTOR IS CALLED WITHOUT THE LOCK; IT MAY USE THE CHAIN
+	s := newSwitches(chain.names, selector, connection)
+
+	chain.mux.Lock()
+	if _, exists := chain.switches[connection]; exists {
+		chain.mux.Unlock()
+		return nil, nil, ErrConnectionExists
+	}
+	chain.switches[connection] = s
+	chain.mux.Unlock()
+
+	AttachAttributes(connection)
+
+	for index, interceptor := range chain.interceptors {
+		var (
+			w Writer
+			r Reader
+		)
+
+		err := protect(&chain.counters[index], func() (err error) {
+			w, r, err = interceptor.BindSocketConnection(connection, writer, reader)
+			return err
+		})
+		if err != nil {
+			for i := index - 1; i >= 0; i-- {
+				chain.interceptors[i].UnBindSocketConnection(connection)
+			}
+			DetachAttributes(connection)
+			chain.unswitch(connection)
+			return nil, nil, fmt.Errorf("error while binding interceptor %s; err: %w", chain.names[index], err)
 		}
+
+		// NOTE: INTERCEPTORS MAY RETURN NIL TO KEEP THE GIVEN WRITER OR READER
+		if w != nil {
+			writer = w
+		}
+		if r != nil {
+			reader = r
+		}
+
+		g := &guard{
+			name:     chain.names[index],
+			policy:   chain.policies[index],
+			counters: &chain.counters[index],
+			below:    writer,
+			belowR:   reader,
+		}
+
+		w, r = g.beneath()
+		writer = g.writer(s.writer(index, interceptor.InterceptSocketWriter(w), w))
+		reader = g.reader(s.reader(index, interceptor.InterceptSocketReader(r), r))
 	}
 
-	return w, r, nil
+	return writer, reader, nil
 }
 
+// Init initialises the interceptors enabled for the connection; the disabled ones are initialised when they
+// are enabled (see Chain.Enable).
 func (chain *Chain) Init(connection Connection) error {
-	for _, interceptor := range chain.interceptors {
-		if err := interceptor.Init(connection); err != nil {
-			return err
+	chain.mux.Lock()
+	s, bound := chain.switches[connection]
+	chain.mux.Unlock()
+
+	if bound {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+	}
+
+	for index, interceptor := range chain.interceptors {
+		if bound && !s.enabled[index].Load() {
+			continue
 		}
+
+		if err := protect(&chain.counters[index], func() error {
+			return interceptor.Init(connection)
+		}); err != nil {
+			return fmt.Errorf("error while initialising interceptor %s; err: %w", chain.names[index], err)
+		}
+
+		if bound {
+			s.initialised[index] = true
+		}
+	}
+
+	if bound {
+		s.inited = true
 	}
 
 	return nil
@@ -53,10 +177,22 @@ func (chain *Chain) InterceptSocketReader(reader Reader) Reader {
 	return reader
 }
 
+// UnBindSocketConnection unbinds the interceptors, then detaches the attribute store of the connection and
+// forgets which interceptors were enabled for it
 func (chain *Chain) UnBindSocketConnection(connection Connection) {
 	for _, interceptor := range chain.interceptors {
 		interceptor.UnBindSocketConnection(connection)
 	}
+
+	DetachAttributes(connection)
+	chain.unswitch(connection)
+}
+
+func (chain *Chain) unswitch(connection Connection) {
+	chain.mux.Lock()
+	defer chain.mux.Unlock()
+
+	delete(chain.switches, connection)
 }
 
 func (chain *Chain) Close() error {
@@ -0,0 +1,210 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const traversalProtocol message.Protocol = "test:traversal"
+
+// traversal records the layers a message passed through
+type traversal struct {
+	message.BaseMessage
+	Layers []string
+}
+
+func (t *traversal) GetProtocol() message.Protocol {
+	return traversalProtocol
+}
+
+type testConnection struct{}
+
+func (testConnection) Write(context.Context, []byte) error  { return nil }
+func (testConnection) Read(context.Context) ([]byte, error) { return nil, nil }
+func (testConnection) Close() error                         { return nil }
+
+// layer is an interceptor which stamps its name on the messages it intercepts and keeps the
+// writer and reader it was bound with
+type layer struct {
+	NoOpInterceptor
+	name         string
+	bindErr      error
+	initErr      error
+	inits        int
+	writeErr     error
+	readErr      error
+	readFailures int // number of reads failing with readErr
+	panicOnWrite any
+	writer       Writer
+	reader       Reader
+	unbound      bool
+}
+
+func (l *layer) BindSocketConnection(_ Connection, writer Writer, reader Reader) (Writer, Reader, error) {
+	if l.bindErr != nil {
+		return nil, nil, l.bindErr
+	}
+
+	l.writer, l.reader = writer, reader
+	return nil, nil, nil
+}
+
+func (l *layer) Init(_ Connection) error {
+	l.inits++
+	return l.initErr
+}
+
+func (l *layer) InterceptSocketWriter(writer Writer) Writer {
+	return WriterFunc(func(ctx context.Context, connection Connection, msg message.Message) error {
+		if l.panicOnWrite != nil {
+			panic(l.panicOnWrite)
+		}
+
+		if l.writeErr != nil {
+			return l.writeErr
+		}
+
+		if t, ok := msg.(*traversal); ok {
+			t.Layers = append(t.Layers, l.name)
+		}
+
+		return writer.Write(ctx, connection, msg)
+	})
+}
+
+func (l *layer) InterceptSocketReader(reader Reader) Reader {
+	return ReaderFunc(func(ctx context.Context, connection Connection) (message.Message, error) {
+		msg, err := reader.Read(ctx, connection)
+		if err != nil {
+			return nil, err
+		}
+
+		if l.readFailures > 0 {
+			l.readFailures--
+			return nil, l.readErr
+		}
+
+		t := msg.(*traversal)
+		t.Layers = append(t.Layers, l.name)
+		return t, nil
+	})
+}
+
+func (l *layer) UnBindSocketConnection(_ Connection) {
+	l.unbound = true
+}
+
+// socketEnd is the writer and reader of the socket beneath the chain
+type socketEnd struct {
+	written []message.Message
+	reads   int
+}
+
+func (s *socketEnd) Write(_ context.Context, _ Connection, msg message.Message) error {
+	s.written = append(s.written, msg)
+	return nil
+}
+
+func (s *socketEnd) Read(_ context.Context, _ Connection) (message.Message, error) {
+	s.reads++
+	return &traversal{}, nil
+}
+
+func (s *socketEnd) last() *traversal {
+	return s.written[len(s.written)-1].(*traversal)
+}
+
+func newLayers(names ...string) ([]*layer, *Chain) {
+	layers := make([]*layer, 0, len(names))
+	interceptors := make([]Interceptor, 0, len(names))
+
+	for _, name := range names {
+		l := &layer{name: name}
+		layers = append(layers, l)
+		interceptors = append(interceptors, l)
+	}
+
+	return layers, CreateChain(interceptors)
+}
+
+func TestChain_BindSocketConnection_LayersSeeOnlyLayersBeneath(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		connection = testConnection{}
+		socket     = &socketEnd{}
+	)
+
+	// NOTE: INDEX 0 IS THE CLOSEST TO THE SOCKET
+	layers, chain := newLayers("socket-side", "middle", "app-side")
+
+	writer, reader, err := chain.BindSocketConnection(connection, socket, socket)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	for n, l := range layers {
+		var below []string
+		for i := n - 1; i >= 0; i-- {
+			below = append(below, layers[i].name)
+		}
+
+		if err := l.writer.Write(ctx, connection, &traversal{}); err != nil {
+			t.Fatalf("layer %s: Write() error = %v", l.name, err)
+		}
+
+		if got := socket.last().Layers; !slices.Equal(got, below) {
+			t.Errorf("layer %s: written through %v, want %v", l.name, got, below)
+		}
+
+		msg, err := l.reader.Read(ctx, connection)
+		if err != nil {
+			t.Fatalf("layer %s: Read() error = %v", l.name, err)
+		}
+
+		slices.Reverse(below)
+		if got := msg.(*traversal).Layers; !slices.Equal(got, below) {
+			t.Errorf("layer %s: read through %v, want %v", l.name, got, below)
+		}
+	}
+
+	if err := writer.Write(ctx, connection, &traversal{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if got, want := socket.last().Layers, []string{"app-side", "middle", "socket-side"}; !slices.Equal(got, want) {
+		t.Errorf("chain writer: written through %v, want %v", got, want)
+	}
+
+	msg, err := reader.Read(ctx, connection)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got, want := msg.(*traversal).Layers, []string{"socket-side", "middle", "app-side"}; !slices.Equal(got, want) {
+		t.Errorf("chain reader: read through %v, want %v", got, want)
+	}
+}
+
+func TestChain_BindSocketConnection_UnbindsOnFailure(t *testing.T) {
+	errBind := errors.New("bind failed")
+
+	layers, chain := newLayers("a", "b", "c")
+	layers[1].bindErr = errBind
+
+	socket := &socketEnd{}
+	if _, _, err := chain.BindSocketConnection(testConnection{}, socket, socket); !errors.Is(err, errBind) {
+		t.Fatalf("BindSocketConnection() error = %v, want %v", err, errBind)
+	}
+
+	if !layers[0].unbound {
+		t.Errorf("layer a bound before the failure was not unbound")
+	}
+
+	if layers[2].unbound || layers[2].writer != nil {
+		t.Errorf("layer c after the failure was touched")
+	}
+}
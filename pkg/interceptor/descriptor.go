@@ -0,0 +1,224 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Descriptor declares the place of an interceptor in the chain.
+//
+// The chain is ordered along the write path: messages written by the application pass through the
+// interceptors from the first to the last, which is the closest to the socket; read messages pass
+// through them in the opposite order. For example, chat runs before encrypt so that the chat messages
+// are encrypted, and fragment runs after encrypt so that the encrypted envelopes are fragmented.
+type Descriptor struct {
+	// Name identifies the interceptor in the constraints of the other interceptors; it must be unique
+	Name string
+	// Priority orders the interceptors which are not ordered by constraints: lower priorities run first.
+	// Interceptors with equal priorities are chained in their registration order from the socket outwards,
+	// like a chain of factories without descriptors: the first registered is the closest to the socket.
+	Priority int
+	// Before lists the interceptors this one must run before (further from the socket)
+	Before []string
+	// After lists the interceptors this one must run after (closer to the socket)
+	After []string
+	// Optional lists the interceptors of Before and After which may be left unregistered; their constraints
+	// apply only if they are registered. The others must be registered, so that a misspelt name fails the
+	// ordering instead of being ignored.
+	Optional []string
+	// Requires lists the interceptors which must be registered for this one to work
+	Requires []string
+	// ErrorPolicy is the default error policy of the interceptor; Registry.SetErrorPolicy overrides it
+	ErrorPolicy ErrorPolicy
+}
+
+// Described is implemented by the factories which declare their place in the chain
+type Described interface {
+	Descriptor() Descriptor
+}
+
+// describedFactory attaches a descriptor to a factory which does not declare one
+type describedFactory struct {
+	Factory
+	descriptor Descriptor
+}
+
+func (f describedFactory) Descriptor() Descriptor {
+	return f.descriptor
+}
+
+// Describe attaches the descriptor to the factory, overriding the descriptor it declares, if any.
+func Describe(factory Factory, descriptor Descriptor) Factory {
+	return describedFactory{Factory: factory, descriptor: descriptor}
+}
+
+// DescriptorOf returns the descriptor declared by the factory. Factories without one are named
+// after their type and index in the registry and have no constraints.
+func DescriptorOf(factory Factory, index int) Descriptor {
+	if d, ok := factory.(Described); ok {
+		descriptor := d.Descriptor()
+		if descriptor.Name != "" {
+			return descriptor
+		}
+	}
+
+	return Descriptor{Name: fmt.Sprintf("%T#%d", factory, index)}
+}
+
+// sortFactories returns the indices of the factories along the write path (see Descriptor) and their
+// descriptors. It fails on nil factories, duplicate names, missing requirements, constraints on unregistered
+// interceptors not listed as optional and cyclic constraints.
+func sortFactories(factories []Factory) ([]int, []Descriptor, error) {
+	var (
+		descriptors = make([]Descriptor, len(factories))
+		index       = make(map[string]int, len(factories))
+	)
+
+	for i, factory := range factories {
+		if factory == nil {
+			return nil, nil, fmt.Errorf("error while ordering interceptor #%d; err: %w", i, ErrNilFactory)
+		}
+
+		descriptors[i] = DescriptorOf(factory, i)
+		if _, exists := index[descriptors[i].Name]; exists {
+			return nil, nil, fmt.Errorf("error while ordering interceptor %s; err: %w", descriptors[i].Name, ErrDuplicateName)
+		}
+		index[descriptors[i].Name] = i
+	}
+
+	// edges[i] lists the interceptors which must run after i; pending[i] counts the ones which must run before i
+	var (
+		edges   = make([][]int, len(factories))
+		pending = make([]int, len(factories))
+	)
+
+	addEdge := func(first, then int) {
+		edges[first] = append(edges[first], then)
+		pending[then]++
+	}
+
+	for i, descriptor := range descriptors {
+		for _, name := range descriptor.Requires {
+			if _, exists := index[name]; !exists {
+				return nil, nil, fmt.Errorf("error while ordering interceptor %s; requires %s; err: %w", descriptor.Name, name, ErrMissingDependency)
+			}
+		}
+
+		for _, name := range descriptor.Before {
+			j, exists := index[name]
+			if !exists {
+				if !slices.Contains(descriptor.Optional, name) {
+					return nil, nil, fmt.Errorf("error while ordering interceptor %s; runs before %s; err: %w", descriptor.Name, name, ErrUnknownInterceptor)
+				}
+				continue
+			}
+			addEdge(i, j)
+		}
+
+		for _, name := range descriptor.After {
+			j, exists := index[name]
+			if !exists {
+				if !slices.Contains(descriptor.Optional, name) {
+					return nil, nil, fmt.Errorf("error while ordering interceptor %s; runs after %s; err: %w", descriptor.Name, name, ErrUnknownInterceptor)
+				}
+				continue
+			}
+			addEdge(j, i)
+		}
+	}
+
+	// NOTE: KAHN'S ALGORITHM; AMONG THE READY INTERCEPTORS THE LOWEST PRIORITY GOES FIRST, THEN THE LAST REGISTERED,
+	// NOTE: SO THAT THE FIRST REGISTERED ENDS UP THE CLOSEST TO THE SOCKET
+	ready := make([]int, 0, len(factories))
+	for i := range factories {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	less := func(a, b int) bool {
+		if descriptors[a].Priority != descriptors[b].Priority {
+			return descriptors[a].Priority < descriptors[b].Priority
+		}
+		return a > b
+	}
+
+	sorted := make([]int, 0, len(factories))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(a, b int) bool { return less(ready[a], ready[b]) })
+
+		next := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, next)
+
+		for _, then := range edges[next] {
+			pending[then]--
+			if pending[then] == 0 {
+				ready = append(ready, then)
+			}
+		}
+	}
+
+	if len(sorted) != len(factories) {
+		cyclic := make([]string, 0)
+		for i, count := range pending {
+			if count > 0 {
+				cyclic = append(cyclic, descriptors[i].Name)
+			}
+		}
+
+		return nil, nil, fmt.Errorf("error while ordering interceptors %s; err: %w", strings.Join(cyclic, ", "), ErrInterceptorCycle)
+	}
+
+	return sorted, descriptors, nil
+}
+
+// Order returns the names of the registered interceptors along the write path, as Build chains them.
+func (registry *Registry) Order() ([]string, error) {
+	sorted, descriptors, err := sortFactories(registry.factories)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sorted))
+	for _, i := range sorted {
+		names = append(names, descriptors[i].Name)
+	}
+
+	return names, nil
+}
+
+// build creates the interceptors along the write path and returns them with their descriptors
+func (registry *Registry) build(ctx context.Context, id ClientID, messages message.Registry) ([]Interceptor, []Descriptor, []*layerReporter, error) {
+	sorted, descriptors, err := sortFactories(registry.factories)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		interceptors = make([]Interceptor, 0, len(sorted))
+		ordered      = make([]Descriptor, 0, len(sorted))
+		reporters    = make([]*layerReporter, 0, len(sorted))
+	)
+
+	for _, i := range sorted {
+		// NOTE: EVERY INTERCEPTOR GETS ITS OWN REPORTER SO THAT ITS PANICS ARE COUNTED IN ITS LAYER (SEE ReportPanic)
+		reporter := &layerReporter{}
+
+		interceptor, err := registry.factories[i].NewInterceptor(context.WithValue(ctx, layerReporterKey{}, reporter), id, messages)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error while building interceptor %s; err: %w", descriptors[i].Name, err)
+		}
+
+		interceptors = append(interceptors, interceptor)
+		ordered = append(ordered, descriptors[i])
+		reporters = append(reporters, reporter)
+	}
+
+	return interceptors, ordered, reporters, nil
+}
@@ -0,0 +1,219 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type testFactory struct {
+	built *[]string
+	name  string
+}
+
+func (f testFactory) NewInterceptor(ctx context.Context, id ClientID, registry message.Registry) (Interceptor, error) {
+	if f.built != nil {
+		*f.built = append(*f.built, f.name)
+	}
+
+	i := NewNoOpInterceptor(ctx, id, registry)
+	return &i, nil
+}
+
+func described(name string, priority int, before, after, requires []string) Factory {
+	return Describe(testFactory{name: name}, Descriptor{
+		Name:     name,
+		Priority: priority,
+		Before:   before,
+		After:    after,
+		Requires: requires,
+	})
+}
+
+func optional(name string, before, after, optional []string) Factory {
+	return Describe(testFactory{name: name}, Descriptor{
+		Name:     name,
+		Before:   before,
+		After:    after,
+		Optional: optional,
+	})
+}
+
+func TestRegistry_Order(t *testing.T) {
+	tests := []struct {
+		name      string
+		factories []Factory
+		want      []string
+		wantErr   error
+	}{
+		{
+			name: "first registered closest to the socket without constraints",
+			factories: []Factory{
+				described("a", 0, nil, nil, nil),
+				described("b", 0, nil, nil, nil),
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "priority breaks ties",
+			factories: []Factory{
+				described("a", 10, nil, nil, nil),
+				described("b", 0, nil, nil, nil),
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "constraints override priority",
+			factories: []Factory{
+				described("fragment", -10, nil, []string{"encrypt"}, nil),
+				described("encrypt", 0, nil, nil, nil),
+				described("chat", 10, []string{"encrypt"}, nil, nil),
+			},
+			want: []string{"chat", "encrypt", "fragment"},
+		},
+		{
+			name: "unregistered optional constraints are ignored",
+			factories: []Factory{
+				optional("chat", []string{"encrypt"}, []string{"auth"}, []string{"encrypt", "auth"}),
+			},
+			want: []string{"chat"},
+		},
+		{
+			name: "registered optional constraints apply",
+			factories: []Factory{
+				described("encrypt", 0, nil, nil, nil),
+				optional("chat", []string{"encrypt"}, nil, []string{"encrypt"}),
+			},
+			want: []string{"chat", "encrypt"},
+		},
+		{
+			name: "unregistered before",
+			factories: []Factory{
+				described("chat", 0, []string{"encrpyt"}, nil, nil),
+			},
+			wantErr: ErrUnknownInterceptor,
+		},
+		{
+			name: "unregistered after",
+			factories: []Factory{
+				optional("fragment", nil, []string{"encrpyt"}, []string{"encrypt"}),
+			},
+			wantErr: ErrUnknownInterceptor,
+		},
+		{
+			name: "undescribed factories are named by type",
+			factories: []Factory{
+				testFactory{},
+				described("a", 0, nil, nil, nil),
+			},
+			want: []string{"a", "interceptor.testFactory#0"},
+		},
+		{
+			name: "missing requirement",
+			factories: []Factory{
+				described("chat", 0, nil, nil, []string{"encrypt"}),
+			},
+			wantErr: ErrMissingDependency,
+		},
+		{
+			name: "duplicate name",
+			factories: []Factory{
+				described("a", 0, nil, nil, nil),
+				described("a", 0, nil, nil, nil),
+			},
+			wantErr: ErrDuplicateName,
+		},
+		{
+			name: "cycle",
+			factories: []Factory{
+				described("a", 0, []string{"b"}, nil, nil),
+				described("b", 0, []string{"c"}, nil, nil),
+				described("c", 0, []string{"a"}, nil, nil),
+				described("d", 0, nil, nil, nil),
+			},
+			wantErr: ErrInterceptorCycle,
+		},
+		{
+			name:      "nil factory",
+			factories: []Factory{nil},
+			wantErr:   ErrNilFactory,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, factory := range tt.factories {
+				registry.Register(factory)
+			}
+
+			got, err := registry.Order()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Order() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_BuildChainsInOrder(t *testing.T) {
+	var built []string
+
+	registry := NewRegistry()
+	registry.Register(Describe(testFactory{built: &built, name: "fragment"}, Descriptor{Name: "fragment", After: []string{"encrypt"}}))
+	registry.Register(Describe(testFactory{built: &built, name: "encrypt"}, Descriptor{Name: "encrypt"}))
+	registry.Register(Describe(testFactory{built: &built, name: "chat"}, Descriptor{Name: "chat", Before: []string{"encrypt"}}))
+
+	i, err := registry.Build(context.Background(), "client")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if want := []string{"chat", "encrypt", "fragment"}; !slices.Equal(built, want) {
+		t.Errorf("built = %v, want %v", built, want)
+	}
+
+	chain, ok := i.(*Chain)
+	if !ok {
+		t.Fatalf("Build() = %T, want *Chain", i)
+	}
+
+	if len(chain.interceptors) != 3 {
+		t.Fatalf("len(interceptors) = %d, want 3", len(chain.interceptors))
+	}
+}
+
+func TestRegistry_BuildKeepsRegistrationOrderFromTheSocket(t *testing.T) {
+	var built []string
+
+	registry := NewRegistry()
+	for _, name := range []string{"first", "second", "third"} {
+		registry.Register(testFactory{built: &built, name: name})
+	}
+
+	if _, err := registry.Build(context.Background(), "client"); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	// NOTE: BUILT IN WRITE PATH ORDER; THE CHAIN REVERSES IT, SO THE FIRST REGISTERED IS THE CLOSEST TO THE SOCKET
+	want := []string{"third", "second", "first"}
+	if !slices.Equal(built, want) {
+		t.Errorf("built = %v, want %v", built, want)
+	}
+}
+
+func TestRegistry_BuildReportsCycle(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(described("a", 0, []string{"b"}, nil, nil))
+	registry.Register(described("b", 0, []string{"a"}, nil, nil))
+
+	if _, err := registry.Build(context.Background(), "client"); !errors.Is(err, ErrInterceptorCycle) {
+		t.Errorf("Build() error = %v, want %v", err, ErrInterceptorCycle)
+	}
+}
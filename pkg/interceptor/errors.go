@@ -13,6 +13,14 @@ var (
 	ErrConnectionNotFound = errors.New("connection not registered")
 	ErrConnectionExists   = errors.New("connection already exists")
 	ErrInvalidInterceptor = errors.New("inappropriate interceptor for the message")
+
+	ErrNilFactory         = errors.New("nil interceptor factory")
+	ErrDuplicateName      = errors.New("interceptor name registered more than once")
+	ErrMissingDependency  = errors.New("required interceptor not registered")
+	ErrUnknownInterceptor = errors.New("constrained interceptor not registered and not optional")
+	ErrInterceptorCycle   = errors.New("interceptor ordering constraints form a cycle")
+
+	ErrInterceptorNotFound = errors.New("interceptor not in the chain")
 )
 
 func NewError(text string) error {
@@ -0,0 +1,24 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+)
+
+type expiryKey struct{}
+
+// WithExpiry returns a context which makes the transport drop the written message if it is still
+// queued at the given time. The socket sets it from the header of the written message (see message.Header.Expiry).
+func WithExpiry(ctx context.Context, expiry time.Time) context.Context {
+	return context.WithValue(ctx, expiryKey{}, expiry)
+}
+
+// ExpiryFromContext returns the expiry set by WithExpiry or the zero time.
+func ExpiryFromContext(ctx context.Context) time.Time {
+	if ctx == nil {
+		return time.Time{}
+	}
+
+	expiry, _ := ctx.Value(expiryKey{}).(time.Time)
+	return expiry
+}
@@ -2,48 +2,74 @@ package interceptor
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"slices"
 
 	"github.com/harshabose/socket-comm/pkg/message"
 )
 
 type Registry struct {
 	factories []Factory
+	policies  map[string]ErrorPolicy
 	messages  message.Registry
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
 		factories: make([]Factory, 0),
+		policies:  make(map[string]ErrorPolicy),
 	}
 }
 
+// Register adds the factory to the registry. The order of the chain is resolved by Build from the
+// descriptors of the factories (see Descriptor); registration order only breaks ties, the first registered
+// being the closest to the socket.
 func (registry *Registry) Register(factory Factory) {
 	registry.factories = append(registry.factories, factory)
 }
 
+// SetErrorPolicy sets the error policy of the named interceptor, overriding the one of its descriptor.
+// It applies to the chains built afterwards.
+func (registry *Registry) SetErrorPolicy(name string, policy ErrorPolicy) {
+	if registry.policies == nil {
+		registry.policies = make(map[string]ErrorPolicy)
+	}
+	registry.policies[name] = policy
+}
+
 func (registry *Registry) Build(ctx context.Context, id ClientID) (Interceptor, error) {
 	if len(registry.factories) == 0 {
 		return &NoOpInterceptor{}, nil
 	}
 
-	interceptors := make([]Interceptor, 0)
-	for _, factory := range registry.factories {
-		interceptor, err := factory.NewInterceptor(ctx, id, registry.messages)
-		if err != nil {
-			return nil, err
-		}
+	interceptors, descriptors, reporters, err := registry.build(ctx, id, registry.messages)
+	if err != nil {
+		return nil, fmt.Errorf("error while building interceptor chain; err: %w", err)
+	}
+
+	// NOTE: THE CHAIN WRAPS FROM THE SOCKET OUTWARDS; THE LAST INTERCEPTOR ON THE WRITE PATH GOES FIRST
+	slices.Reverse(interceptors)
+	slices.Reverse(descriptors)
+	slices.Reverse(reporters)
+
+	chain := CreateChain(interceptors)
+	chain.describe(descriptors, registry.policies)
+	chain.selector = SelectorFromContext(ctx)
 
-		interceptors = append(interceptors, interceptor)
+	for index, reporter := range reporters {
+		reporter.counters.Store(&chain.counters[index])
 	}
 
-	return CreateChain(interceptors), nil
+	return chain, nil
 }
 
 type Factory interface {
 	NewInterceptor(context.Context, ClientID, message.Registry) (Interceptor, error)
 }
 
+// Connection is the transport of a single peer.
+// Write must not retain p after returning; Read hands the ownership of the returned buffer to the caller.
 type Connection interface {
 	Write(ctx context.Context, p []byte) error
 	Read(ctx context.Context) ([]byte, error)
@@ -57,6 +83,11 @@ type Interceptor interface {
 
 	GetMessageRegistry() message.Registry
 
+	// BindSocketConnection registers the connection. The writer and reader only traverse the layers
+	// beneath the interceptor (closer to the socket), so the messages the interceptor writes on its own
+	// are not intercepted by itself or the layers above it. The returned writer and reader replace the
+	// given ones as the base the interceptor's InterceptSocketWriter and InterceptSocketReader wrap;
+	// most interceptors return them unchanged.
 	BindSocketConnection(Connection, Writer, Reader) (Writer, Reader, error)
 
 	Init(Connection) error
@@ -116,8 +147,8 @@ func (interceptor *NoOpInterceptor) GetMessageRegistry() message.Registry {
 	return interceptor.messageRegistry
 }
 
-func (interceptor *NoOpInterceptor) BindSocketConnection(_ Connection, _ Writer, _ Reader) (Writer, Reader, error) {
-	return nil, nil, nil
+func (interceptor *NoOpInterceptor) BindSocketConnection(_ Connection, writer Writer, reader Reader) (Writer, Reader, error) {
+	return writer, reader, nil
 }
 
 func (interceptor *NoOpInterceptor) Init(_ Connection) error {
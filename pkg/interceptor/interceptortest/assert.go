@@ -0,0 +1,99 @@
+package interceptortest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultWaitTimeout is how long Wait waits for a background process
+const DefaultWaitTimeout = time.Second
+
+// Bind binds the connection to the interceptor over the given writer and reader, like the chain does, and
+// returns the interceptor's writer and reader. Like the chain, it attaches the attribute store of the
+// connection; the connection is unbound, and the store detached, when the test ends.
+func Bind(t testing.TB, i interceptor.Interceptor, connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader) {
+	t.Helper()
+
+	interceptor.AttachAttributes(connection)
+
+	w, r, err := i.BindSocketConnection(connection, writer, reader)
+	if err != nil {
+		interceptor.DetachAttributes(connection)
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		i.UnBindSocketConnection(connection)
+		interceptor.DetachAttributes(connection)
+	})
+
+	// NOTE: LIKE THE CHAIN, A NIL WRITER OR READER KEEPS THE GIVEN ONE
+	if w == nil {
+		w = writer
+	}
+	if r == nil {
+		r = reader
+	}
+
+	return i.InterceptSocketWriter(w), i.InterceptSocketReader(r)
+}
+
+// Wait waits for the background process and returns its error; the test fails if the process does not
+// finish within DefaultWaitTimeout.
+func Wait(t testing.TB, process interceptor.CanBeProcessedBackground) error {
+	t.Helper()
+
+	if process == nil {
+		t.Fatal("Wait() on a nil process")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- process.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(DefaultWaitTimeout):
+		process.Stop()
+		t.Fatalf("process did not finish within %s", DefaultWaitTimeout)
+		return nil
+	}
+}
+
+// AssertError fails the test unless err matches want with errors.Is; a nil want expects no error
+func AssertError(t testing.TB, err error, want error) {
+	t.Helper()
+
+	if want == nil {
+		if err != nil {
+			t.Errorf("error = %v; want none", err)
+		}
+		return
+	}
+
+	if !errors.Is(err, want) {
+		t.Errorf("error = %v; want %v", err, want)
+	}
+}
+
+// AssertProtocols fails the test unless the messages have the given protocols, in order
+func AssertProtocols(t testing.TB, msgs []message.Message, want ...message.Protocol) {
+	t.Helper()
+
+	if got := protocols(msgs); !slices.Equal(got, want) {
+		t.Errorf("protocols = %v; want %v", got, want)
+	}
+}
+
+// AssertCalled fails the test unless the fake processor's method was called n times
+func AssertCalled(t testing.TB, calls *Calls, method string, n int) {
+	t.Helper()
+
+	if got := calls.Called(method); got != n {
+		t.Errorf("%s called %d times; want %d", method, got, n)
+	}
+}
@@ -0,0 +1,136 @@
+// Package interceptortest provides fakes and helpers to unit-test interceptors, messages and processes
+// against the real interceptor interfaces, without a socket.
+package interceptortest
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Connection is an in-memory interceptor.Connection. Written frames are recorded; Read returns the frames
+// queued with Push, blocking while there are none, and io.EOF once the connection is closed and drained.
+type Connection struct {
+	id      string
+	written [][]byte
+	queue   [][]byte
+	ready   chan struct{}
+	closed  bool
+	code    int
+	reason  string
+	mux     sync.Mutex
+}
+
+func NewConnection(id string) *Connection {
+	return &Connection{
+		id:      id,
+		written: make([][]byte, 0),
+		queue:   make([][]byte, 0),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+func (c *Connection) ID() string {
+	return c.id
+}
+
+func (c *Connection) Write(_ context.Context, p []byte) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+
+	c.written = append(c.written, slices.Clone(p))
+	return nil
+}
+
+func (c *Connection) Read(ctx context.Context) ([]byte, error) {
+	for {
+		c.mux.Lock()
+		if len(c.queue) > 0 {
+			frame := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mux.Unlock()
+			return frame, nil
+		}
+		closed := c.closed
+		c.mux.Unlock()
+
+		if closed {
+			return nil, io.EOF
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.ready:
+		}
+	}
+}
+
+// Push queues frames to be read
+func (c *Connection) Push(frames ...[]byte) {
+	c.mux.Lock()
+	for _, frame := range frames {
+		c.queue = append(c.queue, slices.Clone(frame))
+	}
+	c.mux.Unlock()
+
+	c.notify()
+}
+
+// PushMessage queues the serialized messages to be read
+func (c *Connection) PushMessage(msgs ...message.Message) error {
+	for _, msg := range msgs {
+		data, err := message.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		c.Push(data)
+	}
+
+	return nil
+}
+
+// Written returns the frames written to the connection
+func (c *Connection) Written() [][]byte {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return slices.Clone(c.written)
+}
+
+func (c *Connection) Close() error {
+	return c.CloseWithCode(0, "")
+}
+
+func (c *Connection) CloseWithCode(code int, reason string) error {
+	c.mux.Lock()
+	if !c.closed {
+		c.closed, c.code, c.reason = true, code, reason
+	}
+	c.mux.Unlock()
+
+	c.notify()
+	return nil
+}
+
+// Closed reports if the connection is closed, with the close code and reason if closed with CloseWithCode
+func (c *Connection) Closed() (bool, int, string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.closed, c.code, c.reason
+}
+
+func (c *Connection) notify() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
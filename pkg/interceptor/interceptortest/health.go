@@ -0,0 +1,139 @@
+package interceptortest
+
+import (
+	"context"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/health"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+var (
+	_ interceptor.Processor                          = (*Health)(nil)
+	_ interfaces.CanAddHealth                        = (*Health)(nil)
+	_ interfaces.CanRemoveHealth                     = (*Health)(nil)
+	_ interfaces.CanUpdate                           = (*Health)(nil)
+	_ interfaces.CanGetHealth                        = (*Health)(nil)
+	_ interfaces.CanAddHealthSnapshotStreamer        = (*Health)(nil)
+	_ interfaces.CanRemoveHealthSnapshotStreamer     = (*Health)(nil)
+	_ interfaces.CanRemoveAllHealthSnapshotStreamers = (*Health)(nil)
+	_ interfaces.CanCreateHealth                     = (*Health)(nil)
+	_ interfaces.CanDeleteHealth                     = (*Health)(nil)
+	_ interfaces.CanGetHealthSnapshot                = (*Health)(nil)
+)
+
+// Health is a fake health processor. Every method records its call and calls the matching func; a nil func
+// succeeds with zero values.
+type Health struct {
+	Calls
+	AddFunc                              func(types.RoomID, interceptor.ClientID) error
+	RemoveFunc                           func(types.RoomID, interceptor.ClientID) error
+	UpdateFunc                           func(types.RoomID, interceptor.ClientID, *health.Stat) error
+	GetHealthFunc                        func(types.RoomID) (*health.Health, error)
+	AddHealthSnapshotStreamerFunc        func(types.RoomID, interceptor.State, interceptor.CanBeProcessedBackground) error
+	RemoveHealthSnapshotStreamerFunc     func(types.RoomID, interceptor.State) error
+	RemoveAllHealthSnapshotStreamersFunc func(interceptor.State) error
+	CreateHealthFunc                     func(types.RoomID, []interceptor.ClientID, time.Duration) (*health.Health, error)
+	DeleteHealthFunc                     func(types.RoomID) error
+	GetHealthSnapshotFunc                func(types.RoomID) (health.Snapshot, error)
+}
+
+func (h *Health) Process(ctx context.Context, process interceptor.CanBeProcessed, s interceptor.State) error {
+	return process.Process(ctx, h, s)
+}
+
+func (h *Health) ProcessBackground(ctx context.Context, process interceptor.CanBeProcessedBackground, s interceptor.State) interceptor.CanBeProcessedBackground {
+	return process.ProcessBackground(ctx, h, s)
+}
+
+func (h *Health) Add(id types.RoomID, client interceptor.ClientID) error {
+	h.record("Add", id, client)
+	if h.AddFunc == nil {
+		return nil
+	}
+
+	return h.AddFunc(id, client)
+}
+
+func (h *Health) Remove(id types.RoomID, client interceptor.ClientID) error {
+	h.record("Remove", id, client)
+	if h.RemoveFunc == nil {
+		return nil
+	}
+
+	return h.RemoveFunc(id, client)
+}
+
+func (h *Health) Update(id types.RoomID, client interceptor.ClientID, stat *health.Stat) error {
+	h.record("Update", id, client, stat)
+	if h.UpdateFunc == nil {
+		return nil
+	}
+
+	return h.UpdateFunc(id, client, stat)
+}
+
+func (h *Health) GetHealth(id types.RoomID) (*health.Health, error) {
+	h.record("GetHealth", id)
+	if h.GetHealthFunc == nil {
+		return nil, nil
+	}
+
+	return h.GetHealthFunc(id)
+}
+
+func (h *Health) AddHealthSnapshotStreamer(id types.RoomID, s interceptor.State, process interceptor.CanBeProcessedBackground) error {
+	h.record("AddHealthSnapshotStreamer", id, s, process)
+	if h.AddHealthSnapshotStreamerFunc == nil {
+		return nil
+	}
+
+	return h.AddHealthSnapshotStreamerFunc(id, s, process)
+}
+
+func (h *Health) RemoveHealthSnapshotStreamer(id types.RoomID, s interceptor.State) error {
+	h.record("RemoveHealthSnapshotStreamer", id, s)
+	if h.RemoveHealthSnapshotStreamerFunc == nil {
+		return nil
+	}
+
+	return h.RemoveHealthSnapshotStreamerFunc(id, s)
+}
+
+func (h *Health) RemoveAllHealthSnapshotStreamers(s interceptor.State) error {
+	h.record("RemoveAllHealthSnapshotStreamers", s)
+	if h.RemoveAllHealthSnapshotStreamersFunc == nil {
+		return nil
+	}
+
+	return h.RemoveAllHealthSnapshotStreamersFunc(s)
+}
+
+func (h *Health) CreateHealth(id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration) (*health.Health, error) {
+	h.record("CreateHealth", id, allowed, ttl)
+	if h.CreateHealthFunc == nil {
+		return nil, nil
+	}
+
+	return h.CreateHealthFunc(id, allowed, ttl)
+}
+
+func (h *Health) DeleteHealth(id types.RoomID) error {
+	h.record("DeleteHealth", id)
+	if h.DeleteHealthFunc == nil {
+		return nil
+	}
+
+	return h.DeleteHealthFunc(id)
+}
+
+func (h *Health) GetHealthSnapshot(id types.RoomID) (health.Snapshot, error) {
+	h.record("GetHealthSnapshot", id)
+	if h.GetHealthSnapshotFunc == nil {
+		return health.Snapshot{}, nil
+	}
+
+	return h.GetHealthSnapshotFunc(id)
+}
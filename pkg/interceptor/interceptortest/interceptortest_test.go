@@ -0,0 +1,111 @@
+package interceptortest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+func newReport(t *testing.T) message.Message {
+	t.Helper()
+
+	msg, err := message.NewErrorReport("test", "test:protocol", "write", 0, errors.New("report"))
+	if err != nil {
+		t.Fatalf("NewErrorReport() error = %v", err)
+	}
+
+	return msg
+}
+
+func TestConnection(t *testing.T) {
+	c := NewConnection("test")
+
+	read := make(chan []byte, 1)
+	go func() {
+		frame, _ := c.Read(context.Background())
+		read <- frame
+	}()
+
+	c.Push([]byte("frame"))
+
+	select {
+	case frame := <-read:
+		if string(frame) != "frame" {
+			t.Errorf("Read() = %q; want %q", frame, "frame")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read() did not return the pushed frame")
+	}
+
+	c.Push([]byte("last"))
+	if err := c.CloseWithCode(1000, "done"); err != nil {
+		t.Fatalf("CloseWithCode() error = %v", err)
+	}
+
+	if frame, err := c.Read(context.Background()); err != nil || string(frame) != "last" {
+		t.Errorf("Read() = %q, %v; want the frame queued before closing", frame, err)
+	}
+
+	if _, err := c.Read(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("Read() error = %v; want %v", err, io.EOF)
+	}
+
+	if closed, code, reason := c.Closed(); !closed || code != 1000 || reason != "done" {
+		t.Errorf("Closed() = %t, %d, %q; want true, 1000, done", closed, code, reason)
+	}
+}
+
+func TestState(t *testing.T) {
+	s := NewState(nil)
+
+	if _, err := s.GetClientID(); !errors.Is(err, ErrUnknownClientID) {
+		t.Errorf("GetClientID() error = %v; want %v", err, ErrUnknownClientID)
+	}
+
+	if err := s.SetClientID("client"); err != nil {
+		t.Fatalf("SetClientID() error = %v", err)
+	}
+
+	if err := s.SetClientID("other"); !errors.Is(err, interceptor.ErrClientIDNotConsistent) {
+		t.Errorf("SetClientID() twice error = %v; want %v", err, interceptor.ErrClientIDNotConsistent)
+	}
+
+	msg := newReport(t)
+	if err := s.Write(context.Background(), msg); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	AssertProtocols(t, s.Writer().Messages(), msg.GetProtocol())
+}
+
+func TestBind(t *testing.T) {
+	i := interceptor.NewNoOpInterceptor(context.Background(), "test", nil)
+	connection := NewConnection("test")
+
+	msg := newReport(t)
+	writer, reader := NewWriter(), NewReader(msg)
+
+	w, r := Bind(t, &i, connection, writer, reader)
+
+	if err := w.Write(context.Background(), connection, msg); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := r.Read(context.Background(), connection)
+	if err != nil || got != msg {
+		t.Errorf("Read() = %v, %v; want the queued message", got, err)
+	}
+
+	if written := writer.Written(); len(written) != 1 || written[0].Connection != connection {
+		t.Errorf("Written() = %v; want the message written to the connection", written)
+	}
+
+	if reader.Pending() != 0 || reader.Reads() != 1 {
+		t.Errorf("Pending(), Reads() = %d, %d; want 0, 1", reader.Pending(), reader.Reads())
+	}
+}
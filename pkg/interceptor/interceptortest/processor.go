@@ -0,0 +1,63 @@
+package interceptortest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// Processor is an interceptor.Processor without any capability; processes asserting one fail with
+// interceptor.ErrInterfaceMisMatch. It runs processes the way the interceptors do.
+type Processor struct{}
+
+func (p *Processor) Process(ctx context.Context, process interceptor.CanBeProcessed, s interceptor.State) error {
+	return process.Process(ctx, p, s)
+}
+
+func (p *Processor) ProcessBackground(ctx context.Context, process interceptor.CanBeProcessedBackground, s interceptor.State) interceptor.CanBeProcessedBackground {
+	return process.ProcessBackground(ctx, p, s)
+}
+
+// Call is a method call recorded by a fake processor
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Calls records the method calls of a fake processor
+type Calls struct {
+	calls []Call
+	mux   sync.Mutex
+}
+
+func (c *Calls) record(method string, args ...any) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the recorded calls in order
+func (c *Calls) Calls() []Call {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return slices.Clone(c.calls)
+}
+
+// Called returns the number of calls of the method
+func (c *Calls) Called(method string) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	count := 0
+	for _, call := range c.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
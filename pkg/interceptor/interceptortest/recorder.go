@@ -0,0 +1,138 @@
+package interceptortest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Writer is an interceptor.Writer recording the messages written to it. Set Err to fail the writes.
+type Writer struct {
+	Err     error
+	written []Written
+	mux     sync.Mutex
+}
+
+// Written is a message recorded by Writer, with the connection it was written to
+type Written struct {
+	Connection interceptor.Connection
+	Message    message.Message
+}
+
+func NewWriter() *Writer {
+	return &Writer{written: make([]Written, 0)}
+}
+
+func (w *Writer) Write(_ context.Context, connection interceptor.Connection, msg message.Message) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.Err != nil {
+		return w.Err
+	}
+
+	w.written = append(w.written, Written{Connection: connection, Message: msg})
+	return nil
+}
+
+// Written returns the recorded writes in order
+func (w *Writer) Written() []Written {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return slices.Clone(w.written)
+}
+
+// Messages returns the recorded messages in order
+func (w *Writer) Messages() []message.Message {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	msgs := make([]message.Message, 0, len(w.written))
+	for _, written := range w.written {
+		msgs = append(msgs, written.Message)
+	}
+
+	return msgs
+}
+
+// Protocols returns the protocols of the recorded messages in order
+func (w *Writer) Protocols() []message.Protocol {
+	return protocols(w.Messages())
+}
+
+// Reset forgets the recorded messages
+func (w *Writer) Reset() {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.written = make([]Written, 0)
+}
+
+// Reader is an interceptor.Reader returning the queued messages in order, then nil messages once drained.
+// Set Err to fail the reads.
+type Reader struct {
+	Err   error
+	queue []message.Message
+	reads int
+	mux   sync.Mutex
+}
+
+func NewReader(msgs ...message.Message) *Reader {
+	return &Reader{queue: slices.Clone(msgs)}
+}
+
+func (r *Reader) Read(_ context.Context, _ interceptor.Connection) (message.Message, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.reads++
+
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	if len(r.queue) == 0 {
+		return nil, nil
+	}
+
+	msg := r.queue[0]
+	r.queue = r.queue[1:]
+	return msg, nil
+}
+
+// Queue adds messages to be read
+func (r *Reader) Queue(msgs ...message.Message) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.queue = append(r.queue, msgs...)
+}
+
+// Pending returns the number of queued messages not read yet
+func (r *Reader) Pending() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return len(r.queue)
+}
+
+// Reads returns the number of calls to Read
+func (r *Reader) Reads() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.reads
+}
+
+func protocols(msgs []message.Message) []message.Protocol {
+	protocols := make([]message.Protocol, 0, len(msgs))
+	for _, msg := range msgs {
+		protocols = append(protocols, msg.GetProtocol())
+	}
+
+	return protocols
+}
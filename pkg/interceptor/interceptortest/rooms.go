@@ -0,0 +1,139 @@
+package interceptortest
+
+import (
+	"context"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+var (
+	_ interceptor.Processor             = (*Rooms)(nil)
+	_ interfaces.CanAdd                 = (*Rooms)(nil)
+	_ interfaces.CanRemove              = (*Rooms)(nil)
+	_ interfaces.CanLeaveAll            = (*Rooms)(nil)
+	_ interfaces.CanGetRoom             = (*Rooms)(nil)
+	_ interfaces.CanWriteRoomMessage    = (*Rooms)(nil)
+	_ interfaces.CanCreateRoom          = (*Rooms)(nil)
+	_ interfaces.CanDeleteRoom          = (*Rooms)(nil)
+	_ interfaces.CanStartHealthTracking = (*Rooms)(nil)
+	_ interfaces.CanStopHealthTracking  = (*Rooms)(nil)
+)
+
+// Rooms is a fake room processor. Every method records its call and calls the matching func; a nil func
+// succeeds with zero values.
+type Rooms struct {
+	Calls
+	AddFunc                 func(types.RoomID, interceptor.State) error
+	RemoveFunc              func(types.RoomID, interceptor.State) error
+	LeaveAllFunc            func(interceptor.State) ([]types.RoomID, error)
+	GetRoomFunc             func(types.RoomID) (*room.Room, error)
+	WriteRoomMessageFunc    func(types.RoomID, message.Message, interceptor.ClientID, ...interceptor.ClientID) error
+	CreateRoomFunc          func(types.RoomID, []interceptor.ClientID, time.Duration) (*room.Room, error)
+	DeleteRoomFunc          func(types.RoomID) error
+	StartHealthTrackingFunc func(types.RoomID, time.Duration, interceptor.CanBeProcessedBackground) error
+	IsHealthTrackedFunc     func(types.RoomID) (bool, error)
+	StopHealthTrackingFunc  func(types.RoomID) error
+}
+
+func (r *Rooms) Process(ctx context.Context, process interceptor.CanBeProcessed, s interceptor.State) error {
+	return process.Process(ctx, r, s)
+}
+
+func (r *Rooms) ProcessBackground(ctx context.Context, process interceptor.CanBeProcessedBackground, s interceptor.State) interceptor.CanBeProcessedBackground {
+	return process.ProcessBackground(ctx, r, s)
+}
+
+func (r *Rooms) Add(id types.RoomID, s interceptor.State) error {
+	r.record("Add", id, s)
+	if r.AddFunc == nil {
+		return nil
+	}
+
+	return r.AddFunc(id, s)
+}
+
+func (r *Rooms) Remove(id types.RoomID, s interceptor.State) error {
+	r.record("Remove", id, s)
+	if r.RemoveFunc == nil {
+		return nil
+	}
+
+	return r.RemoveFunc(id, s)
+}
+
+func (r *Rooms) LeaveAll(s interceptor.State) ([]types.RoomID, error) {
+	r.record("LeaveAll", s)
+	if r.LeaveAllFunc == nil {
+		return nil, nil
+	}
+
+	return r.LeaveAllFunc(s)
+}
+
+func (r *Rooms) GetRoom(id types.RoomID) (*room.Room, error) {
+	r.record("GetRoom", id)
+	if r.GetRoomFunc == nil {
+		return nil, nil
+	}
+
+	return r.GetRoomFunc(id)
+}
+
+func (r *Rooms) WriteRoomMessage(id types.RoomID, msg message.Message, from interceptor.ClientID, tos ...interceptor.ClientID) error {
+	r.record("WriteRoomMessage", id, msg, from, tos)
+	if r.WriteRoomMessageFunc == nil {
+		return nil
+	}
+
+	return r.WriteRoomMessageFunc(id, msg, from, tos...)
+}
+
+func (r *Rooms) CreateRoom(id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration, _ ...room.Option) (*room.Room, error) {
+	r.record("CreateRoom", id, allowed, ttl)
+	if r.CreateRoomFunc == nil {
+		return nil, nil
+	}
+
+	return r.CreateRoomFunc(id, allowed, ttl)
+}
+
+func (r *Rooms) DeleteRoom(id types.RoomID) error {
+	r.record("DeleteRoom", id)
+	if r.DeleteRoomFunc == nil {
+		return nil
+	}
+
+	return r.DeleteRoomFunc(id)
+}
+
+func (r *Rooms) StartHealthTracking(id types.RoomID, interval time.Duration, process interceptor.CanBeProcessedBackground) error {
+	r.record("StartHealthTracking", id, interval, process)
+	if r.StartHealthTrackingFunc == nil {
+		return nil
+	}
+
+	return r.StartHealthTrackingFunc(id, interval, process)
+}
+
+func (r *Rooms) IsHealthTracked(id types.RoomID) (bool, error) {
+	r.record("IsHealthTracked", id)
+	if r.IsHealthTrackedFunc == nil {
+		return false, nil
+	}
+
+	return r.IsHealthTrackedFunc(id)
+}
+
+func (r *Rooms) StopHealthTracking(id types.RoomID) error {
+	r.record("StopHealthTracking", id)
+	if r.StopHealthTrackingFunc == nil {
+		return nil
+	}
+
+	return r.StopHealthTrackingFunc(id)
+}
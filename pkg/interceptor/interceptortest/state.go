@@ -0,0 +1,95 @@
+package interceptortest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// ErrUnknownClientID is returned by State.GetClientID before the client id is set
+var ErrUnknownClientID = errors.New("client id not known at the moment")
+
+// State is an interceptor.State writing to its own Writer and Connection. Like the chat state, the
+// client id can only be set once.
+type State struct {
+	ctx        context.Context
+	id         interceptor.ClientID
+	connection *Connection
+	writer     *Writer
+	attributes *interceptor.Attributes
+	mux        sync.Mutex
+}
+
+// NewState creates a state without a client id; a nil ctx is replaced by context.Background
+func NewState(ctx context.Context) *State {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &State{
+		ctx:        ctx,
+		id:         interceptor.UnknownClientID,
+		connection: NewConnection("state"),
+		writer:     NewWriter(),
+		attributes: interceptor.NewAttributes(),
+	}
+}
+
+// NewStateWithID creates a state with the client id already set
+func NewStateWithID(ctx context.Context, id interceptor.ClientID) *State {
+	s := NewState(ctx)
+	s.id = id
+	interceptor.Set(s.attributes, interceptor.ClientIDKey, id)
+
+	return s
+}
+
+func (s *State) Ctx() context.Context {
+	return s.ctx
+}
+
+func (s *State) GetClientID() (interceptor.ClientID, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.id == interceptor.UnknownClientID {
+		return s.id, ErrUnknownClientID
+	}
+
+	return s.id, nil
+}
+
+func (s *State) SetClientID(id interceptor.ClientID) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.id != interceptor.UnknownClientID {
+		return interceptor.ErrClientIDNotConsistent
+	}
+
+	s.id = id
+	interceptor.Set(s.attributes, interceptor.ClientIDKey, id)
+
+	return nil
+}
+
+func (s *State) Attributes() *interceptor.Attributes {
+	return s.attributes
+}
+
+func (s *State) Write(ctx context.Context, msg message.Message) error {
+	return s.writer.Write(ctx, s.connection, msg)
+}
+
+// Writer returns the writer recording the messages written through the state
+func (s *State) Writer() *Writer {
+	return s.writer
+}
+
+// Connection returns the connection of the state
+func (s *State) Connection() *Connection {
+	return s.connection
+}
@@ -0,0 +1,33 @@
+package interceptor
+
+import "context"
+
+// Lane identifies an independent queue in the write path of a connection. The transport
+// serves the lanes fairly, so messages written on a busy lane (for example, the chunks of a
+// file) do not delay the messages written on the other lanes. Messages of a lane keep their order.
+type Lane string
+
+// DefaultLane is used by the messages written without a lane; it carries the control traffic.
+const DefaultLane Lane = ""
+
+type laneKey struct{}
+
+// WithLane returns a context which makes the transport queue the written messages in the given lane.
+// The context must be passed down the writer chain untouched (derived contexts keep the lane).
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// LaneFromContext returns the lane set by WithLane or DefaultLane.
+func LaneFromContext(ctx context.Context) Lane {
+	if ctx == nil {
+		return DefaultLane
+	}
+
+	lane, ok := ctx.Value(laneKey{}).(Lane)
+	if !ok {
+		return DefaultLane
+	}
+
+	return lane
+}
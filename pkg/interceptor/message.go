@@ -57,6 +57,8 @@ type Message interface {
 type BaseMessage struct {
 	// Embed the base message implementation
 	message.BaseMessage
+	// InheritedPriority is the priority of the wrapped message (see InheritPriority); nil if it has none
+	InheritedPriority *Priority `json:"priority,omitempty"`
 }
 
 func NewBaseMessage(nextProtocol message.Protocol, nextPayload message.Marshallable, msg Message) (BaseMessage, error) {
@@ -0,0 +1,14 @@
+// Package names holds the names of the middleware interceptors in the interceptor chain (see
+// interceptor.Descriptor), so that a middleware can order itself against the others without importing them.
+package names
+
+const (
+	Chat      = "chat"
+	Compress  = "compress"
+	Dedup     = "dedup"
+	Encrypt   = "encrypt"
+	Fault     = "fault"
+	Fragment  = "fragment"
+	RateLimit = "ratelimit"
+	Stream    = "stream"
+)
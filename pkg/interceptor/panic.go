@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+var panics atomic.Uint64
+
+// PanicError is a recovered panic. Value is the value passed to panic and Stack the stack trace of the
+// panicking goroutine at the time of the panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and errors.As see through the panic
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// RecoverPanic converts a panic into a *PanicError stored in *errp. It must be deferred directly:
+//
+//	defer interceptor.RecoverPanic(&err)
+func RecoverPanic(errp *error) {
+	if r := recover(); r != nil {
+		panics.Add(1)
+		*errp = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// Panics returns the number of panics recovered by RecoverPanic since the process started
+func Panics() uint64 {
+	return panics.Load()
+}
@@ -0,0 +1,117 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	before := Panics()
+
+	err := func() (err error) {
+		defer RecoverPanic(&err)
+		panic(errLayer)
+	}()
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *PanicError", err)
+	}
+
+	if !errors.Is(err, errLayer) {
+		t.Errorf("errors.Is(%v, errLayer) = false, want the panic value to be unwrapped", err)
+	}
+
+	if !strings.Contains(string(perr.Stack), "TestRecoverPanic") {
+		t.Errorf("Stack does not contain the panicking function:\n%s", perr.Stack)
+	}
+
+	if Panics() != before+1 {
+		t.Errorf("Panics() = %d, want %d", Panics(), before+1)
+	}
+}
+
+func TestChain_RecoversPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ErrorPolicy
+		wantErr bool
+	}{
+		{name: "returned as error", policy: ErrorPolicy{Default: ErrorReturn}, wantErr: true},
+		{name: "dropped by policy", policy: ErrorPolicy{Default: ErrorDrop}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, failing, socket, writer, _ := newPolicyChain(t, testConnection{}, tt.policy)
+			failing.panicOnWrite = "bad message"
+
+			err := writer.Write(context.Background(), testConnection{}, &traversal{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var perr *PanicError
+			if tt.wantErr && !errors.As(err, &perr) {
+				t.Errorf("Write() error = %v, want *PanicError", err)
+			}
+
+			if len(socket.written) != 0 {
+				t.Errorf("written %d messages, want none", len(socket.written))
+			}
+
+			stats := chain.ErrorStats()
+			if stats["failing"].Panics != 1 {
+				t.Errorf("failing Panics = %d, want 1", stats["failing"].Panics)
+			}
+
+			if stats["app-side"].Panics != 0 {
+				t.Errorf("app-side Panics = %d, want 0; the panic must only be counted where it happened", stats["app-side"].Panics)
+			}
+		})
+	}
+}
+
+type panickingInit struct {
+	NoOpInterceptor
+}
+
+func (panickingInit) Init(Connection) error {
+	panic("init failed")
+}
+
+func TestChain_InitRecoversPanics(t *testing.T) {
+	chain := CreateChain([]Interceptor{&panickingInit{}})
+
+	var perr *PanicError
+	if err := chain.Init(testConnection{}); !errors.As(err, &perr) {
+		t.Fatalf("Init() error = %v, want *PanicError", err)
+	}
+}
+
+func TestReportPanic(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Describe(testFactory{name: "chat"}, Descriptor{Name: "chat"}))
+	registry.Register(Describe(testFactory{name: "encrypt"}, Descriptor{Name: "encrypt"}))
+
+	i, err := registry.Build(context.Background(), "client")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	chain := i.(*Chain)
+
+	// NOTE: A BACKGROUND PROCESS OF THE INTERCEPTOR REPORTS WITH THE CONTEXT IT WAS BUILT WITH
+	for _, layer := range chain.interceptors {
+		ReportPanic(layer.Ctx(), errLayer)
+		ReportPanic(layer.Ctx(), &PanicError{Value: errLayer})
+	}
+	ReportPanic(context.Background(), &PanicError{Value: errLayer})
+
+	for name, stats := range chain.ErrorStats() {
+		if stats.Panics != 1 {
+			t.Errorf("%s: Panics = %d, want only the reported panic counted", name, stats.Panics)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultErrorCode is the code used to close the connection or reported to the peer when the policy
+// does not set one; it is the WebSocket "internal error" close code.
+const DefaultErrorCode = 1011
+
+// ErrorAction tells the chain what to do with an error returned by an interceptor
+type ErrorAction uint8
+
+const (
+	// ErrorReturn returns the error to the caller (the default)
+	ErrorReturn ErrorAction = iota
+	// ErrorPassThrough skips the interceptor: the message is handed on as if the interceptor was not in the chain
+	ErrorPassThrough
+	// ErrorDrop drops the message; reads continue with the next message
+	ErrorDrop
+	// ErrorClose closes the connection with the code of the policy and returns the error
+	ErrorClose
+	// ErrorReply drops the message and sends a message.ErrorReport to the peer
+	ErrorReply
+)
+
+func (a ErrorAction) String() string {
+	switch a {
+	case ErrorReturn:
+		return "return"
+	case ErrorPassThrough:
+		return "pass-through"
+	case ErrorDrop:
+		return "drop"
+	case ErrorClose:
+		return "close"
+	case ErrorReply:
+		return "reply"
+	default:
+		return fmt.Sprintf("action(%d)", uint8(a))
+	}
+}
+
+// ErrorPolicy decides how the chain handles the errors of an interceptor. Only the errors raised by the
+// interceptor itself are handled; errors of the layers beneath it were already handled by their own policies.
+type ErrorPolicy struct {
+	// Default is the action for the protocols without an entry in Protocols
+	Default ErrorAction
+	// Protocols overrides the action for the messages of the given protocols. On the read path, the
+	// protocol is the one of the message the interceptor read from the layer beneath it.
+	Protocols map[message.Protocol]ErrorAction
+	// Code is the close code of ErrorClose and the code of the reports sent by ErrorReply; DefaultErrorCode if zero
+	Code int
+}
+
+// Action returns the action for the errors on messages of the given protocol
+func (p ErrorPolicy) Action(protocol message.Protocol) ErrorAction {
+	if action, ok := p.Protocols[protocol]; ok {
+		return action
+	}
+
+	return p.Default
+}
+
+func (p ErrorPolicy) code() int {
+	if p.Code == 0 {
+		return DefaultErrorCode
+	}
+
+	return p.Code
+}
+
+// CodeCloser is implemented by the connections which can be closed with a close code and reason
+type CodeCloser interface {
+	CloseWithCode(code int, reason string) error
+}
+
+// ErrorStats counts the errors handled by the chain for an interceptor, by action
+type ErrorStats struct {
+	Returned      uint64 `json:"returned"`
+	PassedThrough uint64 `json:"passed_through"`
+	Dropped       uint64 `json:"dropped"`
+	Closed        uint64 `json:"closed"`
+	Replied       uint64 `json:"replied"`
+	// Panics counts the panics recovered in the interceptor; they are handled like errors (see PanicError)
+	Panics uint64 `json:"panics"`
+}
+
+type errorCounters struct {
+	actions [ErrorReply + 1]atomic.Uint64
+	panics  atomic.Uint64
+}
+
+func (c *errorCounters) stats() ErrorStats {
+	return ErrorStats{
+		Returned:      c.actions[ErrorReturn].Load(),
+		PassedThrough: c.actions[ErrorPassThrough].Load(),
+		Dropped:       c.actions[ErrorDrop].Load(),
+		Closed:        c.actions[ErrorClose].Load(),
+		Replied:       c.actions[ErrorReply].Load(),
+		Panics:        c.panics.Load(),
+	}
+}
+
+// layerReporter counts the panics of an interceptor recovered outside of the chain (see ReportPanic); the
+// counters of its layer are set once the chain is built
+type layerReporter struct {
+	counters atomic.Pointer[errorCounters]
+}
+
+type layerReporterKey struct{}
+
+// ReportPanic counts a panic of the interceptor given the context by Registry.Build, recovered outside of
+// its writer and reader (for example, by one of its background processes), in the error statistics of its
+// layer like the panics recovered by the chain (see ErrorStats). Errors which are not a *PanicError and
+// contexts of other origins are ignored.
+func ReportPanic(ctx context.Context, err error) {
+	var perr *PanicError
+	if ctx == nil || !errors.As(err, &perr) {
+		return
+	}
+
+	reporter, ok := ctx.Value(layerReporterKey{}).(*layerReporter)
+	if !ok {
+		return
+	}
+
+	if counters := reporter.counters.Load(); counters != nil {
+		counters.panics.Add(1)
+	}
+}
+
+// propagatedError marks the errors raised beneath an interceptor so that its policy leaves them alone
+type propagatedError struct {
+	err error
+}
+
+func (e *propagatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *propagatedError) Unwrap() error {
+	return e.err
+}
+
+func isPropagated(err error) bool {
+	var p *propagatedError
+	return errors.As(err, &p)
+}
+
+func propagate(err error) error {
+	if err == nil || isPropagated(err) {
+		return err
+	}
+
+	return &propagatedError{err: err}
+}
+
+// readSlot keeps the last message an interceptor read from the layer beneath it
+type readSlot struct {
+	msg message.Message
+}
+
+type readSlotKey struct{}
+
+// guard applies the error policy of a single layer of the chain
+type guard struct {
+	name     string
+	policy   ErrorPolicy
+	counters *errorCounters
+	below    Writer // the writer beneath the layer; used to pass through and to reply
+	belowR   Reader // the reader beneath the layer
+}
+
+// beneath marks the errors of the writer and reader beneath the layer and records the messages read from it
+func (g *guard) beneath() (Writer, Reader) {
+	writer := WriterFunc(func(ctx context.Context, connection Connection, msg message.Message) error {
+		return propagate(g.below.Write(ctx, connection, msg))
+	})
+
+	reader := ReaderFunc(func(ctx context.Context, connection Connection) (message.Message, error) {
+		msg, err := g.belowR.Read(ctx, connection)
+		if slot, ok := ctx.Value(readSlotKey{}).(*readSlot); ok {
+			slot.msg = msg
+		}
+
+		return msg, propagate(err)
+	})
+
+	return writer, reader
+}
+
+func (g *guard) writer(writer Writer) Writer {
+	return WriterFunc(func(ctx context.Context, connection Connection, msg message.Message) error {
+		err := g.protect(func() error {
+			return writer.Write(ctx, connection, msg)
+		})
+		if err == nil || isPropagated(err) {
+			return err
+		}
+
+		protocol := protocolOf(msg)
+		action := g.policy.Action(protocol)
+		g.counters.actions[action].Add(1)
+
+		switch action {
+		case ErrorPassThrough:
+			return g.below.Write(ctx, connection, msg)
+		case ErrorDrop:
+			return nil
+		case ErrorClose:
+			return g.close(connection, StageWrite, err)
+		case ErrorReply:
+			return g.reply(ctx, connection, protocol, StageWrite, err)
+		default:
+			return err
+		}
+	})
+}
+
+func (g *guard) reader(reader Reader) Reader {
+	return ReaderFunc(func(ctx context.Context, connection Connection) (message.Message, error) {
+		for {
+			var (
+				slot = &readSlot{}
+				msg  message.Message
+			)
+
+			err := g.protect(func() (err error) {
+				msg, err = reader.Read(context.WithValue(ctx, readSlotKey{}, slot), connection)
+				return err
+			})
+			if err == nil || isPropagated(err) {
+				return msg, err
+			}
+
+			protocol := protocolOf(slot.msg)
+			action := g.policy.Action(protocol)
+			if action == ErrorPassThrough && slot.msg == nil {
+				// NOTE: THE ERROR WAS RAISED BEFORE ANYTHING WAS READ; THERE IS NOTHING TO PASS THROUGH
+				action = ErrorReturn
+			}
+			g.counters.actions[action].Add(1)
+
+			switch action {
+			case ErrorPassThrough:
+				return slot.msg, nil
+			case ErrorDrop:
+				continue
+			case ErrorClose:
+				return nil, g.close(connection, StageRead, err)
+			case ErrorReply:
+				if err := g.reply(ctx, connection, protocol, StageRead, err); err != nil {
+					return nil, err
+				}
+				continue
+			default:
+				return nil, err
+			}
+		}
+	})
+}
+
+// protect recovers the panics of the layer; they are handled by the policy like any other error of the layer
+func (g *guard) protect(fn func() error) error {
+	return protect(g.counters, fn)
+}
+
+// protect runs fn, converting its panics into *PanicError and counting them
+func protect(counters *errorCounters, fn func() error) (err error) {
+	defer func() {
+		var perr *PanicError
+		if errors.As(err, &perr) && !isPropagated(err) {
+			counters.panics.Add(1)
+		}
+	}()
+	defer RecoverPanic(&err)
+
+	return fn()
+}
+
+func (g *guard) close(connection Connection, stage Stage, err error) error {
+	var closeErr error
+	if closer, ok := connection.(CodeCloser); ok {
+		closeErr = closer.CloseWithCode(g.policy.code(), err.Error())
+	} else {
+		closeErr = connection.Close()
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("error while closing connection after %s error in interceptor %s; err: %w", stage, g.name, errors.Join(err, closeErr))
+	}
+
+	return fmt.Errorf("error while %s in interceptor %s; connection closed; err: %w", stage, g.name, err)
+}
+
+func (g *guard) reply(ctx context.Context, connection Connection, protocol message.Protocol, stage Stage, err error) error {
+	report, rerr := message.NewErrorReport(g.name, protocol, string(stage), g.policy.code(), err)
+	if rerr != nil {
+		return fmt.Errorf("error while reporting %s error in interceptor %s; err: %w", stage, g.name, errors.Join(err, rerr))
+	}
+
+	if rerr := g.below.Write(ctx, connection, report); rerr != nil {
+		return fmt.Errorf("error while reporting %s error in interceptor %s; err: %w", stage, g.name, errors.Join(err, rerr))
+	}
+
+	return nil
+}
+
+func protocolOf(msg message.Message) message.Protocol {
+	if msg == nil {
+		return message.NoneProtocol
+	}
+
+	return msg.GetProtocol()
+}
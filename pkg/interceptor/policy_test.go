@@ -0,0 +1,203 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+var errLayer = errors.New("layer failed")
+
+type closingConnection struct {
+	testConnection
+	code   int
+	closed bool
+}
+
+func (c *closingConnection) CloseWithCode(code int, _ string) error {
+	c.code, c.closed = code, true
+	return nil
+}
+
+// newPolicyChain chains socket-side, failing and app-side, with the policy set on the failing layer
+func newPolicyChain(t *testing.T, connection Connection, policy ErrorPolicy) (*Chain, *layer, *socketEnd, Writer, Reader) {
+	t.Helper()
+
+	layers, chain := newLayers("socket-side", "failing", "app-side")
+	chain.describe([]Descriptor{{Name: "socket-side"}, {Name: "failing", ErrorPolicy: policy}, {Name: "app-side"}}, nil)
+
+	socket := &socketEnd{}
+	writer, reader, err := chain.BindSocketConnection(connection, socket, socket)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	return chain, layers[1], socket, writer, reader
+}
+
+func TestErrorPolicy_Write(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      ErrorPolicy
+		wantErr     bool
+		wantWritten []string // layers of the message written to the socket; nil if nothing is written
+		wantStats   ErrorStats
+	}{
+		{
+			name:      "return",
+			policy:    ErrorPolicy{Default: ErrorReturn},
+			wantErr:   true,
+			wantStats: ErrorStats{Returned: 1},
+		},
+		{
+			name:        "pass through",
+			policy:      ErrorPolicy{Default: ErrorPassThrough},
+			wantWritten: []string{"app-side", "socket-side"},
+			wantStats:   ErrorStats{PassedThrough: 1},
+		},
+		{
+			name:      "drop",
+			policy:    ErrorPolicy{Default: ErrorDrop},
+			wantStats: ErrorStats{Dropped: 1},
+		},
+		{
+			name:        "protocol override",
+			policy:      ErrorPolicy{Default: ErrorReturn, Protocols: map[message.Protocol]ErrorAction{traversalProtocol: ErrorPassThrough}},
+			wantWritten: []string{"app-side", "socket-side"},
+			wantStats:   ErrorStats{PassedThrough: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, failing, socket, writer, _ := newPolicyChain(t, testConnection{}, tt.policy)
+			failing.writeErr = errLayer
+
+			err := writer.Write(context.Background(), testConnection{}, &traversal{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, errLayer) {
+				t.Errorf("Write() error = %v, want %v", err, errLayer)
+			}
+
+			if tt.wantWritten == nil && len(socket.written) != 0 {
+				t.Errorf("written %d messages, want none", len(socket.written))
+			}
+
+			if tt.wantWritten != nil && !slices.Equal(socket.last().Layers, tt.wantWritten) {
+				t.Errorf("written through %v, want %v", socket.last().Layers, tt.wantWritten)
+			}
+
+			if got := chain.ErrorStats()["failing"]; got != tt.wantStats {
+				t.Errorf("ErrorStats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestErrorPolicy_ReadDropContinues(t *testing.T) {
+	chain, failing, socket, _, reader := newPolicyChain(t, testConnection{}, ErrorPolicy{Default: ErrorDrop})
+	failing.readErr, failing.readFailures = errLayer, 2
+
+	msg, err := reader.Read(context.Background(), testConnection{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got, want := msg.(*traversal).Layers, []string{"socket-side", "failing", "app-side"}; !slices.Equal(got, want) {
+		t.Errorf("read through %v, want %v", got, want)
+	}
+
+	if socket.reads != 3 {
+		t.Errorf("socket reads = %d, want 3", socket.reads)
+	}
+
+	if got := chain.ErrorStats()["failing"].Dropped; got != 2 {
+		t.Errorf("Dropped = %d, want 2", got)
+	}
+}
+
+func TestErrorPolicy_ReadPassThrough(t *testing.T) {
+	_, failing, _, _, reader := newPolicyChain(t, testConnection{}, ErrorPolicy{Default: ErrorPassThrough})
+	failing.readErr, failing.readFailures = errLayer, 1
+
+	msg, err := reader.Read(context.Background(), testConnection{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got, want := msg.(*traversal).Layers, []string{"socket-side", "app-side"}; !slices.Equal(got, want) {
+		t.Errorf("read through %v, want %v", got, want)
+	}
+}
+
+func TestErrorPolicy_ReadReply(t *testing.T) {
+	_, failing, socket, _, reader := newPolicyChain(t, testConnection{}, ErrorPolicy{Default: ErrorReply, Code: 4000})
+	failing.readErr, failing.readFailures = errLayer, 1
+
+	if _, err := reader.Read(context.Background(), testConnection{}); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if len(socket.written) != 1 {
+		t.Fatalf("written %d messages, want the error report", len(socket.written))
+	}
+
+	report, ok := socket.written[0].(*message.ErrorReport)
+	if !ok {
+		t.Fatalf("written %T, want *message.ErrorReport", socket.written[0])
+	}
+
+	if report.Interceptor != "failing" || report.FailedProtocol != traversalProtocol || report.Stage != string(StageRead) || report.Code != 4000 {
+		t.Errorf("report = %+v", report)
+	}
+
+	if err := report.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestErrorPolicy_ReadClose(t *testing.T) {
+	connection := &closingConnection{}
+
+	chain, failing, _, _, reader := newPolicyChain(t, connection, ErrorPolicy{Default: ErrorClose})
+	failing.readErr, failing.readFailures = errLayer, 1
+
+	if _, err := reader.Read(context.Background(), connection); !errors.Is(err, errLayer) {
+		t.Fatalf("Read() error = %v, want %v", err, errLayer)
+	}
+
+	if !connection.closed || connection.code != DefaultErrorCode {
+		t.Errorf("closed = %v with code %d, want closed with %d", connection.closed, connection.code, DefaultErrorCode)
+	}
+
+	if got := chain.ErrorStats()["failing"].Closed; got != 1 {
+		t.Errorf("Closed = %d, want 1", got)
+	}
+}
+
+func TestErrorPolicy_OnlyOwnErrors(t *testing.T) {
+	layers, chain := newLayers("socket-side", "app-side")
+	chain.describe([]Descriptor{{Name: "socket-side"}, {Name: "app-side", ErrorPolicy: ErrorPolicy{Default: ErrorDrop}}}, nil)
+	layers[0].writeErr = errLayer
+
+	socket := &socketEnd{}
+	writer, _, err := chain.BindSocketConnection(testConnection{}, socket, socket)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if err := writer.Write(context.Background(), testConnection{}, &traversal{}); !errors.Is(err, errLayer) {
+		t.Fatalf("Write() error = %v, want %v", err, errLayer)
+	}
+
+	stats := chain.ErrorStats()
+	if stats["socket-side"].Returned != 1 || stats["app-side"] != (ErrorStats{}) {
+		t.Errorf("ErrorStats() = %+v, want the error handled by socket-side only", stats)
+	}
+}
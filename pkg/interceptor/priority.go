@@ -0,0 +1,141 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Priority is the class of a message in the write path of a connection. The transport serves
+// the classes in proportion to their weights, so control messages are not stuck behind bulk traffic.
+// NOTE: ORDER IS ONLY KEPT BETWEEN MESSAGES OF THE SAME PRIORITY AND LANE
+type Priority uint8
+
+const (
+	// PriorityControl is for the messages which keep the connection working (key exchange, health, errors)
+	PriorityControl Priority = iota
+	// PriorityInteractive is for the regular messages; it is the default priority
+	PriorityInteractive
+	// PriorityBulk is for large transfers which should not delay the other messages
+	PriorityBulk
+)
+
+// Priorities lists all the priority classes from the most to the least urgent
+var Priorities = []Priority{PriorityControl, PriorityInteractive, PriorityBulk}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityControl:
+		return "control"
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBulk:
+		return "bulk"
+	default:
+		return "unknown"
+	}
+}
+
+// Prioritized is implemented by the messages which always belong to the same priority class
+type Prioritized interface {
+	Priority() Priority
+}
+
+// PriorityInheritor is implemented by the messages which carry the priority of the message they wrap;
+// every message embedding BaseMessage is
+type PriorityInheritor interface {
+	SetInheritedPriority(Priority)
+	GetInheritedPriority() (Priority, bool)
+}
+
+// SetInheritedPriority sets the priority carried for the wrapped message
+func (m *BaseMessage) SetInheritedPriority(priority Priority) {
+	m.InheritedPriority = &priority
+}
+
+// GetInheritedPriority returns the priority carried for the wrapped message, if any
+func (m *BaseMessage) GetInheritedPriority() (Priority, bool) {
+	if m.InheritedPriority == nil {
+		return PriorityInteractive, false
+	}
+
+	return *m.InheritedPriority, true
+}
+
+// InheritPriority makes the wrapper carry the priority of the inner message; the priority of the inner
+// message, or the one it carries itself if it is a wrapper too. Like message.InheritExpiry, wrappers must
+// call it as the transport only sees the outermost message of a chain.
+func InheritPriority(wrapper message.Message, inner message.Message) {
+	if wrapper == nil || inner == nil {
+		return
+	}
+
+	w, ok := wrapper.(PriorityInheritor)
+	if !ok {
+		return
+	}
+
+	if p, ok := inner.(Prioritized); ok {
+		w.SetInheritedPriority(p.Priority())
+		return
+	}
+
+	if i, ok := inner.(PriorityInheritor); ok {
+		if priority, ok := i.GetInheritedPriority(); ok {
+			w.SetInheritedPriority(priority)
+		}
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context which makes the transport queue the written messages in the
+// given priority class. It overrides the priority of the message and of its protocol.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority set by WithPriority, if any.
+func PriorityFromContext(ctx context.Context) (Priority, bool) {
+	if ctx == nil {
+		return PriorityInteractive, false
+	}
+
+	priority, ok := ctx.Value(priorityKey{}).(Priority)
+	return priority, ok
+}
+
+// ResolvePriority returns the priority of the message written with the context. The priority set
+// on the context wins over the priority of the message (see Prioritized), which wins over the
+// priority it inherited from the message it wraps (see InheritPriority), which wins over the priority
+// of its protocol in protocols, and then of the protocol of the message it wraps. Messages without any
+// priority are PriorityInteractive.
+func ResolvePriority(ctx context.Context, msg message.Message, protocols map[message.Protocol]Priority) Priority {
+	if priority, ok := PriorityFromContext(ctx); ok {
+		return priority
+	}
+
+	if msg == nil {
+		return PriorityInteractive
+	}
+
+	if p, ok := msg.(Prioritized); ok {
+		return p.Priority()
+	}
+
+	if i, ok := msg.(PriorityInheritor); ok {
+		if priority, ok := i.GetInheritedPriority(); ok {
+			return priority
+		}
+	}
+
+	if priority, ok := protocols[msg.GetProtocol()]; ok {
+		return priority
+	}
+
+	if priority, ok := protocols[msg.GetNextProtocol()]; ok {
+		return priority
+	}
+
+	return PriorityInteractive
+}
@@ -0,0 +1,151 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Selector decides, when a connection is bound, whether the named interceptor of the chain is enabled for
+// the connection; for example, from the auth claims or the features negotiated during the upgrade.
+type Selector func(connection Connection, name string) bool
+
+// switches holds which interceptors of the chain are enabled for a bound connection; indexed like the
+// interceptors of the chain.
+type switches struct {
+	enabled     []atomic.Bool
+	initialised []bool
+	inited      bool // the chain is initialised for the connection; enabled interceptors are initialised on Enable
+	mux         sync.Mutex
+}
+
+func newSwitches(names []string, selector Selector, connection Connection) *switches {
+	s := &switches{
+		enabled:     make([]atomic.Bool, len(names)),
+		initialised: make([]bool, len(names)),
+	}
+
+	for index, name := range names {
+		s.enabled[index].Store(selector == nil || selector(connection, name))
+	}
+
+	return s
+}
+
+// writer writes through the interceptor while it is enabled, and straight to the layer beneath otherwise
+func (s *switches) writer(index int, intercepted Writer, beneath Writer) Writer {
+	return WriterFunc(func(ctx context.Context, connection Connection, msg message.Message) error {
+		if !s.enabled[index].Load() {
+			return beneath.Write(ctx, connection, msg)
+		}
+
+		return intercepted.Write(ctx, connection, msg)
+	})
+}
+
+// reader reads through the interceptor while it is enabled, and straight from the layer beneath otherwise
+func (s *switches) reader(index int, intercepted Reader, beneath Reader) Reader {
+	return ReaderFunc(func(ctx context.Context, connection Connection) (message.Message, error) {
+		if !s.enabled[index].Load() {
+			return beneath.Read(ctx, connection)
+		}
+
+		return intercepted.Read(ctx, connection)
+	})
+}
+
+// SetSelector sets the selector deciding which interceptors are enabled for the connections bound after;
+// without one, every interceptor is enabled. The chains built by the registry take the selector from the
+// context they are built with (see WithSelector).
+func (chain *Chain) SetSelector(selector Selector) {
+	chain.mux.Lock()
+	defer chain.mux.Unlock()
+
+	chain.selector = selector
+}
+
+// Enable enables the named interceptor for the bound connection. An interceptor disabled since the
+// connection was bound is initialised first, if the chain was already initialised for the connection; it
+// stays disabled if its initialisation fails.
+func (chain *Chain) Enable(connection Connection, name string) error {
+	s, index, err := chain.switchOf(connection, name)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.inited && !s.initialised[index] {
+		if err := protect(&chain.counters[index], func() error {
+			return chain.interceptors[index].Init(connection)
+		}); err != nil {
+			return fmt.Errorf("error while enabling interceptor %s; err: %w", name, err)
+		}
+		s.initialised[index] = true
+	}
+
+	s.enabled[index].Store(true)
+	return nil
+}
+
+// Disable disables the named interceptor for the bound connection; the messages of the connection pass
+// the interceptor by. The interceptor stays bound, so it can be enabled again.
+func (chain *Chain) Disable(connection Connection, name string) error {
+	s, index, err := chain.switchOf(connection, name)
+	if err != nil {
+		return err
+	}
+
+	s.enabled[index].Store(false)
+	return nil
+}
+
+// Enabled reports whether the named interceptor is enabled for the bound connection
+func (chain *Chain) Enabled(connection Connection, name string) (bool, error) {
+	s, index, err := chain.switchOf(connection, name)
+	if err != nil {
+		return false, err
+	}
+
+	return s.enabled[index].Load(), nil
+}
+
+func (chain *Chain) switchOf(connection Connection, name string) (*switches, int, error) {
+	index := slices.Index(chain.names, name)
+	if index < 0 {
+		return nil, 0, fmt.Errorf("error while switching interceptor %s; err: %w", name, ErrInterceptorNotFound)
+	}
+
+	chain.mux.Lock()
+	defer chain.mux.Unlock()
+
+	s, exists := chain.switches[connection]
+	if !exists {
+		return nil, 0, fmt.Errorf("error while switching interceptor %s; err: %w", name, ErrConnectionNotFound)
+	}
+
+	return s, index, nil
+}
+
+type selectorKey struct{}
+
+// WithSelector returns a context which makes the chains built with it (see Registry.Build) select the
+// interceptors of every connection with the given selector.
+func WithSelector(ctx context.Context, selector Selector) context.Context {
+	return context.WithValue(ctx, selectorKey{}, selector)
+}
+
+// SelectorFromContext returns the selector set by WithSelector, or nil.
+func SelectorFromContext(ctx context.Context) Selector {
+	if ctx == nil {
+		return nil
+	}
+
+	selector, _ := ctx.Value(selectorKey{}).(Selector)
+	return selector
+}
@@ -0,0 +1,183 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newNamedLayers creates a chain of layers named in the chain after the layers, as if described by the registry
+func newNamedLayers(names ...string) ([]*layer, *Chain) {
+	layers, chain := newLayers(names...)
+
+	descriptors := make([]Descriptor, 0, len(names))
+	for _, name := range names {
+		descriptors = append(descriptors, Descriptor{Name: name})
+	}
+	chain.describe(descriptors, nil)
+
+	return layers, chain
+}
+
+// traverse writes and reads a message through the chain and returns the layers it passed through
+func traverse(t *testing.T, writer Writer, reader Reader, socket *socketEnd) ([]string, []string) {
+	t.Helper()
+
+	if err := writer.Write(context.Background(), testConnection{}, &traversal{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	msg, err := reader.Read(context.Background(), testConnection{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	return socket.last().Layers, msg.(*traversal).Layers
+}
+
+func TestChain_Selector_DisablesLayers(t *testing.T) {
+	layers, chain := newNamedLayers("socket-side", "middle", "app-side")
+	chain.SetSelector(func(_ Connection, name string) bool {
+		return name != "middle"
+	})
+
+	socket := &socketEnd{}
+	writer, reader, err := chain.BindSocketConnection(testConnection{}, socket, socket)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if layers[1].writer == nil {
+		t.Errorf("disabled layer was not bound")
+	}
+
+	if err := chain.Init(testConnection{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	if layers[1].inits != 0 {
+		t.Errorf("disabled layer initialised %d times, want 0", layers[1].inits)
+	}
+
+	written, read := traverse(t, writer, reader, socket)
+	if want := []string{"app-side", "socket-side"}; !slices.Equal(written, want) {
+		t.Errorf("written through %v, want %v", written, want)
+	}
+	if want := []string{"socket-side", "app-side"}; !slices.Equal(read, want) {
+		t.Errorf("read through %v, want %v", read, want)
+	}
+}
+
+func TestChain_EnableDisable(t *testing.T) {
+	layers, chain := newNamedLayers("socket-side", "middle", "app-side")
+	chain.SetSelector(func(_ Connection, name string) bool {
+		return name != "middle"
+	})
+
+	socket := &socketEnd{}
+	writer, reader, err := chain.BindSocketConnection(testConnection{}, socket, socket)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if err := chain.Init(testConnection{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	if err := chain.Enable(testConnection{}, "middle"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+
+	if layers[1].inits != 1 {
+		t.Errorf("layer enabled after init initialised %d times, want 1", layers[1].inits)
+	}
+
+	if enabled, err := chain.Enabled(testConnection{}, "middle"); err != nil || !enabled {
+		t.Errorf("Enabled() = %v, %v; want true, nil", enabled, err)
+	}
+
+	written, _ := traverse(t, writer, reader, socket)
+	if want := []string{"app-side", "middle", "socket-side"}; !slices.Equal(written, want) {
+		t.Errorf("enabled: written through %v, want %v", written, want)
+	}
+
+	if err := chain.Disable(testConnection{}, "middle"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+
+	written, _ = traverse(t, writer, reader, socket)
+	if want := []string{"app-side", "socket-side"}; !slices.Equal(written, want) {
+		t.Errorf("disabled: written through %v, want %v", written, want)
+	}
+
+	// NOTE: ENABLING AGAIN DOES NOT INITIALISE THE LAYER AGAIN
+	if err := chain.Enable(testConnection{}, "middle"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+
+	if layers[1].inits != 1 {
+		t.Errorf("layer enabled twice initialised %d times, want 1", layers[1].inits)
+	}
+}
+
+func TestChain_Enable_StaysDisabledIfInitFails(t *testing.T) {
+	errInit := errors.New("init failed")
+
+	layers, chain := newNamedLayers("socket-side", "middle")
+	layers[1].initErr = errInit
+	chain.SetSelector(func(_ Connection, name string) bool {
+		return name != "middle"
+	})
+
+	socket := &socketEnd{}
+	if _, _, err := chain.BindSocketConnection(testConnection{}, socket, socket); err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if err := chain.Init(testConnection{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	if err := chain.Enable(testConnection{}, "middle"); !errors.Is(err, errInit) {
+		t.Fatalf("Enable() error = %v, want %v", err, errInit)
+	}
+
+	if enabled, _ := chain.Enabled(testConnection{}, "middle"); enabled {
+		t.Errorf("layer enabled although its initialisation failed")
+	}
+}
+
+func TestChain_Enable_Errors(t *testing.T) {
+	_, chain := newNamedLayers("socket-side")
+
+	if err := chain.Enable(testConnection{}, "socket-side"); !errors.Is(err, ErrConnectionNotFound) {
+		t.Errorf("Enable() on unbound connection error = %v, want %v", err, ErrConnectionNotFound)
+	}
+
+	socket := &socketEnd{}
+	if _, _, err := chain.BindSocketConnection(testConnection{}, socket, socket); err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if err := chain.Disable(testConnection{}, "unknown"); !errors.Is(err, ErrInterceptorNotFound) {
+		t.Errorf("Disable() of unknown interceptor error = %v, want %v", err, ErrInterceptorNotFound)
+	}
+
+	chain.UnBindSocketConnection(testConnection{})
+
+	if _, err := chain.Enabled(testConnection{}, "socket-side"); !errors.Is(err, ErrConnectionNotFound) {
+		t.Errorf("Enabled() after unbind error = %v, want %v", err, ErrConnectionNotFound)
+	}
+}
+
+func TestSelectorFromContext(t *testing.T) {
+	if SelectorFromContext(context.Background()) != nil {
+		t.Errorf("SelectorFromContext() without selector is not nil")
+	}
+
+	ctx := WithSelector(context.Background(), func(Connection, string) bool { return false })
+	if selector := SelectorFromContext(ctx); selector == nil || selector(testConnection{}, "any") {
+		t.Errorf("SelectorFromContext() did not return the selector set by WithSelector")
+	}
+}
@@ -11,4 +11,6 @@ type State interface {
 	GetClientID() (ClientID, error)
 	SetClientID(id ClientID) error
 	Write(ctx context.Context, msg message.Message) error
+	// Attributes returns the attribute store of the state's connection, shared with the other interceptors
+	Attributes() *Attributes
 }
@@ -0,0 +1,154 @@
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Stage is the part of the message life cycle covered by a span
+type Stage string
+
+const (
+	StageRead    Stage = "read"
+	StageWrite   Stage = "write"
+	StageProcess Stage = "process"
+)
+
+// Attribute is a key-value pair recorded on a span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span is a stage of a message being read, written or processed. It mirrors the subset of the
+// OpenTelemetry span API used by socket-comm, so that an OpenTelemetry tracer can be adapted to it.
+type Span interface {
+	// SpanContext returns the trace context of the span; it is propagated in the message headers
+	SpanContext() message.TraceContext
+	SetAttributes(...Attribute)
+	RecordError(error)
+	End()
+}
+
+// Tracer creates spans. Like the OpenTelemetry Tracer.Start, the parent of the new span is the span
+// carried by the context (see ContextWithTrace); without one, the span starts a new trace.
+// The returned context carries the new span.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// tracerHolder allows storing interfaces of different dynamic types in an atomic.Value
+type tracerHolder struct {
+	tracer Tracer
+}
+
+var globalTracer atomic.Value
+
+// SetTracer sets the tracer used by the sockets and interceptors; nil restores the default tracer,
+// which only propagates the trace context without recording anything.
+func SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = propagatingTracer{}
+	}
+
+	globalTracer.Store(tracerHolder{tracer: tracer})
+}
+
+// GetTracer returns the tracer set by SetTracer
+func GetTracer() Tracer {
+	if holder, ok := globalTracer.Load().(tracerHolder); ok {
+		return holder.tracer
+	}
+
+	return propagatingTracer{}
+}
+
+type traceKey struct{}
+
+// ContextWithTrace returns a context carrying the trace context, which becomes the parent of the spans started with it.
+func ContextWithTrace(ctx context.Context, tc message.TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the trace context set by ContextWithTrace; it is not valid if there is none.
+func TraceFromContext(ctx context.Context) message.TraceContext {
+	if ctx == nil {
+		return message.TraceContext{}
+	}
+
+	tc, _ := ctx.Value(traceKey{}).(message.TraceContext)
+	return tc
+}
+
+// StartSpan starts the span of a stage of the message using the tracer set by SetTracer. The parent
+// is the span of the context or, if there is none, the trace context in the header of the message,
+// so that traces continue across connections (for example, through room forwarding).
+func StartSpan(ctx context.Context, stage Stage, msg message.Message) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	protocol := message.NoneProtocol
+	if msg != nil {
+		protocol = msg.GetProtocol()
+
+		if !TraceFromContext(ctx).IsValid() {
+			if tc := msg.GetCurrentHeader().TraceContext(); tc.IsValid() {
+				ctx = ContextWithTrace(ctx, tc)
+			}
+		}
+	}
+
+	attributes := []Attribute{
+		{Key: "messaging.system", Value: "socket-comm"},
+		{Key: "messaging.operation.name", Value: string(stage)},
+		{Key: "messaging.message.protocol", Value: string(protocol)},
+	}
+
+	if msg != nil {
+		header := msg.GetCurrentHeader()
+		attributes = append(attributes,
+			Attribute{Key: "messaging.message.sender", Value: string(header.Sender)},
+			Attribute{Key: "messaging.message.receiver", Value: string(header.Receiver)},
+		)
+	}
+
+	return GetTracer().Start(ctx, string(stage)+" "+string(protocol), attributes...)
+}
+
+// InjectTrace sets the trace context in the header of the message, if both are valid
+func InjectTrace(msg message.Message, tc message.TraceContext) {
+	if !tc.IsValid() {
+		return
+	}
+
+	if t, ok := msg.(message.Traceable); ok {
+		t.SetTraceContext(tc)
+	}
+}
+
+// propagatingTracer creates spans which are not recorded but still carry a trace context, so that
+// traces are propagated even if no tracer is set. It never starts a trace on its own; a trace can be
+// started without a tracer using ContextWithTrace(ctx, message.NewTraceContext()).
+type propagatingTracer struct{}
+
+func (propagatingTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	parent := TraceFromContext(ctx)
+	if !parent.IsValid() {
+		return ctx, propagatingSpan{}
+	}
+
+	span := propagatingSpan{tc: parent.NewChild()}
+	return ContextWithTrace(ctx, span.tc), span
+}
+
+type propagatingSpan struct {
+	tc message.TraceContext
+}
+
+func (s propagatingSpan) SpanContext() message.TraceContext { return s.tc }
+func (s propagatingSpan) SetAttributes(...Attribute)        {}
+func (s propagatingSpan) RecordError(error)                 {}
+func (s propagatingSpan) End()                              {}
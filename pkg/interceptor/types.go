@@ -7,3 +7,6 @@ type (
 const (
 	UnknownClientID ClientID = "unknown"
 )
+
+// ClientIDKey is the attribute holding the client id of a connection once it is identified
+var ClientIDKey = NewKey[ClientID]("client_id")
@@ -0,0 +1,51 @@
+package message
+
+// ErrorReportProtocol identifies the message sent to the peer when an interceptor failed to process one of its messages
+const ErrorReportProtocol Protocol = "message:error"
+
+// ErrorReport tells the peer that an interceptor failed to process a message. It is sent when the
+// error policy of the interceptor asks to reply (see interceptor.ErrorPolicy).
+type ErrorReport struct {
+	BaseMessage
+	Interceptor    string   `json:"interceptor"`
+	FailedProtocol Protocol `json:"failed_protocol,omitempty"`
+	Stage          string   `json:"stage"`
+	Code           int      `json:"code,omitempty"`
+	Reason         string   `json:"reason"`
+}
+
+// NewErrorReport creates the message reporting that the named interceptor failed to process a message
+// of the given protocol at the given stage ("read" or "write")
+func NewErrorReport(interceptor string, protocol Protocol, stage string, code int, reason error) (*ErrorReport, error) {
+	report := &ErrorReport{
+		Interceptor:    interceptor,
+		FailedProtocol: protocol,
+		Stage:          stage,
+		Code:           code,
+		Reason:         reason.Error(),
+	}
+
+	bmsg, err := NewBaseMessage(NoneProtocol, nil, report)
+	if err != nil {
+		return nil, err
+	}
+
+	report.BaseMessage = bmsg
+	return report, nil
+}
+
+func (m *ErrorReport) GetProtocol() Protocol {
+	return ErrorReportProtocol
+}
+
+func (m *ErrorReport) Validate() error {
+	if err := RequireNotEmpty("interceptor", m.Interceptor); err != nil {
+		return err
+	}
+
+	if err := RequireNotEmpty("stage", m.Stage); err != nil {
+		return err
+	}
+
+	return RequireNotEmpty("reason", m.Reason)
+}
@@ -16,4 +16,22 @@ var (
 	// ErrInvalidMessageData is returned when raw message data cannot be
 	// properly identified or does not contain a valid protocol field
 	ErrInvalidMessageData = errors.New("invalid message data")
+
+	// ErrUnsupportedVersion is returned when a message carries a version that the
+	// registry can neither decode directly nor migrate to the current version
+	ErrUnsupportedVersion = errors.New("unsupported message version")
+
+	// ErrNoMigrationPath is returned when migrations are registered for a protocol
+	// but none of them lead from the source version to the target version
+	ErrNoMigrationPath = errors.New("no migration path between versions")
+
+	// ErrValidation is returned (wrapped in a ValidationError) when a decoded
+	// message does not satisfy the rules of its protocol
+	ErrValidation = errors.New("message validation failed")
+
+	// ErrNotOpaque is returned when decoding a payload that was not made by NewOpaquePayload
+	ErrNotOpaque = errors.New("payload is not opaque")
+
+	// ErrExpired is returned when a message is dropped because its expiry has passed
+	ErrExpired = errors.New("message expired")
 )
@@ -0,0 +1,94 @@
+package message
+
+import (
+	"time"
+)
+
+// ExpiredProtocol identifies the message sent back to the sender of a message that expired before delivery
+const ExpiredProtocol Protocol = "message:expired"
+
+// ExpiryStage tells where an expired message was dropped
+type ExpiryStage string
+
+const (
+	// StageWrite is the write path of the sender (including its write queue)
+	StageWrite ExpiryStage = "write"
+	// StageRead is the read path of the receiver
+	StageRead ExpiryStage = "read"
+	// StageForward is a relay, for example a room forwarding the message to its participants
+	StageForward ExpiryStage = "forward"
+)
+
+// Expirable is implemented by the messages whose expiry can be set; every message embedding BaseMessage is
+type Expirable interface {
+	SetExpiry(time.Time)
+}
+
+// Expired reports if the header carries an expiry which is before now
+func (h Header) Expired(now time.Time) bool {
+	return !h.Expiry.IsZero() && now.After(h.Expiry)
+}
+
+// SetExpiry sets the time after which the message must not be delivered; the zero time never expires
+func (m *BaseMessage) SetExpiry(expiry time.Time) {
+	m.CurrentHeader.Expiry = expiry
+}
+
+// SetTTL makes the message expire after ttl from now
+func (m *BaseMessage) SetTTL(ttl time.Duration) {
+	m.SetExpiry(time.Now().Add(ttl))
+}
+
+// IsExpired reports if the message expired
+func IsExpired(msg Message) bool {
+	return msg.GetCurrentHeader().Expired(time.Now())
+}
+
+// InheritExpiry copies the expiry of the inner message to the message wrapping it. Wrappers must
+// call it as the header of the inner message is not visible (or even readable) while it is wrapped.
+func InheritExpiry(wrapper Expirable, inner Message) {
+	if inner == nil {
+		return
+	}
+
+	if expiry := inner.GetCurrentHeader().Expiry; !expiry.IsZero() {
+		wrapper.SetExpiry(expiry)
+	}
+}
+
+// ExpiredMessage is sent back to the sender of a message that expired before it was delivered.
+type ExpiredMessage struct {
+	BaseMessage
+	ExpiredProtocol Protocol    `json:"expired_protocol"`
+	Expiry          time.Time   `json:"expiry"`
+	Stage           ExpiryStage `json:"stage"`
+}
+
+// NewExpiredMessage creates the message reporting that msg expired at the given stage
+func NewExpiredMessage(msg Message, stage ExpiryStage) (*ExpiredMessage, error) {
+	expired := &ExpiredMessage{
+		ExpiredProtocol: msg.GetProtocol(),
+		Expiry:          msg.GetCurrentHeader().Expiry,
+		Stage:           stage,
+	}
+
+	bmsg, err := NewBaseMessage(NoneProtocol, nil, expired)
+	if err != nil {
+		return nil, err
+	}
+	expired.BaseMessage = bmsg
+
+	return expired, nil
+}
+
+func (m *ExpiredMessage) GetProtocol() Protocol {
+	return ExpiredProtocol
+}
+
+func (m *ExpiredMessage) Validate() error {
+	if err := RequireNotEmpty("expired_protocol", m.ExpiredProtocol); err != nil {
+		return err
+	}
+
+	return RequireNotEmpty("stage", m.Stage)
+}
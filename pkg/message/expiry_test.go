@@ -0,0 +1,108 @@
+package message
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestHeader_Expired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		expiry time.Time
+		want   bool
+	}{
+		{name: "no expiry", expiry: time.Time{}, want: false},
+		{name: "in the future", expiry: now.Add(time.Minute), want: false},
+		{name: "in the past", expiry: now.Add(-time.Minute), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := Header{Expiry: tt.expiry}
+			if got := header.Expired(now); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiry_RoundTrip(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	msg := &testMessage{Text: "stale"}
+	base, err := NewBaseMessage(NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = base
+
+	data, err := Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if bytes.Contains(data, []byte(`"expiry"`)) {
+		t.Errorf("Marshal() = %s, want no expiry for a message without one", data)
+	}
+
+	msg.SetTTL(-time.Second)
+
+	data, err = Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	decoded, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	if !IsExpired(decoded) {
+		t.Errorf("IsExpired() = false after round trip, want true")
+	}
+
+	if !decoded.GetCurrentHeader().Expiry.Equal(msg.GetCurrentHeader().Expiry) {
+		t.Errorf("Expiry = %v, want %v", decoded.GetCurrentHeader().Expiry, msg.GetCurrentHeader().Expiry)
+	}
+}
+
+func TestInheritExpiry(t *testing.T) {
+	inner := &testOtherMessage{Count: 1}
+	base, err := NewBaseMessage(NoneProtocol, nil, inner)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	inner.BaseMessage = base
+
+	expiry := time.Now().Add(time.Minute)
+	inner.SetExpiry(expiry)
+
+	wrapper := &testMessage{}
+	base, err = NewBaseMessage(testOtherProtocol, inner, wrapper)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	wrapper.BaseMessage = base
+
+	InheritExpiry(wrapper, inner)
+
+	if !wrapper.GetCurrentHeader().Expiry.Equal(expiry) {
+		t.Errorf("Expiry = %v, want %v", wrapper.GetCurrentHeader().Expiry, expiry)
+	}
+
+	report, err := NewExpiredMessage(wrapper, StageForward)
+	if err != nil {
+		t.Fatalf("NewExpiredMessage() error = %v", err)
+	}
+
+	if report.ExpiredProtocol != testProtocol || report.Stage != StageForward || !report.Expiry.Equal(expiry) {
+		t.Errorf("NewExpiredMessage() = %+v, want protocol %s at stage %s", report, testProtocol, StageForward)
+	}
+
+	if err := report.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
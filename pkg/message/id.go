@@ -0,0 +1,22 @@
+package message
+
+import "encoding/hex"
+
+// Identifiable is implemented by the messages whose ID can be set; every message embedding BaseMessage is
+type Identifiable interface {
+	SetID(string)
+}
+
+// SetID sets the ID carried by the header of the message. A message sent again (for example, after a
+// reconnect) must keep its ID, so that the receiver can tell it is a duplicate.
+func (m *BaseMessage) SetID(id string) {
+	m.CurrentHeader.ID = id
+}
+
+// NewID returns a random message ID
+func NewID() string {
+	var id [16]byte
+	putRandom(id[:])
+
+	return hex.EncodeToString(id[:])
+}
@@ -4,8 +4,12 @@
 package message
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
 // Type aliases for improved readability and type safety
@@ -31,9 +35,13 @@ const (
 	// NoneProtocol indicates no nested message exists
 	NoneProtocol Protocol = "none"
 
-	// Version1 is the current message protocol version
+	// Version1 is the first message protocol version
 	Version1 Version = "v1.0"
 
+	// CurrentVersion is the message protocol version of the registered message structs.
+	// Messages received with any other version are migrated to it by the registry
+	CurrentVersion = Version1
+
 	// UnknownSender senderID initialising
 	UnknownSender Sender = "unknown.sender"
 
@@ -55,6 +63,56 @@ func (p Payload) Marshal() ([]byte, error) {
 	return p, nil
 }
 
+// NewOpaquePayload encodes data that is not a message, for example ciphertext or compressed bytes, as a
+// payload. The payload is always a base64 encoded JSON string, whatever the data looks like, so that it is
+// never mistaken for a nested message (always a JSON object). Use Payload.Opaque to decode it.
+func NewOpaquePayload(data []byte) Payload {
+	encoded, _ := json.Marshal(data) // NOTE: MARSHALLING A BYTE SLICE CANNOT FAIL
+	return encoded
+}
+
+// Opaque decodes a payload made by NewOpaquePayload. It returns ErrNotOpaque for any other payload.
+func (p Payload) Opaque() ([]byte, error) {
+	if len(p) == 0 || p[0] != '"' {
+		return nil, ErrNotOpaque
+	}
+
+	var data []byte
+	if err := json.Unmarshal(p, &data); err != nil {
+		return nil, fmt.Errorf("error while decoding opaque payload; err: %w", errors.Join(ErrNotOpaque, err))
+	}
+
+	return data, nil
+}
+
+// MarshalJSON embeds the payload as-is. A payload always holds JSON: either a nested message or an opaque
+// payload made by NewOpaquePayload; anything else fails to marshal.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+
+	return p, nil
+}
+
+// UnmarshalJSON keeps the payload as the raw JSON value without copying it.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return errors.New("message.Payload: UnmarshalJSON on nil pointer")
+	}
+
+	if string(data) == "null" {
+		*p = nil
+		return nil
+	}
+
+	// NOTE: ZERO-COPY; THE PAYLOAD ALIASES THE DECODED BUFFER INSTEAD OF COPYING IT. json.Unmarshal PASSES
+	// NOTE: A SUB-SLICE OF ITS INPUT, SO THE NESTED CHAIN SHARES THE BUFFER READ FROM THE CONNECTION.
+	// NOTE: THE BUFFER MUST NOT BE REUSED WHILE THE MESSAGE IS ALIVE; json.Decoder USERS MUST COPY IT FIRST
+	*p = data
+	return nil
+}
+
 // Message defines the interface that all message types must implement.
 // It provides methods for protocol identification, serialization, and
 // message nesting/unwrapping.
@@ -79,21 +137,29 @@ type Message interface {
 
 // Header contains common metadata for all messages
 type Header struct {
-	Sender   Sender   `json:"sender"`   // Sender identifies the message source
-	Receiver Receiver `json:"receiver"` // Receiver identifies the intended recipient
-	Version  Version  `json:"version"`  // Version specifies the protocol version
+	Sender      Sender    `json:"sender"`                // Sender identifies the message source
+	Receiver    Receiver  `json:"receiver"`              // Receiver identifies the intended recipient
+	Version     Version   `json:"version"`               // Version specifies the protocol version
+	Expiry      time.Time `json:"expiry,omitzero"`       // Expiry is the time after which the message must not be delivered; optional
+	TraceParent string    `json:"traceparent,omitempty"` // TraceParent is the W3C Trace Context of the message; optional
+	ID          string    `json:"id,omitempty"`          // ID identifies the message of the sender; retries keep it. Optional
 }
 
-// NewV1Header creates a new header with Version1
-// This is a convenience constructor for common header creation
-func NewV1Header(sender Sender, receiver Receiver) Header {
+// NewHeader creates a new header with the given version
+func NewHeader(sender Sender, receiver Receiver, version Version) Header {
 	return Header{
 		Sender:   sender,
 		Receiver: receiver,
-		Version:  Version1,
+		Version:  version,
 	}
 }
 
+// NewV1Header creates a new header with Version1
+// This is a convenience constructor for common header creation
+func NewV1Header(sender Sender, receiver Receiver) Header {
+	return NewHeader(sender, receiver, Version1)
+}
+
 // BaseMessage provides a foundation for all message types.
 // It implements the Message interface and manages message nesting.
 // Custom message types should embed this struct to inherit its functionality.
@@ -106,6 +172,11 @@ type BaseMessage struct {
 	// NEXT MESSAGE PROCESSOR
 	NextPayload  Payload  `json:"next,omitempty"` // NextPayload contains the serialized next message in the chain
 	NextProtocol Protocol `json:"next_protocol"`  // NextProtocol identifies the type of the next message. NoneProtocol indicates end of chain
+
+	// next caches the message decoded from decodedFrom (the NextPayload at the time of decoding); it is
+	// dropped by SetNextPayload
+	next        Message
+	decodedFrom Payload
 }
 
 // GetProtocol returns this message's protocol identifier
@@ -133,9 +204,20 @@ func (m *BaseMessage) GetCurrentHeader() Header {
 	return m.CurrentHeader
 }
 
+// SetNextPayload replaces the serialized next message and drops the cached decoded one.
+// NOTE: A PAYLOAD MUTATED IN PLACE (FOR EXAMPLE, DECRYPTED INTO ITS OWN BUFFER) MUST BE SET AGAIN USING
+// NOTE: SetNextPayload; GetNext CANNOT TELL FROM THE SLICE ALONE THAT ITS CONTENTS CHANGED
+func (m *BaseMessage) SetNextPayload(payload Payload) {
+	m.NextPayload = payload
+	m.next, m.decodedFrom = nil, nil
+}
+
 // GetNext retrieves the next message in the chain, if one exists.
 // Returns nil, nil if NextProtocol is NoneProtocol.
-// Uses the provided Registry to create and unmarshal the next message.
+// Uses the provided Registry to create and unmarshal the next message. The next message
+// is decoded lazily on the first call and cached; it is decoded again only if NextPayload
+// was set using SetNextPayload (for example, after decryption), assigned another slice, or
+// if NextProtocol changed in the meantime.
 func (m *BaseMessage) GetNext(registry Registry) (Message, error) {
 	if m.NextProtocol == NoneProtocol {
 		return nil, nil
@@ -145,7 +227,22 @@ func (m *BaseMessage) GetNext(registry Registry) (Message, error) {
 		return nil, ErrNoPayload
 	}
 
-	return registry.Unmarshal(m.NextProtocol, m.NextPayload)
+	if m.next != nil && m.next.GetProtocol() == m.NextProtocol && samePayload(m.decodedFrom, m.NextPayload) {
+		return m.next, nil
+	}
+
+	next, err := registry.Unmarshal(m.NextProtocol, m.NextPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	m.next, m.decodedFrom = next, m.NextPayload
+	return next, nil
+}
+
+// samePayload reports if both the payloads refer to the same memory; it does not compare the contents
+func samePayload(a, b Payload) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
 }
 
 // Marshal serializes the message to JSON format
@@ -158,13 +255,66 @@ func (m *BaseMessage) Unmarshal(data []byte) error {
 	return json.Unmarshal(data, m)
 }
 
+// Marshal serializes the given value to JSON format.
+// If the value is a Message, the complete concrete message is encoded; calling Marshal on
+// a type that embeds BaseMessage would otherwise only encode the base fields.
+// Any other Marshallable (for example, a Payload) is serialized using its own Marshal method.
+func Marshal(m Marshallable) ([]byte, error) {
+	if msg, ok := m.(Message); ok {
+		return json.Marshal(msg)
+	}
+
+	return m.Marshal()
+}
+
+// encoder is a reusable json.Encoder writing into its own buffer
+type encoder struct {
+	buffer  bytes.Buffer
+	encoder *json.Encoder
+}
+
+// encoderPool holds the encoders used by Encode
+var encoderPool = sync.Pool{
+	New: func() any {
+		e := &encoder{}
+		e.encoder = json.NewEncoder(&e.buffer)
+		e.encoder.SetEscapeHTML(false)
+		return e
+	},
+}
+
+// Encode serializes the message like Marshal but into a pooled buffer, which is handed to use.
+// The slice passed to use is only valid until use returns; it must be copied to be retained.
+// This avoids an allocation per message on hot write paths where the bytes are copied anyway.
+func Encode(m Marshallable, use func([]byte) error) error {
+	msg, ok := m.(Message)
+	if !ok {
+		data, err := m.Marshal()
+		if err != nil {
+			return err
+		}
+		return use(data)
+	}
+
+	e := encoderPool.Get().(*encoder)
+	defer encoderPool.Put(e)
+	e.buffer.Reset()
+
+	if err := e.encoder.Encode(msg); err != nil {
+		return err
+	}
+
+	// NOTE: json.Encoder TERMINATES EVERY VALUE WITH A NEWLINE
+	return use(bytes.TrimSuffix(e.buffer.Bytes(), []byte("\n")))
+}
+
 func NewBaseMessage(nextProtocol Protocol, nextPayload Marshallable, msg Message) (BaseMessage, error) {
 	var inner json.RawMessage = nil
 	if nextPayload != nil {
 		if nextProtocol == NoneProtocol {
 			return BaseMessage{}, fmt.Errorf("nextPayload was empty, but protocol was not - protocol: %s", nextProtocol)
 		}
-		_inner, err := nextPayload.Marshal()
+		_inner, err := Marshal(nextPayload)
 		if err != nil {
 			return BaseMessage{}, err
 		}
@@ -173,7 +323,7 @@ func NewBaseMessage(nextProtocol Protocol, nextPayload Marshallable, msg Message
 
 	return BaseMessage{
 		CurrentProtocol: msg.GetProtocol(),
-		CurrentHeader:   NewV1Header(UnknownSender, UnknownReceiver),
+		CurrentHeader:   NewHeader(UnknownSender, UnknownReceiver, CurrentVersion),
 		NextPayload:     Payload(inner),
 		NextProtocol:    nextProtocol,
 	}, nil
@@ -0,0 +1,110 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newBenchmarkChain builds a chain of depth messages; the innermost carries a 1KiB text.
+func newBenchmarkChain(b *testing.B, depth int) Payload {
+	b.Helper()
+
+	inner := &testMessage{Text: strings.Repeat("x", 1024)}
+	base, err := NewBaseMessage(NoneProtocol, nil, inner)
+	if err != nil {
+		b.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	inner.BaseMessage = base
+
+	var current Message = inner
+	for i := 1; i < depth; i++ {
+		outer := &testOtherMessage{Count: i}
+		base, err := NewBaseMessage(current.GetProtocol(), current, outer)
+		if err != nil {
+			b.Fatalf("NewBaseMessage() error = %v", err)
+		}
+		outer.BaseMessage = base
+		current = outer
+	}
+
+	data, err := Marshal(current)
+	if err != nil {
+		b.Fatalf("Marshal() error = %v", err)
+	}
+
+	return data
+}
+
+// BenchmarkUnmarshalChain decodes a chain and walks it the way the interceptors do:
+// every layer asks for the next message twice (once to check it, once to process it).
+func BenchmarkUnmarshalChain(b *testing.B) {
+	for _, depth := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("depth-%d", depth), func(b *testing.B) {
+			registry := NewDefaultRegistry()
+			MustRegisterAll(registry, Type[testMessage](testProtocol), Type[testOtherMessage](testOtherProtocol))
+			data := newBenchmarkChain(b, depth)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				msg, err := registry.UnmarshalRaw(data)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				for msg != nil {
+					if _, err := msg.GetNext(registry); err != nil {
+						b.Fatal(err)
+					}
+
+					if msg, err = msg.GetNext(registry); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkWrite compares serializing a message for a connection which copies the bytes
+// (like the socket adaptor) using Marshal and using the pooled Encode.
+func BenchmarkWrite(b *testing.B) {
+	msg := &testMessage{Text: strings.Repeat("x", 1024)}
+	base, err := NewBaseMessage(NoneProtocol, nil, msg)
+	if err != nil {
+		b.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = base
+
+	write := func(p []byte) error {
+		q := make([]byte, len(p))
+		copy(q, p)
+		return nil
+	}
+
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := Marshal(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if err := write(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("encode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := Encode(msg, write); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package message
+
+import (
+	"fmt"
+)
+
+// Registration pairs a protocol with the factory that creates its messages.
+// A slice of registrations describes the full message set of a middleware and
+// can be registered at once using RegisterAll.
+type Registration struct {
+	Protocol Protocol
+	Factory  Factory
+}
+
+// TypeFactory returns a Factory that creates a new zero-valued instance of T.
+// PT is inferred from T and must be a pointer to T that implements Message;
+// this allows message structs to be registered without a hand-written factory.
+func TypeFactory[T any, PT interface {
+	*T
+	Message
+}]() Factory {
+	return EmptyFactoryFunc(func() (Message, error) {
+		return PT(new(T)), nil
+	})
+}
+
+// Type builds a Registration for the message type T under the given protocol.
+// It is intended to be used when declaring the message set of a middleware:
+//
+//	message.Type[CreateRoom](CreateRoomProtocol)
+func Type[T any, PT interface {
+	*T
+	Message
+}](protocol Protocol) Registration {
+	return Registration{
+		Protocol: protocol,
+		Factory:  TypeFactory[T, PT](),
+	}
+}
+
+// RegisterType registers the message type T under the given protocol in the registry.
+// It returns the error from Registry.Register if the protocol is already registered.
+func RegisterType[T any, PT interface {
+	*T
+	Message
+}](registry Registry, protocol Protocol) error {
+	return registry.Register(protocol, TypeFactory[T, PT]())
+}
+
+// MustRegister is like Registry.Register but panics if the registration fails.
+// It is meant for package initialisation where a failure is a programming error.
+func MustRegister(registry Registry, protocol Protocol, factory Factory) {
+	if err := registry.Register(protocol, factory); err != nil {
+		panic(fmt.Sprintf("message: failed to register protocol %s; err: %s", protocol, err.Error()))
+	}
+}
+
+// RegisterAll registers every given registration in the registry.
+// Registration stops at the first failure and the error names the offending protocol.
+func RegisterAll(registry Registry, registrations ...Registration) error {
+	for _, registration := range registrations {
+		if registration.Factory == nil {
+			return fmt.Errorf("error while registering protocol %s; err: nil factory", registration.Protocol)
+		}
+
+		if err := registry.Register(registration.Protocol, registration.Factory); err != nil {
+			return fmt.Errorf("error while registering protocol %s; err: %w", registration.Protocol, err)
+		}
+	}
+
+	return nil
+}
+
+// MustRegisterAll is like RegisterAll but panics if any registration fails.
+func MustRegisterAll(registry Registry, registrations ...Registration) {
+	if err := RegisterAll(registry, registrations...); err != nil {
+		panic(fmt.Sprintf("message: %s", err.Error()))
+	}
+}
@@ -0,0 +1,150 @@
+package message
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testProtocol      Protocol = "test:message"
+	testOtherProtocol Protocol = "test:other_message"
+)
+
+type testMessage struct {
+	BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *testMessage) GetProtocol() Protocol {
+	return testProtocol
+}
+
+type testOtherMessage struct {
+	BaseMessage
+	Count int `json:"count"`
+}
+
+func (m *testOtherMessage) GetProtocol() Protocol {
+	return testOtherProtocol
+}
+
+func TestRegisterType(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	if err := RegisterType[testMessage](registry, testProtocol); err != nil {
+		t.Fatalf("RegisterType() error = %v", err)
+	}
+
+	if !registry.Check(testProtocol) {
+		t.Fatalf("protocol %s not registered", testProtocol)
+	}
+
+	msg, err := registry.UnmarshalRaw(Payload(`{"protocol":"test:message","text":"hello","next_protocol":"none"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	m, ok := msg.(*testMessage)
+	if !ok {
+		t.Fatalf("UnmarshalRaw() type = %T, want *testMessage", msg)
+	}
+
+	if m.Text != "hello" {
+		t.Errorf("Text = %q, want %q", m.Text, "hello")
+	}
+
+	if err := RegisterType[testMessage](registry, testProtocol); err == nil {
+		t.Errorf("RegisterType() on duplicate protocol expected error, got nil")
+	}
+}
+
+func TestTypeFactory_CreatesFreshInstances(t *testing.T) {
+	factory := TypeFactory[testMessage]()
+
+	first, err := factory.Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	second, err := factory.Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if first == second {
+		t.Errorf("Create() returned the same instance twice")
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		registrations []Registration
+		wantErr       bool
+	}{
+		{
+			name: "registers every message",
+			registrations: []Registration{
+				Type[testMessage](testProtocol),
+				Type[testOtherMessage](testOtherProtocol),
+			},
+		},
+		{
+			name: "duplicate protocol",
+			registrations: []Registration{
+				Type[testMessage](testProtocol),
+				Type[testOtherMessage](testProtocol),
+			},
+			wantErr: true,
+		},
+		{
+			name: "nil factory",
+			registrations: []Registration{
+				{Protocol: testProtocol},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewDefaultRegistry()
+
+			err := RegisterAll(registry, tt.registrations...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RegisterAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			for _, registration := range tt.registrations {
+				if !registry.Check(registration.Protocol) {
+					t.Errorf("protocol %s not registered", registration.Protocol)
+				}
+			}
+		})
+	}
+}
+
+func TestMustRegister_PanicsOnDuplicate(t *testing.T) {
+	registry := NewDefaultRegistry()
+	MustRegister(registry, testProtocol, TypeFactory[testMessage]())
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRegister() on duplicate protocol did not panic")
+		}
+	}()
+
+	MustRegister(registry, testProtocol, TypeFactory[testMessage]())
+}
+
+func TestUnmarshal_UnknownProtocol(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	if _, err := registry.Unmarshal(testProtocol, Payload(`{}`)); !errors.Is(err, ErrNoProtocolMatch) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrNoProtocolMatch)
+	}
+}
@@ -5,6 +5,7 @@ package message
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -13,15 +14,44 @@ import (
 // during the unmarshaling process.
 type envelope struct {
 	Protocol Protocol `json:"protocol"`
+	Header   struct {
+		Version Version `json:"version"`
+	} `json:"header"`
+}
+
+// version returns the version carried by the envelope. Messages without a version
+// are assumed to be of the CurrentVersion.
+func (e envelope) version() Version {
+	if e.Header.Version == "" {
+		return CurrentVersion
+	}
+
+	return e.Header.Version
 }
 
 // Registry defines the interface for a message registry system that manages
 // different message protocols. It provides methods for registering message factories,
 // checking protocol existence, and unmarshaling messages from different formats.
 type Registry interface {
-	// Register adds a new protocol to the registry.
+	// Register adds a new protocol to the registry at the CurrentVersion.
 	Register(Protocol, Factory) error
 
+	// RegisterVersion adds a factory for a specific (older) version of a protocol.
+	// Messages received with that version are decoded using this factory when no
+	// migration to the CurrentVersion is available.
+	RegisterVersion(Protocol, Version, Factory) error
+
+	// RegisterMigration adds a function transforming a payload of the protocol from one
+	// version to another. Migrations are used both for upgrading received messages and
+	// for downgrading messages sent to peers that negotiated an older version.
+	RegisterMigration(protocol Protocol, from Version, to Version, migration Migration) error
+
+	// Downgrade serializes the message for a peer that understands the given version.
+	Downgrade(Message, Version) (Payload, error)
+
+	// Supports reports whether messages of the version can be exchanged with a peer.
+	Supports(Version) bool
+
 	// Check function verifies if a protocol is registered in the registry.
 	Check(protocol Protocol) bool
 
@@ -30,6 +60,12 @@ type Registry interface {
 
 	// UnmarshalRaw extracts the protocol from a raw JSON message and unmarshals it accordingly.
 	UnmarshalRaw(Payload) (Message, error)
+
+	// Protocols lists all the registered protocols in a stable (sorted) order.
+	Protocols() []Protocol
+
+	// Create instantiates a new, empty message of the specified protocol.
+	Create(Protocol) (Message, error)
 }
 
 // Factory defines an interface for creating new message instances.
@@ -50,12 +86,22 @@ func (f EmptyFactoryFunc) Create() (Message, error) {
 	return f()
 }
 
+// versionedProtocol is the key of the factories map; the same protocol can be
+// registered once per version.
+type versionedProtocol struct {
+	protocol Protocol
+	version  Version
+}
+
 // DefaultRegistry is the standard implementation of the Registry interface.
-// It maintains a thread-safe map of protocol-to-factory mappings.
+// It maintains a thread-safe map of (protocol, version)-to-factory mappings
+// and the migrations between the versions of each protocol.
 type DefaultRegistry struct {
-	// factories field maps protocol identifiers to their corresponding message factories
-	factories map[Protocol]Factory
-	// mux provides thread-safety for concurrent access to the factories map
+	// factories field maps protocol identifiers and versions to their corresponding message factories
+	factories map[versionedProtocol]Factory
+	// migrations field maps a protocol, a source version and a target version to the corresponding migration
+	migrations map[Protocol]map[Version]map[Version]Migration
+	// mux provides thread-safety for concurrent access to the maps
 	mux sync.RWMutex
 }
 
@@ -63,53 +109,140 @@ type DefaultRegistry struct {
 // It initializes the factories map and returns a ready-to-use registry.
 func NewDefaultRegistry() *DefaultRegistry {
 	return &DefaultRegistry{
-		factories: make(map[Protocol]Factory),
+		factories:  make(map[versionedProtocol]Factory),
+		migrations: make(map[Protocol]map[Version]map[Version]Migration),
 	}
 }
 
-// Register adds a new protocol and its associated factory to the registry.
+// Register adds a new protocol and its associated factory to the registry at the CurrentVersion.
 // It returns an error if the protocol is already registered.
 // This method is thread-safe.
 func (r *DefaultRegistry) Register(protocol Protocol, factory Factory) error {
+	return r.RegisterVersion(protocol, CurrentVersion, factory)
+}
+
+// RegisterVersion adds a factory for the given version of the protocol.
+// It returns an error if the version of the protocol is already registered.
+// This method is thread-safe.
+func (r *DefaultRegistry) RegisterVersion(protocol Protocol, version Version, factory Factory) error {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 
-	if _, exists := r.factories[protocol]; exists {
-		return fmt.Errorf("protocol %s is already registered", protocol)
+	key := versionedProtocol{protocol: protocol, version: version}
+	if _, exists := r.factories[key]; exists {
+		return fmt.Errorf("protocol %s (%s) is already registered", protocol, version)
 	}
 
-	r.factories[protocol] = factory
+	r.factories[key] = factory
 	return nil
 }
 
-// Check verifies if a protocol is registered in the registry.
+// Check verifies if a protocol is registered in the registry at the CurrentVersion.
 // Returns true if the protocol exists, false otherwise.
 // This method is thread-safe and uses a read lock for better concurrency.
 func (r *DefaultRegistry) Check(protocol Protocol) bool {
 	r.mux.RLock()
 	defer r.mux.RUnlock()
 
-	_, exists := r.factories[protocol]
+	_, exists := r.factories[versionedProtocol{protocol: protocol, version: CurrentVersion}]
 	return exists
 }
 
+// Protocols returns all the protocols registered at the CurrentVersion sorted lexicographically.
+// This method is thread-safe.
+func (r *DefaultRegistry) Protocols() []Protocol {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	protocols := make([]Protocol, 0, len(r.factories))
+	for key := range r.factories {
+		if key.version != CurrentVersion {
+			continue
+		}
+		protocols = append(protocols, key.protocol)
+	}
+
+	sort.Slice(protocols, func(i, j int) bool {
+		return protocols[i] < protocols[j]
+	})
+
+	return protocols
+}
+
+// Create instantiates a new, empty message of the CurrentVersion for the specified protocol using its factory.
+// It returns ErrNoProtocolMatch if the protocol is not registered.
+// This method is thread-safe.
+func (r *DefaultRegistry) Create(protocol Protocol) (Message, error) {
+	return r.create(protocol, CurrentVersion)
+}
+
+func (r *DefaultRegistry) create(protocol Protocol, version Version) (Message, error) {
+	r.mux.RLock()
+	factory, exists := r.factories[versionedProtocol{protocol: protocol, version: version}]
+	r.mux.RUnlock()
+
+	if !exists {
+		return nil, ErrNoProtocolMatch
+	}
+
+	return factory.Create()
+}
+
 // Unmarshal creates a new message instance for the specified protocol and
 // populates it with the provided data. It returns an error if:
 // - The protocol is not registered
 // - The factory fails to create a message instance
 // - The message fails to unmarshal the data
+// - The message implements Validator and is not valid (the error is a *ValidationError)
+// Messages of an older version are first migrated to the CurrentVersion (see Upgrade).
 // This method is thread-safe.
 func (r *DefaultRegistry) Unmarshal(protocol Protocol, data Payload) (Message, error) {
-	if !r.Check(protocol) {
-		return nil, ErrNoProtocolMatch
+	msg, err := r.create(protocol, CurrentVersion)
+	if err != nil {
+		// NOTE: THE PROTOCOL MIGHT ONLY BE REGISTERED AT AN OLDER VERSION
+		var envelope envelope
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, err
+		}
+
+		return r.unmarshal(protocol, envelope.version(), data)
+	}
+
+	// NOTE: THE COMMON CASE (A MESSAGE OF THE CURRENT VERSION) IS DECODED IN A SINGLE PASS; THE
+	// NOTE: VERSION IS READ FROM THE DECODED HEADER INSTEAD OF PEEKING THE ENVELOPE FIRST
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	if version := msg.GetCurrentHeader().Version; version != "" && version != CurrentVersion {
+		return r.unmarshal(protocol, version, data)
 	}
 
-	msg, err := r.factories[protocol].Create()
+	if err := validate(protocol, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (r *DefaultRegistry) unmarshal(protocol Protocol, version Version, data Payload) (Message, error) {
+	version, data, err := r.upgrade(protocol, version, data)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := msg.Unmarshal(data); err != nil {
+	msg, err := r.create(protocol, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: DECODING INTO THE CONCRETE MESSAGE; msg.Unmarshal IS PROMOTED FROM THE EMBEDDED BaseMessage
+	// NOTE: AND WOULD ONLY FILL THE BASE FIELDS. TYPES NEEDING CUSTOM DECODING SHOULD IMPLEMENT json.Unmarshaler
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	if err := validate(protocol, msg); err != nil {
 		return nil, err
 	}
 
@@ -133,5 +266,5 @@ func (r *DefaultRegistry) UnmarshalRaw(data Payload) (Message, error) {
 		return nil, ErrInvalidMessageData
 	}
 
-	return r.Unmarshal(envelope.Protocol, data)
+	return r.unmarshal(envelope.Protocol, envelope.version(), data)
 }
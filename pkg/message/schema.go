@@ -0,0 +1,276 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaDialect is the JSON Schema dialect of the generated documents.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a (partial) JSON Schema document. Only the keywords required to describe
+// the wire format of the registered messages are supported.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Marshal serializes the schema to JSON format
+func (s *Schema) Marshal() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	payloadType  = reflect.TypeOf(Payload{})
+	marshalerTyp = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// GenerateSchemas builds a single JSON Schema document describing every protocol registered
+// in the registry. Each message is described under "$defs" keyed by its protocol, and the
+// root accepts any one of them. Nested messages ("next") reference the same definitions,
+// so complete interceptor chains are described.
+func GenerateSchemas(registry Registry) (*Schema, error) {
+	protocols := registry.Protocols()
+
+	defs, err := generateDefinitions(registry, protocols)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &Schema{
+		Dialect:     SchemaDialect,
+		Title:       "socket-comm messages",
+		Description: "all the messages registered in the message registry",
+		OneOf:       refsTo(protocols),
+		Defs:        defs,
+	}
+
+	return root, nil
+}
+
+// GenerateSchema builds a JSON Schema document for the given protocol. The document also
+// carries the definitions of all the other registered protocols under "$defs" as they can
+// appear nested in the "next" field of the message.
+func GenerateSchema(registry Registry, protocol Protocol) (*Schema, error) {
+	if !registry.Check(protocol) {
+		return nil, fmt.Errorf("error while generating schema for protocol %s; err: %w", protocol, ErrNoProtocolMatch)
+	}
+
+	defs, err := generateDefinitions(registry, registry.Protocols())
+	if err != nil {
+		return nil, err
+	}
+
+	root := *defs[string(protocol)]
+	root.Dialect = SchemaDialect
+	root.Defs = defs
+
+	return &root, nil
+}
+
+func generateDefinitions(registry Registry, protocols []Protocol) (map[string]*Schema, error) {
+	defs := make(map[string]*Schema, len(protocols))
+
+	for _, protocol := range protocols {
+		msg, err := registry.Create(protocol)
+		if err != nil {
+			return nil, fmt.Errorf("error while generating schema for protocol %s; err: %w", protocol, err)
+		}
+
+		if msg == nil {
+			return nil, fmt.Errorf("error while generating schema for protocol %s; err: factory returned nil message", protocol)
+		}
+
+		schema := schemaForType(reflect.TypeOf(msg), make(map[reflect.Type]bool))
+		schema.Title = string(protocol)
+		schema.Description = reflect.TypeOf(msg).String()
+
+		if schema.Properties != nil {
+			if p, ok := schema.Properties["protocol"]; ok {
+				p.Const = protocol
+			}
+
+			if p, ok := schema.Properties["next_protocol"]; ok {
+				p.Enum = protocolEnum(protocols)
+			}
+
+			if p, ok := schema.Properties["next"]; ok {
+				p.OneOf = append(refsTo(protocols), p.OneOf...)
+			}
+		}
+
+		defs[string(protocol)] = schema
+	}
+
+	return defs, nil
+}
+
+func refsTo(protocols []Protocol) []*Schema {
+	refs := make([]*Schema, 0, len(protocols))
+	for _, protocol := range protocols {
+		refs = append(refs, &Schema{Ref: "#/$defs/" + escapeRef(string(protocol))})
+	}
+
+	return refs
+}
+
+func protocolEnum(protocols []Protocol) []any {
+	enum := make([]any, 0, len(protocols)+1)
+	enum = append(enum, NoneProtocol)
+	for _, protocol := range protocols {
+		enum = append(enum, protocol)
+	}
+
+	return enum
+}
+
+// escapeRef escapes a definition name to be used in a JSON pointer (RFC 6901).
+func escapeRef(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Description: "duration in nanoseconds"}
+	case payloadType:
+		// NOTE: THE PROTOCOL REFERENCES ARE ADDED BY THE CALLER AS ONLY IT KNOWS THE REGISTRY
+		return &Schema{OneOf: []*Schema{{Type: "string", ContentEncoding: "base64", Description: "opaque payload"}}}
+	}
+
+	if t.Implements(marshalerTyp) || reflect.PointerTo(t).Implements(marshalerTyp) {
+		// NOTE: CUSTOM ENCODING; THE WIRE FORMAT CANNOT BE DERIVED FROM THE GO TYPE
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), visiting)}
+	case reflect.Array:
+		length := t.Len()
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), visiting), MinItems: &length, MaxItems: &length}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			// NOTE: RECURSIVE TYPE; DESCRIBING AS ANY TO AVOID INFINITE EXPANSION
+			return &Schema{}
+		}
+
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		return schemaForStruct(t, visiting)
+	default:
+		// interfaces and anything else that encoding/json accepts dynamically
+		return &Schema{}
+	}
+}
+
+func schemaForStruct(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	promoted := make([]*Schema, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			// NOTE: FIELDS OF EMBEDDED STRUCTS ARE PROMOTED BY encoding/json; SHALLOWER FIELDS WIN
+			promoted = append(promoted, schemaForType(fieldType, visiting))
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaForType(field.Type, visiting)
+		if strings.Contains(options, "string") {
+			property = &Schema{Type: "string"}
+		}
+
+		schema.Properties[name] = property
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, embedded := range promoted {
+		required := make(map[string]bool, len(embedded.Required))
+		for _, name := range embedded.Required {
+			required[name] = true
+		}
+
+		for name, property := range embedded.Properties {
+			if _, exists := schema.Properties[name]; exists {
+				continue
+			}
+
+			schema.Properties[name] = property
+			if required[name] {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	sort.Strings(schema.Required)
+	return schema
+}
@@ -0,0 +1,240 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T) *DefaultRegistry {
+	t.Helper()
+
+	registry := NewDefaultRegistry()
+	if err := RegisterAll(registry, Type[testMessage](testProtocol), Type[testOtherMessage](testOtherProtocol)); err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+
+	return registry
+}
+
+func TestDefaultRegistry_Protocols(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	protocols := registry.Protocols()
+	want := []Protocol{testProtocol, testOtherProtocol}
+
+	if len(protocols) != len(want) {
+		t.Fatalf("Protocols() = %v, want %v", protocols, want)
+	}
+
+	for i := range want {
+		if protocols[i] != want[i] {
+			t.Errorf("Protocols()[%d] = %s, want %s", i, protocols[i], want[i])
+		}
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	schema, err := GenerateSchema(registry, testProtocol)
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+
+	if schema.Dialect != SchemaDialect {
+		t.Errorf("Dialect = %q, want %q", schema.Dialect, SchemaDialect)
+	}
+
+	if schema.Type != "object" {
+		t.Fatalf("Type = %q, want object", schema.Type)
+	}
+
+	text, ok := schema.Properties["text"]
+	if !ok || text.Type != "string" {
+		t.Errorf("properties.text = %+v, want string", text)
+	}
+
+	if p := schema.Properties["protocol"]; p == nil || p.Const != testProtocol {
+		t.Errorf("properties.protocol = %+v, want const %s", p, testProtocol)
+	}
+
+	header, ok := schema.Properties["header"]
+	if !ok || header.Properties["version"] == nil {
+		t.Errorf("properties.header = %+v, want header object with version", header)
+	}
+
+	next, ok := schema.Properties["next"]
+	if !ok {
+		t.Fatalf("properties.next missing")
+	}
+
+	refs := map[string]bool{}
+	for _, option := range next.OneOf {
+		refs[option.Ref] = true
+	}
+
+	for _, protocol := range registry.Protocols() {
+		if !refs["#/$defs/"+string(protocol)] {
+			t.Errorf("properties.next does not reference %s", protocol)
+		}
+
+		if _, ok := schema.Defs[string(protocol)]; !ok {
+			t.Errorf("$defs missing %s", protocol)
+		}
+	}
+
+	for _, required := range schema.Required {
+		if required == "next" {
+			t.Errorf("next must not be required")
+		}
+	}
+
+	if _, err := schema.Marshal(); err != nil {
+		t.Errorf("Marshal() error = %v", err)
+	}
+}
+
+func TestGenerateSchema_UnknownProtocol(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	if _, err := GenerateSchema(registry, "test:unknown"); !errors.Is(err, ErrNoProtocolMatch) {
+		t.Errorf("GenerateSchema() error = %v, want %v", err, ErrNoProtocolMatch)
+	}
+}
+
+func TestPayload_NestedRoundTrip(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	inner := &testOtherMessage{Count: 7}
+	base, err := NewBaseMessage(NoneProtocol, nil, inner)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	inner.BaseMessage = base
+
+	outer := &testMessage{Text: "outer"}
+	base, err = NewBaseMessage(testOtherProtocol, inner, outer)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	outer.BaseMessage = base
+
+	data, err := Marshal(outer)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(raw["next"]) == 0 || raw["next"][0] != '{' {
+		t.Errorf("next = %s, want an embedded JSON object", raw["next"])
+	}
+
+	msg, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	next, err := msg.GetNext(registry)
+	if err != nil {
+		t.Fatalf("GetNext() error = %v", err)
+	}
+
+	if m, ok := next.(*testOtherMessage); !ok || m.Count != 7 {
+		t.Errorf("GetNext() = %+v, want *testOtherMessage with Count 7", next)
+	}
+}
+
+func TestGetNext_SetNextPayloadInvalidatesCache(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	payload := Payload(`{"protocol":"test:other_message","count":1,"next_protocol":"none"}`)
+
+	msg := &testMessage{}
+	base, err := NewBaseMessage(testOtherProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	base.NextPayload = payload
+	msg.BaseMessage = base
+
+	first, err := msg.GetNext(registry)
+	if err != nil {
+		t.Fatalf("GetNext() error = %v", err)
+	}
+
+	if again, _ := msg.GetNext(registry); again != first {
+		t.Errorf("GetNext() decoded the unchanged payload again")
+	}
+
+	// NOTE: SAME BUFFER, SAME LENGTH, NEW CONTENTS; LIKE A PAYLOAD DECRYPTED IN PLACE
+	copy(payload, `{"protocol":"test:other_message","count":2,"next_protocol":"none"}`)
+	msg.SetNextPayload(payload)
+
+	next, err := msg.GetNext(registry)
+	if err != nil {
+		t.Fatalf("GetNext() error = %v", err)
+	}
+
+	if m, ok := next.(*testOtherMessage); !ok || m.Count != 2 {
+		t.Errorf("GetNext() = %+v, want the message decoded from the new contents", next)
+	}
+}
+
+func TestPayload_OpaqueRoundTrip(t *testing.T) {
+	tests := map[string][]byte{
+		"binary":      {0x00, 0xff, 0x10},
+		"json string": []byte(`"hello"`),
+		"json number": []byte(`123`),
+		"json object": []byte(`{"protocol":"test:message"}`),
+		"json null":   []byte(`null`),
+		"empty":       {},
+	}
+
+	for name, opaque := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := &testMessage{Text: "hello"}
+			base, err := NewBaseMessage(testOtherProtocol, nil, msg)
+			if err != nil {
+				t.Fatalf("NewBaseMessage() error = %v", err)
+			}
+			base.NextPayload = NewOpaquePayload(opaque)
+			msg.BaseMessage = base
+
+			data, err := json.Marshal(msg)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			var decoded testMessage
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			got, err := decoded.NextPayload.Opaque()
+			if err != nil {
+				t.Fatalf("Opaque() error = %v", err)
+			}
+
+			if !bytes.Equal(got, opaque) {
+				t.Errorf("Opaque() = %q, want %q", got, opaque)
+			}
+		})
+	}
+}
+
+func TestPayload_NestedIsNotOpaque(t *testing.T) {
+	if _, err := Payload(`{"protocol":"test:message"}`).Opaque(); !errors.Is(err, ErrNotOpaque) {
+		t.Errorf("Opaque() error = %v, want %v", err, ErrNotOpaque)
+	}
+
+	// NOTE: RAW BYTES MUST BE ENCODED WITH NewOpaquePayload; THEY ARE NEVER GUESSED
+	if _, err := json.Marshal(Payload{0x00, 0xff}); err == nil {
+		t.Error("json.Marshal() error = nil, want an error for a payload which is not JSON")
+	}
+}
@@ -0,0 +1,183 @@
+package message
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// ErrInvalidTraceParent is returned when a traceparent does not follow the W3C Trace Context format
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// TraceID identifies a trace; it is shared by all the spans of the trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// FlagSampled is the trace flag telling that the trace is recorded
+const FlagSampled byte = 0x01
+
+// TraceContext is the W3C Trace Context of a message: the trace it belongs to and the span which sent it.
+// It is carried in the header of the message in the traceparent format.
+type TraceContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+// NewTraceContext starts a new sampled trace
+func NewTraceContext() TraceContext {
+	tc := TraceContext{Flags: FlagSampled}
+	for !tc.TraceID.IsValid() {
+		putRandom(tc.TraceID[:])
+	}
+	tc.SpanID = newSpanID()
+
+	return tc
+}
+
+// NewChild returns the context of a new span of the same trace; a new trace is started if tc is not valid
+func (tc TraceContext) NewChild() TraceContext {
+	if !tc.IsValid() {
+		return NewTraceContext()
+	}
+
+	return TraceContext{TraceID: tc.TraceID, SpanID: newSpanID(), Flags: tc.Flags}
+}
+
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID.IsValid() && tc.SpanID.IsValid()
+}
+
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&FlagSampled != 0
+}
+
+// String formats the context as a W3C traceparent ("00-<trace-id>-<span-id>-<flags>");
+// an invalid context is formatted as an empty string.
+func (tc TraceContext) String() string {
+	if !tc.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// ParseTraceParent parses a W3C traceparent. Only version 00 is understood; as the specification
+// requires, the known fields of a higher version are accepted.
+func ParseTraceParent(traceparent string) (TraceContext, error) {
+	var tc TraceContext
+
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return tc, fmt.Errorf("error while parsing traceparent '%s'; err: %w", traceparent, ErrInvalidTraceParent)
+	}
+
+	if err := decodeHex(tc.TraceID[:], parts[1]); err != nil {
+		return tc, fmt.Errorf("error while parsing trace id of '%s'; err: %w", traceparent, err)
+	}
+
+	if err := decodeHex(tc.SpanID[:], parts[2]); err != nil {
+		return tc, fmt.Errorf("error while parsing span id of '%s'; err: %w", traceparent, err)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return tc, fmt.Errorf("error while parsing flags of '%s'; err: %w", traceparent, err)
+	}
+	tc.Flags = flags[0]
+
+	if !tc.IsValid() {
+		return TraceContext{}, fmt.Errorf("error while parsing traceparent '%s'; err: %w", traceparent, ErrInvalidTraceParent)
+	}
+
+	return tc, nil
+}
+
+// TraceContext returns the trace context carried by the header; it is not valid if the header has none
+func (h Header) TraceContext() TraceContext {
+	if h.TraceParent == "" {
+		return TraceContext{}
+	}
+
+	tc, err := ParseTraceParent(h.TraceParent)
+	if err != nil {
+		return TraceContext{}
+	}
+
+	return tc
+}
+
+// Traceable is implemented by the messages whose trace context can be set; every message embedding BaseMessage is
+type Traceable interface {
+	SetTraceContext(TraceContext)
+}
+
+// SetTraceContext sets the trace context carried by the header of the message
+func (m *BaseMessage) SetTraceContext(tc TraceContext) {
+	m.CurrentHeader.TraceParent = tc.String()
+}
+
+// InheritTrace copies the trace context of one message to another which has none. Wrappers call it
+// with the wrapped message, and interceptors unwrapping a message call it the other way around, so that
+// every message of a chain belongs to the same trace.
+func InheritTrace(to Message, from Message) {
+	if to == nil || from == nil || to.GetCurrentHeader().TraceParent != "" {
+		return
+	}
+
+	t, ok := to.(Traceable)
+	if !ok {
+		return
+	}
+
+	if tc := from.GetCurrentHeader().TraceContext(); tc.IsValid() {
+		t.SetTraceContext(tc)
+	}
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		putRandom(id[:])
+	}
+
+	return id
+}
+
+func putRandom(p []byte) {
+	for i := range p {
+		p[i] = byte(rand.Uint32())
+	}
+}
+
+func decodeHex(dst []byte, src string) error {
+	if len(src) != 2*len(dst) || strings.ToLower(src) != src {
+		return ErrInvalidTraceParent
+	}
+
+	if _, err := hex.Decode(dst, []byte(src)); err != nil {
+		return ErrInvalidTraceParent
+	}
+
+	return nil
+}
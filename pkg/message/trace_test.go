@@ -0,0 +1,128 @@
+package message
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantErr     bool
+	}{
+		{name: "valid", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "future version with extra fields", traceparent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{name: "version 00 with extra fields", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{name: "forbidden version", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero trace id", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero span id", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", wantErr: true},
+		{name: "upper case", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "short trace id", traceparent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", wantErr: true},
+		{name: "empty", traceparent: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := ParseTraceParent(tt.traceparent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTraceParent) {
+					t.Errorf("ParseTraceParent() error = %v, want %v", err, ErrInvalidTraceParent)
+				}
+				return
+			}
+
+			if tc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID.String() != "00f067aa0ba902b7" || !tc.Sampled() {
+				t.Errorf("ParseTraceParent() = %+v, want the ids of the traceparent", tc)
+			}
+		})
+	}
+}
+
+func TestTraceContext_RoundTrip(t *testing.T) {
+	root := NewTraceContext()
+	if !root.IsValid() || !root.Sampled() {
+		t.Fatalf("NewTraceContext() = %+v, want a valid sampled context", root)
+	}
+
+	child := root.NewChild()
+	if child.TraceID != root.TraceID || child.SpanID == root.SpanID {
+		t.Errorf("NewChild() = %+v, want the trace of %+v with a new span", child, root)
+	}
+
+	parsed, err := ParseTraceParent(child.String())
+	if err != nil {
+		t.Fatalf("ParseTraceParent() error = %v", err)
+	}
+
+	if parsed != child {
+		t.Errorf("ParseTraceParent(String()) = %+v, want %+v", parsed, child)
+	}
+
+	if (TraceContext{}).String() != "" {
+		t.Errorf("String() of an invalid context = %q, want empty", (TraceContext{}).String())
+	}
+}
+
+func TestInheritTrace(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	inner := &testOtherMessage{Count: 1}
+	base, err := NewBaseMessage(NoneProtocol, nil, inner)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	inner.BaseMessage = base
+
+	tc := NewTraceContext()
+
+	outer := &testMessage{Text: "outer"}
+	base, err = NewBaseMessage(testOtherProtocol, inner, outer)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	outer.BaseMessage = base
+	outer.SetTraceContext(tc)
+
+	data, err := Marshal(outer)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	decoded, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	if got := decoded.GetCurrentHeader().TraceContext(); got != tc {
+		t.Fatalf("TraceContext() = %+v, want %+v", got, tc)
+	}
+
+	next, err := decoded.GetNext(registry)
+	if err != nil {
+		t.Fatalf("GetNext() error = %v", err)
+	}
+
+	if next.GetCurrentHeader().TraceContext().IsValid() {
+		t.Fatalf("inner message has a trace context before inheriting it")
+	}
+
+	InheritTrace(next, decoded)
+
+	if got := next.GetCurrentHeader().TraceContext(); got != tc {
+		t.Errorf("TraceContext() after InheritTrace = %+v, want %+v", got, tc)
+	}
+
+	// NOTE: AN EXISTING TRACE CONTEXT IS KEPT
+	other := NewTraceContext()
+	outer.SetTraceContext(other)
+	InheritTrace(next, outer)
+
+	if got := next.GetCurrentHeader().TraceContext(); got != tc {
+		t.Errorf("TraceContext() after second InheritTrace = %+v, want %+v", got, tc)
+	}
+}
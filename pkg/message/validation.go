@@ -0,0 +1,146 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationErrorProtocol identifies the message sent back to the sender of a message that failed validation
+const ValidationErrorProtocol Protocol = "message:validation_error"
+
+// Validator is an optional interface for messages whose fields must satisfy rules beyond
+// what encoding/json enforces. The registry calls Validate right after a message is decoded,
+// so invalid messages never reach the interceptors and processors.
+type Validator interface {
+	// Validate returns an error if the message is not valid. Returning a *ValidationError
+	// allows the offending field to be reported back to the sender.
+	Validate() error
+}
+
+// ValidationError describes why a message was rejected. It wraps ErrValidation.
+type ValidationError struct {
+	Protocol Protocol // Protocol of the rejected message; filled by the registry if empty
+	Field    string   // Field is the JSON name of the offending field; empty if the message as a whole is invalid
+	Reason   string   // Reason is a human-readable description of the violated rule
+}
+
+// NewValidationError creates a validation error for the given field
+func NewValidationError(field string, reason string) *ValidationError {
+	return &ValidationError{
+		Field:  field,
+		Reason: reason,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid message %s: %s", e.Protocol, e.Reason)
+	}
+
+	return fmt.Sprintf("invalid field '%s' in message %s: %s", e.Field, e.Protocol, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// RequireNotEmpty returns a validation error if the value is empty
+func RequireNotEmpty[T ~string](field string, value T) error {
+	if value == "" {
+		return NewValidationError(field, "must not be empty")
+	}
+
+	return nil
+}
+
+// RequirePositive returns a validation error if the value is zero or negative
+func RequirePositive[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64](field string, value T) error {
+	if value <= 0 {
+		return NewValidationError(field, "must be positive")
+	}
+
+	return nil
+}
+
+// RequireNotZero returns a validation error if the value is the zero value of its type
+func RequireNotZero[T comparable](field string, value T) error {
+	var zero T
+	if value == zero {
+		return NewValidationError(field, "must be set")
+	}
+
+	return nil
+}
+
+// validate runs the validation rules of the message, if any. The returned error is always a *ValidationError.
+func validate(protocol Protocol, msg Message) error {
+	v, ok := msg.(Validator)
+	if !ok {
+		return nil
+	}
+
+	err := v.Validate()
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		validationErr = &ValidationError{Reason: err.Error()}
+	}
+
+	if validationErr.Protocol == "" {
+		validationErr.Protocol = protocol
+	}
+
+	return validationErr
+}
+
+// ValidationErrorMessage is sent back to the sender of a message that failed validation.
+// It carries the ValidationError in a typed form so that clients can react to it.
+type ValidationErrorMessage struct {
+	BaseMessage
+	RejectedProtocol Protocol `json:"rejected_protocol"`
+	Field            string   `json:"field,omitempty"`
+	Reason           string   `json:"reason"`
+}
+
+// NewValidationErrorMessage creates the message reporting the given validation error
+func NewValidationErrorMessage(err *ValidationError) (*ValidationErrorMessage, error) {
+	msg := &ValidationErrorMessage{
+		RejectedProtocol: err.Protocol,
+		Field:            err.Field,
+		Reason:           err.Reason,
+	}
+
+	bmsg, e := NewBaseMessage(NoneProtocol, nil, msg)
+	if e != nil {
+		return nil, e
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *ValidationErrorMessage) GetProtocol() Protocol {
+	return ValidationErrorProtocol
+}
+
+// Err converts the message back to a ValidationError
+func (m *ValidationErrorMessage) Err() *ValidationError {
+	return &ValidationError{
+		Protocol: m.RejectedProtocol,
+		Field:    m.Field,
+		Reason:   m.Reason,
+	}
+}
+
+// Registrations returns the messages defined by this package. They are not tied to any
+// middleware and should be registered in every registry used by a socket.
+func Registrations() []Registration {
+	return []Registration{
+		Type[ValidationErrorMessage](ValidationErrorProtocol),
+		Type[ExpiredMessage](ExpiredProtocol),
+		Type[ErrorReport](ErrorReportProtocol),
+	}
+}
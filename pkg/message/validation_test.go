@@ -0,0 +1,113 @@
+package message
+
+import (
+	"errors"
+	"testing"
+)
+
+const testValidatedProtocol Protocol = "test:validated_message"
+
+type testValidatedMessage struct {
+	BaseMessage
+	RoomID   string `json:"room_id"`
+	Interval int64  `json:"interval"`
+}
+
+func (m *testValidatedMessage) GetProtocol() Protocol {
+	return testValidatedProtocol
+}
+
+func (m *testValidatedMessage) Validate() error {
+	if err := RequireNotEmpty("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return RequirePositive("interval", m.Interval)
+}
+
+func TestUnmarshal_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      Payload
+		wantField string
+	}{
+		{
+			name: "valid",
+			data: Payload(`{"protocol":"test:validated_message","room_id":"room","interval":1,"next_protocol":"none"}`),
+		},
+		{
+			name:      "empty room id",
+			data:      Payload(`{"protocol":"test:validated_message","interval":1,"next_protocol":"none"}`),
+			wantField: "room_id",
+		},
+		{
+			name:      "negative interval",
+			data:      Payload(`{"protocol":"test:validated_message","room_id":"room","interval":-1,"next_protocol":"none"}`),
+			wantField: "interval",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewDefaultRegistry()
+			MustRegister(registry, testValidatedProtocol, TypeFactory[testValidatedMessage]())
+
+			msg, err := registry.UnmarshalRaw(tt.data)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("UnmarshalRaw() error = %v", err)
+				}
+				if msg == nil {
+					t.Fatalf("UnmarshalRaw() returned nil message")
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("UnmarshalRaw() error = %v, want %v", err, ErrValidation)
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("UnmarshalRaw() error = %T, want *ValidationError", err)
+			}
+
+			if validationErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", validationErr.Field, tt.wantField)
+			}
+
+			if validationErr.Protocol != testValidatedProtocol {
+				t.Errorf("Protocol = %q, want %q", validationErr.Protocol, testValidatedProtocol)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage_RoundTrip(t *testing.T) {
+	registry := NewDefaultRegistry()
+	MustRegisterAll(registry, Registrations()...)
+
+	reply, err := NewValidationErrorMessage(&ValidationError{Protocol: testValidatedProtocol, Field: "room_id", Reason: "must not be empty"})
+	if err != nil {
+		t.Fatalf("NewValidationErrorMessage() error = %v", err)
+	}
+
+	data, err := Marshal(reply)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	msg, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	m, ok := msg.(*ValidationErrorMessage)
+	if !ok {
+		t.Fatalf("UnmarshalRaw() type = %T, want *ValidationErrorMessage", msg)
+	}
+
+	if got := m.Err(); got.Field != "room_id" || got.Protocol != testValidatedProtocol {
+		t.Errorf("Err() = %+v, want field room_id of %s", got, testValidatedProtocol)
+	}
+}
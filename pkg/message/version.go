@@ -0,0 +1,346 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Migration transforms the serialized payload of a message from one version of its
+// protocol to another. The registry rewrites the version in the header of the returned
+// payload, so a migration only needs to reshape the message specific fields.
+type Migration func(Payload) (Payload, error)
+
+// parse returns the major and minor numbers of a version of the form "v<major>.<minor>"
+func (v Version) parse() (major int, minor int, ok bool) {
+	if _, err := fmt.Sscanf(string(v), "v%d.%d", &major, &minor); err != nil {
+		return 0, 0, false
+	}
+
+	// NOTE: Sscanf IGNORES TRAILING INPUT; THE VERSION MUST BE EXACTLY OF THE FORM
+	return major, minor, fmt.Sprintf("v%d.%d", major, minor) == string(v)
+}
+
+// newerThan reports whether the version is well-formed and newer than the other well-formed version
+func (v Version) newerThan(other Version) bool {
+	major, minor, ok := v.parse()
+	if !ok {
+		return false
+	}
+
+	otherMajor, otherMinor, ok := other.parse()
+	if !ok {
+		return false
+	}
+
+	return major > otherMajor || (major == otherMajor && minor > otherMinor)
+}
+
+// supports reports whether messages of the version can be exchanged: the version must be well-formed, not
+// newer than the CurrentVersion, and known to the registry; either the CurrentVersion, the version of a
+// registered factory, or a version some protocol migrates from or to. The protocols without migrations are
+// only wire compatible across the versions the registry knows of.
+// NOTE: THE CALLER MUST HOLD THE LOCK
+func (r *DefaultRegistry) supports(version Version) bool {
+	if version == CurrentVersion {
+		return true
+	}
+
+	if _, _, ok := version.parse(); !ok || version.newerThan(CurrentVersion) {
+		return false
+	}
+
+	for key := range r.factories {
+		if key.version == version {
+			return true
+		}
+	}
+
+	for _, graph := range r.migrations {
+		for from, tos := range graph {
+			if from == version {
+				return true
+			}
+
+			if _, exists := tos[version]; exists {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Supports reports whether messages of the version can be exchanged with a peer (see supports).
+// This method is thread-safe.
+func (r *DefaultRegistry) Supports(version Version) bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.supports(version)
+}
+
+func (r *DefaultRegistry) checkVersion(protocol Protocol, version Version) error {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	if !r.supports(version) {
+		return fmt.Errorf("error while checking version %s of %s; err: %w", version, protocol, ErrUnsupportedVersion)
+	}
+
+	return nil
+}
+
+// migrationStep is a single hop on the path between two versions of a protocol
+type migrationStep struct {
+	to        Version
+	migration Migration
+}
+
+// RegisterMigration adds a migration of the protocol from one version to another.
+// Upgrades (older to CurrentVersion) and downgrades (CurrentVersion to older) are both
+// registered using this method; multi-hop paths are resolved automatically.
+// It returns an error if a migration between the same versions is already registered.
+// This method is thread-safe.
+func (r *DefaultRegistry) RegisterMigration(protocol Protocol, from Version, to Version, migration Migration) error {
+	if migration == nil {
+		return fmt.Errorf("error while registering migration of %s from %s to %s; err: nil migration", protocol, from, to)
+	}
+
+	if from == to {
+		return fmt.Errorf("error while registering migration of %s from %s to %s; err: same version", protocol, from, to)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, exists := r.migrations[protocol]; !exists {
+		r.migrations[protocol] = make(map[Version]map[Version]Migration)
+	}
+
+	if _, exists := r.migrations[protocol][from]; !exists {
+		r.migrations[protocol][from] = make(map[Version]Migration)
+	}
+
+	if _, exists := r.migrations[protocol][from][to]; exists {
+		return fmt.Errorf("migration of %s from %s to %s is already registered", protocol, from, to)
+	}
+
+	r.migrations[protocol][from][to] = migration
+	return nil
+}
+
+// Downgrade serializes the message for a peer that negotiated the given version.
+// Nested messages (in "next") are downgraded as well, innermost first. Opaque nested
+// payloads, for example encrypted ones, are left untouched.
+// Protocols without any registered migration are assumed to be wire compatible across
+// the versions known to the registry and only get their header version rewritten. It
+// returns ErrUnsupportedVersion if the version is newer than the CurrentVersion or unknown
+// to the registry, and ErrNoMigrationPath if migrations exist for a protocol but none of
+// them lead to the requested version.
+func (r *DefaultRegistry) Downgrade(msg Message, version Version) (Payload, error) {
+	if err := r.checkVersion(msg.GetProtocol(), version); err != nil {
+		return nil, err
+	}
+
+	data, err := Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.downgrade(msg.GetProtocol(), data, version)
+}
+
+func (r *DefaultRegistry) downgrade(protocol Protocol, data Payload, target Version) (Payload, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var nextProtocol Protocol
+	if raw, exists := fields["next_protocol"]; exists {
+		if err := json.Unmarshal(raw, &nextProtocol); err != nil {
+			return nil, err
+		}
+	}
+
+	if next := fields["next"]; nextProtocol != "" && nextProtocol != NoneProtocol && len(next) > 0 && next[0] == '{' {
+		nested, err := r.downgrade(nextProtocol, Payload(next), target)
+		if err != nil {
+			return nil, fmt.Errorf("error while downgrading nested message %s; err: %w", nextProtocol, err)
+		}
+
+		fields["next"] = json.RawMessage(nested)
+	}
+
+	from, err := headerVersion(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == target {
+		return data, nil
+	}
+
+	steps, migrated := r.path(protocol, from, target)
+	if steps == nil {
+		if migrated {
+			return nil, fmt.Errorf("error while downgrading %s from %s to %s; err: %w", protocol, from, target, ErrNoMigrationPath)
+		}
+
+		return withVersion(data, target)
+	}
+
+	return migrate(data, steps)
+}
+
+// upgrade brings the payload to a version the registry can decode. The returned version is
+// the version of the factory to be used: CurrentVersion if a migration path exists or if the
+// protocol has no migrations at all (wire compatible); otherwise the version itself, if a
+// factory was registered for it using RegisterVersion. Versions newer than the CurrentVersion,
+// or unknown to the registry, are rejected with ErrUnsupportedVersion.
+func (r *DefaultRegistry) upgrade(protocol Protocol, version Version, data Payload) (Version, Payload, error) {
+	if version == CurrentVersion {
+		return version, data, nil
+	}
+
+	if err := r.checkVersion(protocol, version); err != nil {
+		return "", nil, err
+	}
+
+	steps, migrated := r.path(protocol, version, CurrentVersion)
+	if steps != nil {
+		upgraded, err := migrate(data, steps)
+		if err != nil {
+			return "", nil, fmt.Errorf("error while upgrading %s from %s to %s; err: %w", protocol, version, CurrentVersion, err)
+		}
+
+		return CurrentVersion, upgraded, nil
+	}
+
+	r.mux.RLock()
+	_, legacy := r.factories[versionedProtocol{protocol: protocol, version: version}]
+	r.mux.RUnlock()
+
+	if legacy {
+		return version, data, nil
+	}
+
+	if !migrated {
+		return CurrentVersion, data, nil
+	}
+
+	return "", nil, fmt.Errorf("error while upgrading %s from %s to %s; err: %w", protocol, version, CurrentVersion, ErrUnsupportedVersion)
+}
+
+// path finds the shortest chain of migrations between two versions of the protocol.
+// steps is nil if no path exists; migrated reports if the protocol has any migration.
+func (r *DefaultRegistry) path(protocol Protocol, from Version, to Version) (steps []migrationStep, migrated bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	graph := r.migrations[protocol]
+	if len(graph) == 0 {
+		return nil, false
+	}
+
+	if from == to {
+		return []migrationStep{}, true
+	}
+
+	// NOTE: BREADTH FIRST SEARCH; THE NUMBER OF VERSIONS IS EXPECTED TO BE SMALL
+	previous := map[Version]Version{from: from}
+	queue := []Version{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for next := range graph[current] {
+			if _, visited := previous[next]; visited {
+				continue
+			}
+
+			previous[next] = current
+			if next != to {
+				queue = append(queue, next)
+				continue
+			}
+
+			for version := to; version != from; version = previous[version] {
+				steps = append([]migrationStep{{to: version, migration: graph[previous[version]][version]}}, steps...)
+			}
+
+			return steps, true
+		}
+	}
+
+	return nil, true
+}
+
+func migrate(data Payload, steps []migrationStep) (Payload, error) {
+	for _, step := range steps {
+		migrated, err := step.migration(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err = withVersion(migrated, step.to); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func headerVersion(fields map[string]json.RawMessage) (Version, error) {
+	raw, exists := fields["header"]
+	if !exists {
+		return CurrentVersion, nil
+	}
+
+	var header struct {
+		Version Version `json:"version"`
+	}
+
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return "", err
+	}
+
+	if header.Version == "" {
+		return CurrentVersion, nil
+	}
+
+	return header.Version, nil
+}
+
+// withVersion rewrites the version in the header of the serialized message
+func withVersion(data Payload, version Version) (Payload, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	header := make(map[string]json.RawMessage)
+	if raw, exists := fields["header"]; exists {
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return nil, err
+		}
+	}
+
+	rawVersion, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	header["version"] = rawVersion
+
+	if fields["header"], err = json.Marshal(header); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
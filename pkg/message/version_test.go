@@ -0,0 +1,266 @@
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testVersion0 Version = "v0.9"
+
+// renameField returns a migration renaming a top-level field of the serialized message
+func renameField(from, to string) Migration {
+	return func(data Payload) (Payload, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		if value, exists := fields[from]; exists {
+			fields[to] = value
+			delete(fields, from)
+		}
+
+		return json.Marshal(fields)
+	}
+}
+
+func newVersionedTestRegistry(t *testing.T) *DefaultRegistry {
+	t.Helper()
+
+	registry := newTestRegistry(t)
+
+	// NOTE: IN v0.9 THE "text" FIELD OF testMessage WAS CALLED "body"
+	if err := registry.RegisterMigration(testProtocol, testVersion0, CurrentVersion, renameField("body", "text")); err != nil {
+		t.Fatalf("RegisterMigration() error = %v", err)
+	}
+
+	if err := registry.RegisterMigration(testProtocol, CurrentVersion, testVersion0, renameField("text", "body")); err != nil {
+		t.Fatalf("RegisterMigration() error = %v", err)
+	}
+
+	return registry
+}
+
+func decodeFields(t *testing.T, data Payload) map[string]json.RawMessage {
+	t.Helper()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	return fields
+}
+
+func TestUnmarshal_UpgradesOlderVersion(t *testing.T) {
+	registry := newVersionedTestRegistry(t)
+
+	msg, err := registry.UnmarshalRaw(Payload(`{"protocol":"test:message","header":{"version":"v0.9"},"body":"hello","next_protocol":"none"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	m, ok := msg.(*testMessage)
+	if !ok {
+		t.Fatalf("UnmarshalRaw() type = %T, want *testMessage", msg)
+	}
+
+	if m.Text != "hello" {
+		t.Errorf("Text = %q, want %q", m.Text, "hello")
+	}
+
+	if m.GetCurrentHeader().Version != CurrentVersion {
+		t.Errorf("Version = %s, want %s", m.GetCurrentHeader().Version, CurrentVersion)
+	}
+}
+
+func TestUnmarshal_Versions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    Payload
+		wantErr error
+	}{
+		{
+			name: "current version",
+			data: Payload(`{"protocol":"test:message","header":{"version":"v1.0"},"text":"hello","next_protocol":"none"}`),
+		},
+		{
+			name: "missing version is current",
+			data: Payload(`{"protocol":"test:message","text":"hello","next_protocol":"none"}`),
+		},
+		{
+			name:    "unknown version with migrations",
+			data:    Payload(`{"protocol":"test:message","header":{"version":"v0.1"},"text":"hello","next_protocol":"none"}`),
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name: "known version without migrations is wire compatible",
+			data: Payload(`{"protocol":"test:other_message","header":{"version":"v0.9"},"count":1,"next_protocol":"none"}`),
+		},
+		{
+			name:    "unknown version without migrations",
+			data:    Payload(`{"protocol":"test:other_message","header":{"version":"v0.1"},"count":1,"next_protocol":"none"}`),
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "future version",
+			data:    Payload(`{"protocol":"test:message","header":{"version":"v2.0"},"text":"hello","next_protocol":"none"}`),
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "future version without migrations",
+			data:    Payload(`{"protocol":"test:other_message","header":{"version":"v1.1"},"count":1,"next_protocol":"none"}`),
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "malformed version",
+			data:    Payload(`{"protocol":"test:other_message","header":{"version":"v0.9-beta"},"count":1,"next_protocol":"none"}`),
+			wantErr: ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newVersionedTestRegistry(t)
+
+			_, err := registry.UnmarshalRaw(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UnmarshalRaw() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type testLegacyMessage struct {
+	BaseMessage
+	Legacy string `json:"legacy"`
+}
+
+func (m *testLegacyMessage) GetProtocol() Protocol {
+	return testOtherProtocol
+}
+
+func TestUnmarshal_LegacyFactory(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	if err := registry.RegisterVersion(testOtherProtocol, testVersion0, TypeFactory[testLegacyMessage]()); err != nil {
+		t.Fatalf("RegisterVersion() error = %v", err)
+	}
+
+	msg, err := registry.UnmarshalRaw(Payload(`{"protocol":"test:other_message","header":{"version":"v0.9"},"legacy":"old","next_protocol":"none"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	if m, ok := msg.(*testLegacyMessage); !ok || m.Legacy != "old" {
+		t.Errorf("UnmarshalRaw() = %+v, want *testLegacyMessage with Legacy old", msg)
+	}
+
+	if protocols := registry.Protocols(); len(protocols) != 2 {
+		t.Errorf("Protocols() = %v, want only the current versions", protocols)
+	}
+}
+
+func TestDowngrade_Nested(t *testing.T) {
+	registry := newVersionedTestRegistry(t)
+
+	inner := &testMessage{Text: "inner"}
+	base, err := NewBaseMessage(NoneProtocol, nil, inner)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	inner.BaseMessage = base
+
+	outer := &testOtherMessage{Count: 1}
+	base, err = NewBaseMessage(testProtocol, inner, outer)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	outer.BaseMessage = base
+
+	data, err := registry.Downgrade(outer, testVersion0)
+	if err != nil {
+		t.Fatalf("Downgrade() error = %v", err)
+	}
+
+	fields := decodeFields(t, data)
+	if version, _ := headerVersion(fields); version != testVersion0 {
+		t.Errorf("outer version = %s, want %s", version, testVersion0)
+	}
+
+	nested := decodeFields(t, Payload(fields["next"]))
+	if _, exists := nested["body"]; !exists {
+		t.Errorf("nested message = %s, want the v0.9 body field", fields["next"])
+	}
+
+	if version, _ := headerVersion(nested); version != testVersion0 {
+		t.Errorf("nested version = %s, want %s", version, testVersion0)
+	}
+
+	// NOTE: A v0.9 PEER SENDING THE SAME DATA BACK MUST BE UNDERSTOOD
+	msg, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		t.Fatalf("UnmarshalRaw() error = %v", err)
+	}
+
+	next, err := msg.GetNext(registry)
+	if err != nil {
+		t.Fatalf("GetNext() error = %v", err)
+	}
+
+	if m, ok := next.(*testMessage); !ok || m.Text != "inner" {
+		t.Errorf("GetNext() = %+v, want *testMessage with Text inner", next)
+	}
+}
+
+func TestDowngrade_MultiHop(t *testing.T) {
+	registry := newVersionedTestRegistry(t)
+
+	const testVersion00 Version = "v0.1"
+	if err := registry.RegisterMigration(testProtocol, testVersion0, testVersion00, renameField("body", "content")); err != nil {
+		t.Fatalf("RegisterMigration() error = %v", err)
+	}
+
+	msg := &testMessage{Text: "hello"}
+	base, err := NewBaseMessage(NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = base
+
+	data, err := registry.Downgrade(msg, testVersion00)
+	if err != nil {
+		t.Fatalf("Downgrade() error = %v", err)
+	}
+
+	fields := decodeFields(t, data)
+	if string(fields["content"]) != `"hello"` {
+		t.Errorf("content = %s, want \"hello\"", fields["content"])
+	}
+
+	if _, err := registry.Downgrade(msg, "v0.0"); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Downgrade() error = %v, want %v", err, ErrUnsupportedVersion)
+	}
+
+	if _, err := registry.Downgrade(msg, "v2.0"); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Downgrade() error = %v, want %v", err, ErrUnsupportedVersion)
+	}
+
+	// NOTE: v0.0 IS KNOWN ONCE ANOTHER PROTOCOL MIGRATES TO IT, BUT test:message STILL CANNOT REACH IT
+	if err := registry.RegisterMigration(testOtherProtocol, CurrentVersion, "v0.0", renameField("count", "total")); err != nil {
+		t.Fatalf("RegisterMigration() error = %v", err)
+	}
+
+	if _, err := registry.Downgrade(msg, "v0.0"); !errors.Is(err, ErrNoMigrationPath) {
+		t.Errorf("Downgrade() error = %v, want %v", err, ErrNoMigrationPath)
+	}
+}
+
+func TestRegisterMigration_Duplicate(t *testing.T) {
+	registry := newVersionedTestRegistry(t)
+
+	if err := registry.RegisterMigration(testProtocol, testVersion0, CurrentVersion, renameField("body", "text")); err == nil {
+		t.Errorf("RegisterMigration() on duplicate migration expected error, got nil")
+	}
+}
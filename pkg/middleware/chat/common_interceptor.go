@@ -2,6 +2,9 @@ package chat
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
@@ -13,6 +16,9 @@ type commonInterceptor struct {
 	readProcessMessages  message.Registry
 	writeProcessMessages message.Registry
 	states               *state.Manager
+	reportExpired        bool
+	expired              atomic.Uint64
+	leaveNotification    LeaveNotificationFactory
 }
 
 func (i *commonInterceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
@@ -46,9 +52,16 @@ func (i *commonInterceptor) InterceptSocketWriter(writer interceptor.Writer) int
 			return writer.Write(ctx, connection, msg)
 		}
 
-		if err := m.WriteProcess(ctx, i, connection); err != nil {
-			return writer.Write(ctx, connection, next)
+		pctx, span := interceptor.StartSpan(ctx, interceptor.StageProcess, m)
+		if err := m.WriteProcess(pctx, i, connection); err != nil {
+			span.RecordError(err)
+			span.End()
+			// NOTE: WHAT HAPPENS TO THE MESSAGE IS DECIDED BY THE ERROR POLICY OF THE CHAIN (SEE interceptor.ErrorPolicy)
+			return fmt.Errorf("error while write processing %s; err: %w", msg.GetProtocol(), err)
 		}
+		span.End()
+
+		message.InheritTrace(next, m)
 
 		return writer.Write(ctx, connection, next)
 	})
@@ -56,43 +69,139 @@ func (i *commonInterceptor) InterceptSocketWriter(writer interceptor.Writer) int
 
 func (i *commonInterceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
 	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
-		msg, err := reader.Read(ctx, connection)
-		if err != nil {
-			return msg, err
-		}
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil {
+				return msg, err
+			}
+
+			if msg == nil {
+				return nil, nil
+			}
+
+			if !i.readProcessMessages.Check(msg.GetProtocol()) {
+				return msg, nil
+			}
+
+			m, ok := msg.(interceptor.Message)
+			if !ok {
+				return msg, interceptor.ErrInterfaceMisMatch
+			}
+
+			next, err := m.GetNext(i.GetMessageRegistry())
+			if err != nil {
+				var validationErr *message.ValidationError
+				if errors.As(err, &validationErr) {
+					i.replyValidationError(ctx, connection, validationErr)
+					return nil, err
+				}
+				return msg, nil
+			}
+
+			pctx, span := interceptor.StartSpan(ctx, interceptor.StageProcess, m)
+			if err := m.ReadProcess(pctx, i, connection); err != nil {
+				span.RecordError(err)
+				span.End()
+
+				if errors.Is(err, message.ErrExpired) {
+					// NOTE: ALREADY DROPPED (AND REPORTED) BY THE PROCESS; KEEP READING
+					continue
+				}
+
+				// NOTE: WHAT HAPPENS TO THE MESSAGE IS DECIDED BY THE ERROR POLICY OF THE CHAIN (SEE interceptor.ErrorPolicy)
+				return nil, fmt.Errorf("error while read processing %s; err: %w", msg.GetProtocol(), err)
+			}
+			span.End()
+
+			message.InheritTrace(next, m)
 
-		if msg == nil {
-			return nil, nil
+			return next, nil
 		}
+	})
+}
 
-		if !i.readProcessMessages.Check(msg.GetProtocol()) {
-			return msg, nil
-		}
+// replyValidationError reports the validation error of a nested message back to its sender
+func (i *commonInterceptor) replyValidationError(ctx context.Context, connection interceptor.Connection, validationErr *message.ValidationError) {
+	s, err := i.states.GetState(connection)
+	if err != nil {
+		fmt.Println("error while replying validation error; err:", err.Error())
+		return
+	}
 
-		m, ok := msg.(interceptor.Message)
-		if !ok {
-			return msg, interceptor.ErrInterfaceMisMatch
-		}
+	reply, err := message.NewValidationErrorMessage(validationErr)
+	if err != nil {
+		fmt.Println("error while replying validation error; err:", err.Error())
+		return
+	}
 
-		next, err := m.GetNext(i.GetMessageRegistry())
-		if err != nil {
-			return msg, nil
-		}
+	if err := s.Write(ctx, reply); err != nil {
+		fmt.Println("error while replying validation error; err:", err.Error())
+	}
+}
 
-		if err := m.ReadProcess(ctx, i, connection); err != nil {
-			return next, nil
-		}
+// DropExpired counts the expired message and, if enabled (see WithExpiryReports), reports it back to the sender
+func (i *commonInterceptor) DropExpired(ctx context.Context, connection interceptor.Connection, msg message.Message, stage message.ExpiryStage) {
+	i.expired.Add(1)
 
-		return next, nil
-	})
+	if !i.reportExpired {
+		return
+	}
+
+	s, err := i.states.GetState(connection)
+	if err != nil {
+		fmt.Println("error while reporting expired message; err:", err.Error())
+		return
+	}
+
+	reply, err := message.NewExpiredMessage(msg, stage)
+	if err != nil {
+		fmt.Println("error while reporting expired message; err:", err.Error())
+		return
+	}
+
+	if err := s.Write(ctx, reply); err != nil {
+		fmt.Println("error while reporting expired message; err:", err.Error())
+	}
+}
+
+// Expired returns the number of messages dropped by the interceptor as they expired
+func (i *commonInterceptor) Expired() uint64 {
+	return i.expired.Load()
 }
 
+// UnBindSocketConnection removes the state of the connection and cancels its context
 func (i *commonInterceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	s, err := i.states.GetState(connection)
+	if err != nil {
+		return
+	}
+
+	if err := i.states.RemoveState(connection); err != nil {
+		fmt.Println("error while removing state; err:", err.Error())
+	}
 
+	s.Close()
 }
 
 func (i *commonInterceptor) Close() error {
-	// TODO: FIGURE OUR GOOD CLOSE STRATEGY
+	return i.unBindAll(i.UnBindSocketConnection)
+}
+
+// unBindAll unbinds every bound connection with the given unbind; the connections are collected first as
+// ForEach holds the read lock of the state manager
+func (i *commonInterceptor) unBindAll(unBind func(interceptor.Connection)) error {
+	connections := make([]interceptor.Connection, 0)
+	if err := i.states.ForEach(func(connection interceptor.Connection, _ *state.State) error {
+		connections = append(connections, connection)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, connection := range connections {
+		unBind(connection)
+	}
+
 	return nil
 }
 
@@ -13,4 +13,5 @@ var (
 	ErrClientIsAlreadyParticipant = interceptor.NewError("client is already a participant in the room")
 	ErrClientNotAParticipant      = interceptor.NewError("client is not a participant in the room at the moment")
 	ErrWrongRoom                  = interceptor.NewError("operation not permitted as room id did not match")
+	ErrRoomHistoryDisabled        = interceptor.NewError("room does not keep a history")
 )
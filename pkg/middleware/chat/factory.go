@@ -5,9 +5,11 @@ import (
 	"fmt"
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/processors"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/state"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
 )
 
 type Option = func(i interceptor.Interceptor) error
@@ -22,6 +24,19 @@ func NewInterceptorFactory(options ...Option) *InterceptorFactory {
 	}
 }
 
+// InterceptorName names the chat interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Chat
+
+// Descriptor places chat before encrypt and fragment so that the room messages are encrypted and
+// fragmented on their way out.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Before:   []string{names.Encrypt, names.Fragment},
+		Optional: []string{names.Encrypt, names.Fragment},
+	}
+}
+
 func WithServerInterceptor(i interceptor.Interceptor) error {
 	c, ok := i.(*commonInterceptor)
 	if !ok {
@@ -55,15 +70,75 @@ func WithClientInterceptor(i interceptor.Interceptor) error {
 	return nil
 }
 
+// WithExpiryReports makes the interceptor report the messages which expired before they were
+// forwarded back to their senders (see message.ExpiredMessage).
+func WithExpiryReports(i interceptor.Interceptor) error {
+	c, ok := i.(*commonInterceptor)
+	if !ok {
+		return fmt.Errorf("can only enable expiry reports on common chat interceptor; err: %s", interceptor.ErrInterfaceMisMatch.Error())
+	}
+
+	c.reportExpired = true
+	return nil
+}
+
+// LeaveNotificationFactory creates the message sent to the remaining participants of a room when a client
+// is removed from it on disconnect.
+type LeaveNotificationFactory = func(types.RoomID, interceptor.ClientID) func() (message.Message, error)
+
+// defaultLeaveNotification is the leave notification of the interceptors created without
+// WithLeaveNotification or WithoutLeaveNotification; the messages package, which defines the notification
+// message, sets it (see SetDefaultLeaveNotification)
+var defaultLeaveNotification LeaveNotificationFactory
+
+// SetDefaultLeaveNotification sets the leave notification of the interceptors created afterwards without
+// WithLeaveNotification or WithoutLeaveNotification. It is not thread-safe; the messages package calls it
+// on initialisation, so that the remaining participants are notified by default.
+func SetDefaultLeaveNotification(factory LeaveNotificationFactory) {
+	defaultLeaveNotification = factory
+}
+
+// WithLeaveNotification makes the server interceptor notify the remaining participants of the rooms that a
+// disconnected client was removed from with the given message, instead of the default one.
+func WithLeaveNotification(factory LeaveNotificationFactory) Option {
+	return func(i interceptor.Interceptor) error {
+		c, ok := i.(*commonInterceptor)
+		if !ok {
+			return fmt.Errorf("can only set leave notification on common chat interceptor; err: %s", interceptor.ErrInterfaceMisMatch.Error())
+		}
+
+		if factory == nil {
+			return fmt.Errorf("leave notification factory must not be nil")
+		}
+
+		c.leaveNotification = factory
+		return nil
+	}
+}
+
+// WithoutLeaveNotification makes the server interceptor remove disconnected clients from their rooms
+// without notifying the remaining participants.
+func WithoutLeaveNotification(i interceptor.Interceptor) error {
+	c, ok := i.(*commonInterceptor)
+	if !ok {
+		return fmt.Errorf("can only unset leave notification on common chat interceptor; err: %s", interceptor.ErrInterfaceMisMatch.Error())
+	}
+
+	c.leaveNotification = nil
+	return nil
+}
+
 func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
 	i := &commonInterceptor{
 		NoOpInterceptor:      interceptor.NewNoOpInterceptor(ctx, id, registry),
 		readProcessMessages:  message.NewDefaultRegistry(),
 		writeProcessMessages: message.NewDefaultRegistry(),
 		states:               state.NewManager(),
+		leaveNotification:    defaultLeaveNotification,
 	}
 
-	// TODO: add common messages to the registry
+	// NOTE: THE CHAT MESSAGES ARE NOT REGISTERED HERE AS THE REGISTRY IS SHARED BETWEEN SOCKETS;
+	// NOTE: USE messages.RegisterMessages ON THE API'S MESSAGE REGISTRY INSTEAD
 
 	for _, option := range f.options {
 		if err := option(i); err != nil {
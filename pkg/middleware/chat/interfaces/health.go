@@ -32,6 +32,10 @@ type CanRemoveHealthSnapshotStreamer interface {
 	RemoveHealthSnapshotStreamer(types.RoomID, interceptor.State) error
 }
 
+type CanRemoveAllHealthSnapshotStreamers interface {
+	RemoveAllHealthSnapshotStreamers(interceptor.State) error
+}
+
 type CanCreateHealth interface {
 	CreateHealth(types.RoomID, []interceptor.ClientID, time.Duration) (*health.Health, error)
 }
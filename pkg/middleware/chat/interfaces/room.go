@@ -17,6 +17,10 @@ type CanRemove interface {
 	Remove(types.RoomID, interceptor.State) error
 }
 
+type CanLeaveAll interface {
+	LeaveAll(interceptor.State) ([]types.RoomID, error)
+}
+
 type CanGetRoom interface {
 	GetRoom(id types.RoomID) (*room.Room, error)
 }
@@ -26,7 +30,7 @@ type CanWriteRoomMessage interface {
 }
 
 type CanCreateRoom interface {
-	CreateRoom(types.RoomID, []interceptor.ClientID, time.Duration) (*room.Room, error)
+	CreateRoom(types.RoomID, []interceptor.ClientID, time.Duration, ...room.Option) (*room.Room, error)
 }
 
 type CanDeleteRoom interface {
@@ -0,0 +1,56 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+const FailFetchRoomHistoryProtocol message.Protocol = "room:fail_fetch_room_history"
+
+type FailFetchRoomHistory struct {
+	interceptor.BaseMessage
+	RoomID types.RoomID `json:"room_id"`
+	Error  string       `json:"error"`
+}
+
+func NewFailFetchRoomHistoryMessage(id types.RoomID, err error) (*FailFetchRoomHistory, error) {
+	msg := &FailFetchRoomHistory{
+		RoomID: id,
+		Error:  err.Error(),
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.BaseMessage = bmsg
+	return msg, nil
+}
+
+func NewFailFetchRoomHistoryMessageFactory(id types.RoomID, err error) func() (message.Message, error) {
+	return func() (message.Message, error) {
+		return NewFailFetchRoomHistoryMessage(id, err)
+	}
+}
+
+func (m *FailFetchRoomHistory) GetProtocol() message.Protocol {
+	return FailFetchRoomHistoryProtocol
+}
+
+func (m *FailFetchRoomHistory) ReadProcess(_ context.Context, _i interceptor.Interceptor, _ interceptor.Connection) error {
+	_, ok := _i.(*chat.ClientInterceptor)
+	if !ok {
+		return interceptor.ErrInvalidInterceptor
+	}
+
+	fmt.Println("failed to fetch room history:", m.Error)
+
+	// NOTE: INTENTIONALLY EMPTY
+	return nil
+}
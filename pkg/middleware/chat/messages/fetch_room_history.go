@@ -0,0 +1,92 @@
+package messages
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/errors"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/process"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+const FetchRoomHistoryProtocol message.Protocol = "room:fetch_room_history"
+
+// FetchRoomHistory is the message sent by a participant to the server to fetch a page of the history of the
+// room (see room.HistoryQuery). The server answers with a RoomHistory message, or a FailFetchRoomHistory
+// message if the room keeps no history or the client is not a participant.
+type FetchRoomHistory struct {
+	interceptor.BaseMessage
+	RoomID types.RoomID `json:"room_id"`
+	room.HistoryQuery
+}
+
+func NewFetchRoomHistoryMessage(id types.RoomID, query room.HistoryQuery) (*FetchRoomHistory, error) {
+	msg := &FetchRoomHistory{
+		RoomID:       id,
+		HistoryQuery: query,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.BaseMessage = bmsg
+	return msg, nil
+}
+
+func (m *FetchRoomHistory) GetProtocol() message.Protocol {
+	return FetchRoomHistoryProtocol
+}
+
+func (m *FetchRoomHistory) ReadProcess(ctx context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
+	i, ok := _i.(*chat.ServerInterceptor)
+	if !ok {
+		return interceptor.ErrInterfaceMisMatch
+	}
+
+	s, err := i.GetState(connection)
+	if err != nil {
+		return err
+	}
+
+	return sendRoomHistory(ctx, i, s, m.RoomID, m.HistoryQuery)
+}
+
+// sendRoomHistory sends the page of the history of the room selected by the query to the participant
+func sendRoomHistory(ctx context.Context, i *chat.ServerInterceptor, s interceptor.State, id types.RoomID, query room.HistoryQuery) error {
+	page, err := getRoomHistory(i, s, id, query)
+	if err != nil {
+		_ = process.NewSendMessage(NewFailFetchRoomHistoryMessageFactory(id, err)).Process(ctx, nil, s)
+		return err
+	}
+
+	return process.NewSendMessage(NewRoomHistoryMessageFactory(id, page)).Process(ctx, nil, s)
+}
+
+func getRoomHistory(i *chat.ServerInterceptor, s interceptor.State, id types.RoomID, query room.HistoryQuery) (room.HistoryPage, error) {
+	clientID, err := s.GetClientID()
+	if err != nil {
+		return room.HistoryPage{}, err
+	}
+
+	r, ok := i.Rooms.(interfaces.CanGetRoom)
+	if !ok {
+		return room.HistoryPage{}, interceptor.ErrInterfaceMisMatch
+	}
+
+	rm, err := r.GetRoom(id)
+	if err != nil {
+		return room.HistoryPage{}, err
+	}
+
+	if !rm.IsParticipant(clientID) {
+		return room.HistoryPage{}, errors.ErrClientNotAParticipant
+	}
+
+	return rm.History(query, clientID)
+}
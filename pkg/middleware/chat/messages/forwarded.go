@@ -12,12 +12,18 @@ const ForwardedMessageProtocol message.Protocol = "room:forwarded_message"
 
 type ForwardedMessage struct {
 	interceptor.BaseMessage
+	// Seq is the sequence number of the message in the history of the room; zero if it is not recorded
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 func (m *ForwardedMessage) GetProtocol() message.Protocol {
 	return ForwardedMessageProtocol
 }
 
+func (m *ForwardedMessage) SetSeq(seq uint64) {
+	m.Seq = seq
+}
+
 func newForwardedMessage(forward *ToForward) (*ForwardedMessage, error) {
 	msg := &ForwardedMessage{}
 	bmsg, err := interceptor.NewBaseMessage(forward.GetNextProtocol(), forward.NextPayload, msg)
@@ -26,6 +32,9 @@ func newForwardedMessage(forward *ToForward) (*ForwardedMessage, error) {
 	}
 
 	msg.BaseMessage = bmsg
+	msg.SetExpiry(forward.GetCurrentHeader().Expiry)
+	message.InheritTrace(msg, forward)
+	interceptor.InheritPriority(msg, forward)
 
 	return msg, nil
 }
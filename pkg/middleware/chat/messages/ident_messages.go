@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat"
@@ -22,6 +23,10 @@ func (m *Ident) GetProtocol() message.Protocol {
 	return IdentProtocol
 }
 
+func (m *Ident) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
 func (m *Ident) ReadProcess(ctx context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	s, ok := _i.(*chat.ClientInterceptor)
 	if !ok {
@@ -37,6 +42,8 @@ func (m *Ident) ReadProcess(ctx context.Context, _i interceptor.Interceptor, con
 		return fmt.Errorf("error while processing 'Ident' message; err: %s", err.Error())
 	}
 
+	events.Publish(s.Ctx(), events.Event{Kind: events.Identified, Source: s.ID(), Connection: connection, Client: interceptor.ClientID(m.CurrentHeader.Sender)})
+
 	if err := ss.Write(ctx, &IdentResponse{}); err != nil {
 		return fmt.Errorf("error while processing 'Ident' message; err: %s", err.Error())
 	}
@@ -54,6 +61,10 @@ func (m *IdentResponse) GetProtocol() message.Protocol {
 	return IdentResponseProtocol
 }
 
+func (m *IdentResponse) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
 func (m *IdentResponse) ReadProcess(_i interceptor.Interceptor, connection interceptor.Connection) error {
 	s, ok := _i.(*chat.ServerInterceptor)
 	if !ok {
@@ -69,5 +80,7 @@ func (m *IdentResponse) ReadProcess(_i interceptor.Interceptor, connection inter
 		return fmt.Errorf("error while processing 'Ident' message; err: %s", err.Error())
 	}
 
+	events.Publish(s.Ctx(), events.Event{Kind: events.Identified, Source: s.ID(), Connection: connection, Client: interceptor.ClientID(m.CurrentHeader.Sender)})
+
 	return nil
 }
@@ -7,13 +7,20 @@ import (
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/process"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
 )
 
 const JoinRoomProtocol message.Protocol = "room:join_room"
 
+// JoinRoom is the message sent by the client to the server to join a room. If Replay is set, the client is
+// sent the page of the history of the room it selects (see room.HistoryQuery) once it joins; for example,
+// the last 50 messages, or every message since the last one the client saw before reconnecting.
+// NOTE: A MESSAGE BROADCAST WHILE THE CLIENT JOINS MAY BE BOTH REPLAYED AND DELIVERED; THE SEQ OF THE
+// NOTE: FORWARDED MESSAGES TELLS THE DUPLICATES
 type JoinRoom struct {
 	interceptor.BaseMessage
 	process.AddToRoom
+	Replay *room.HistoryQuery `json:"replay,omitempty"`
 }
 
 func (m *JoinRoom) GetProtocol() message.Protocol {
@@ -36,5 +43,10 @@ func (m *JoinRoom) ReadProcess(ctx context.Context, _i interceptor.Interceptor,
 		return err
 	}
 
-	return process.NewSendMessageToAllParticipantsInRoom(m.RoomID, NewSuccessJoinRoomMessageFactory(m.RoomID, interceptor.ClientID(m.GetCurrentHeader().Sender))).Process(ctx, nil, s)
+	if m.Replay != nil {
+		// NOTE: THE CLIENT HAS JOINED; A FAILED REPLAY IS ANSWERED WITH ITS OWN FAILURE AND DOES NOT FAIL THE JOIN
+		_ = sendRoomHistory(ctx, i, s, m.RoomID, *m.Replay)
+	}
+
+	return process.NewSendMessageToAllParticipantsInRoom(m.RoomID, NewSuccessJoinRoomMessageFactory(m.RoomID, interceptor.ClientID(m.GetCurrentHeader().Sender))).Process(ctx, i.Rooms, s)
 }
@@ -0,0 +1,57 @@
+package messages
+
+import (
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Registrations returns the complete set of chat messages (room, health and ident topics)
+// paired with their protocols. The set is shared by the client and the server as both
+// need to unmarshal the messages sent by the other side.
+func Registrations() []message.Registration {
+	return []message.Registration{
+		// IDENT
+		message.Type[Ident](IdentProtocol),
+		message.Type[IdentResponse](IdentResponseProtocol),
+
+		// ROOM
+		message.Type[CreateRoom](CreateRoomProtocol),
+		message.Type[SuccessCreateRoom](SuccessCreateRoomProtocol),
+		message.Type[FailCreateRoom](FailCreateRoomProtocol),
+		message.Type[DeleteRoom](DeleteRoomProtocol),
+		message.Type[SuccessDeleteRoom](SuccessDeleteRoomProtocol),
+		message.Type[FailDeleteRoom](FailDeleteRoomProtocol),
+		message.Type[JoinRoom](JoinRoomProtocol),
+		message.Type[SuccessJoinRoom](SuccessJoinRoomProtocol),
+		message.Type[FailJoinRoom](FailJoinRoomProtocol),
+		message.Type[LeaveRoom](LeaveRoomProtocol),
+		message.Type[SuccessLeaveRoom](SuccessLeaveRoomProtocol),
+		message.Type[FailLeaveRoom](FailLeaveRoomProtocol),
+		message.Type[ToForward](ForwardMessageProtocol),
+		message.Type[ForwardedMessage](ForwardedMessageProtocol),
+		message.Type[FetchRoomHistory](FetchRoomHistoryProtocol),
+		message.Type[RoomHistory](RoomHistoryProtocol),
+		message.Type[FailFetchRoomHistory](FailFetchRoomHistoryProtocol),
+
+		// HEALTH
+		message.Type[StartHealthTracking](MarkRoomForHealthTrackingProtocol),
+		message.Type[SuccessTrackHealthInRoom](SuccessTrackHealthInRoomProtocol),
+		message.Type[FailStartHealthTracking](FailStartHealthTrackingProtocol),
+		message.Type[StopHealthTracking](UntrackHealthInRoomProtocol),
+		message.Type[SuccessUnmarkRoomForHealthTracking](SuccessUnmarkRoomForHealthTrackingProtocol),
+		message.Type[FailStopHealthTracking](FailStopHealthTrackingProtocol),
+		message.Type[SendHealthStats](RequestHealthProtocol),
+		message.Type[UpdateHealthStat](HealthResponseProtocol),
+		message.Type[StartStreamingHealthSnapshots](GetHealthSnapshotProtocol),
+		message.Type[SuccessStartHealthStreaming](SuccessStartHealthStreamingProtocol),
+		message.Type[FailStartHealthStreaming](FailStartHealthStreamingProtocol),
+		message.Type[UpdateHealthSnapshot](UpdateHealthSnapshotProtocol),
+		message.Type[StopStreamingHealthSnapshot](StopStreamingHealthSnapshotProtocol),
+		message.Type[SuccessStopHealthStreaming](SuccessStopHealthStreamingProtocol),
+		message.Type[FailStopHealthStreaming](FailStopHealthStreamingProtocol),
+	}
+}
+
+// RegisterMessages registers all the chat messages in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
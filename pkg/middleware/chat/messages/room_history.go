@@ -0,0 +1,58 @@
+package messages
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+const RoomHistoryProtocol message.Protocol = "room:room_history"
+
+// RoomHistory is the message sent by the server to a participant with a page of the history of the room;
+// either as asked with FetchRoomHistory, or as the replay asked with JoinRoom. Every record holds a
+// message broadcast in the room, which can be unmarshalled with the registry by its protocol.
+// If More is set, the next page is fetched with FetchRoomHistory since Next.
+type RoomHistory struct {
+	interceptor.BaseMessage
+	RoomID types.RoomID `json:"room_id"`
+	room.HistoryPage
+}
+
+func NewRoomHistoryMessage(id types.RoomID, page room.HistoryPage) (*RoomHistory, error) {
+	msg := &RoomHistory{
+		RoomID:      id,
+		HistoryPage: page,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.BaseMessage = bmsg
+	return msg, nil
+}
+
+func NewRoomHistoryMessageFactory(id types.RoomID, page room.HistoryPage) func() (message.Message, error) {
+	return func() (message.Message, error) {
+		return NewRoomHistoryMessage(id, page)
+	}
+}
+
+func (m *RoomHistory) GetProtocol() message.Protocol {
+	return RoomHistoryProtocol
+}
+
+func (m *RoomHistory) ReadProcess(_ context.Context, _i interceptor.Interceptor, _ interceptor.Connection) error {
+	_, ok := _i.(*chat.ClientInterceptor)
+	if !ok {
+		return interceptor.ErrInvalidInterceptor
+	}
+
+	// NOTE: INTENTIONALLY EMPTY
+	return nil
+}
@@ -23,7 +23,9 @@ type SendHealthStats struct {
 	Timestamp                time.Time    `json:"timestamp"` // in nanoseconds
 }
 
-func NewSendHealthStats(id types.RoomID) (*SendHealthStats, error) {
+// NewSendHealthStats creates a health request which expires after ttl (a zero ttl never expires);
+// a request older than the tracking interval is superseded by the next one.
+func NewSendHealthStats(id types.RoomID, ttl time.Duration) (*SendHealthStats, error) {
 	msg := &SendHealthStats{
 		RoomID:    id,
 		Timestamp: time.Now(),
@@ -31,16 +33,20 @@ func NewSendHealthStats(id types.RoomID) (*SendHealthStats, error) {
 
 	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	msg.BaseMessage = bmsg
 
+	if ttl > 0 {
+		msg.SetTTL(ttl)
+	}
+
 	return msg, nil
 }
 
-func NewRequestHealthFactory(id types.RoomID) func() (message.Message, error) {
+func NewRequestHealthFactory(id types.RoomID, ttl time.Duration) func() (message.Message, error) {
 	return func() (message.Message, error) {
-		return NewSendHealthStats(id)
+		return NewSendHealthStats(id, ttl)
 	}
 }
 
@@ -48,6 +54,10 @@ func (m *SendHealthStats) GetProtocol() message.Protocol {
 	return RequestHealthProtocol
 }
 
+func (m *SendHealthStats) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
 func (m *SendHealthStats) ReadProcess(ctx context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	s, ok := _i.(*chat.ClientInterceptor)
 	if !ok {
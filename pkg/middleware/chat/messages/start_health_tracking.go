@@ -58,7 +58,7 @@ func (m *StartHealthTracking) ReadProcess(ctx context.Context, _i interceptor.In
 		return interceptor.ErrInterfaceMisMatch
 	}
 
-	if err := t.StartHealthTracking(m.RoomID, m.Interval, process.NewSendMessageStreamToAllParticipants(nil, NewRequestHealthFactory(m.RoomID), m.RoomID, m.Interval, room.TTL())); err != nil {
+	if err := t.StartHealthTracking(m.RoomID, m.Interval, process.NewSendMessageStreamToAllParticipants(nil, NewRequestHealthFactory(m.RoomID, m.Interval), m.RoomID, m.Interval, room.TTL())); err != nil {
 		_ = process.NewSendMessage(NewFailStartHealthTrackingMessageFactory(m.RoomID, err)).Process(ctx, nil, s)
 		return err
 	}
@@ -38,7 +38,8 @@ func (m *StopHealthTracking) ReadProcess(ctx context.Context, _i interceptor.Int
 		return err
 	}
 
-	if err := process.NewDeleteHealthRoom(m.RoomID).Process(ctx, i.Health, s); err != nil {
+	deleter := process.NewDeleteHealthRoom(m.RoomID)
+	if err := deleter.Process(ctx, i.Health, s); err != nil {
 		_ = process.NewSendMessage(NewFailStopHealthTrackingMessageFactory(m.RoomID, err)).Process(ctx, nil, s)
 		return err
 	}
@@ -51,3 +51,11 @@ func (m *SuccessLeaveRoom) ReadProcess(_ context.Context, _i interceptor.Interce
 	// NOTE: INTENTIONALLY EMPTY
 	return nil
 }
+
+// WithLeaveNotifications makes the server interceptor send SuccessLeaveRoom to the remaining participants of
+// the rooms a disconnected client is removed from; it is the default (see chat.SetDefaultLeaveNotification)
+var WithLeaveNotifications = chat.WithLeaveNotification(NewSuccessLeaveRoomMessageFactory)
+
+func init() {
+	chat.SetDefaultLeaveNotification(NewSuccessLeaveRoomMessageFactory)
+}
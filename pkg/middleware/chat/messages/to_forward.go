@@ -13,12 +13,42 @@ import (
 
 const ForwardMessageProtocol message.Protocol = "room:forward_message"
 
+// ToForward is the message sent by a participant to the server to forward the wrapped message to the given
+// participants of the room or, without any, to broadcast it to every other participant. Only broadcast
+// messages are recorded in the history of the room.
 type ToForward struct {
 	interceptor.BaseMessage
 	RoomID types.RoomID           `json:"room_id"`
 	To     []interceptor.ClientID `json:"to"`
 }
 
+// NewToForward wraps msg to be forwarded by the server to the given participants of the room
+func NewToForward(roomID types.RoomID, msg message.Message, to ...interceptor.ClientID) (*ToForward, error) {
+	forward := &ToForward{
+		RoomID: roomID,
+		To:     to,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(msg.GetProtocol(), msg, forward)
+	if err != nil {
+		return nil, err
+	}
+	forward.BaseMessage = bmsg
+	message.InheritExpiry(forward, msg)
+	message.InheritTrace(forward, msg)
+	interceptor.InheritPriority(forward, msg)
+
+	return forward, nil
+}
+
+// ForwardTo returns a wrapper which forwards every wrapped message through the room. It can be
+// used to relay the messages of other middlewares, for example the streams of the stream middleware.
+func ForwardTo(roomID types.RoomID, to ...interceptor.ClientID) func(message.Message) (message.Message, error) {
+	return func(msg message.Message) (message.Message, error) {
+		return NewToForward(roomID, msg, to...)
+	}
+}
+
 func (m *ToForward) GetProtocol() message.Protocol {
 	return ForwardMessageProtocol
 }
@@ -28,6 +58,11 @@ func (m *ToForward) ReadProcess(ctx context.Context, _i interceptor.Interceptor,
 	if !ok {
 		return interceptor.ErrInterfaceMisMatch
 	}
+	if message.IsExpired(m) {
+		i.DropExpired(ctx, connection, m, message.StageForward)
+		return fmt.Errorf("error while forwarding message in room %s; err: %w", m.RoomID, message.ErrExpired)
+	}
+
 	s, err := i.GetState(connection)
 	if err != nil {
 		return err
@@ -62,6 +62,10 @@ func (m *UpdateHealthStat) GetProtocol() message.Protocol {
 	return HealthResponseProtocol
 }
 
+func (m *UpdateHealthStat) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
 func (m *UpdateHealthStat) ReadProcess(ctx context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	i, ok := _i.(*chat.ServerInterceptor)
 	if !ok {
@@ -0,0 +1,284 @@
+package messages
+
+import (
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+// This file holds the validation rules of the chat messages. The rules are enforced by the
+// message registry right after decoding (see message.Validator), so the processors can rely
+// on the room ids, durations and client ids being present and sane.
+
+func validateRoomID(field string, id types.RoomID) error {
+	return message.RequireNotEmpty(field, id)
+}
+
+func validateClientIDs(field string, ids []interceptor.ClientID, required bool) error {
+	if required && len(ids) == 0 {
+		return message.NewValidationError(field, "must contain at least one client id")
+	}
+
+	for _, id := range ids {
+		if id == "" {
+			return message.NewValidationError(field, "must not contain empty client ids")
+		}
+	}
+
+	return nil
+}
+
+func validateHistoryQuery(field string, query room.HistoryQuery) error {
+	if query.Last < 0 {
+		return message.NewValidationError(field+".last", "must not be negative")
+	}
+
+	if query.Limit < 0 || query.Limit > room.MaxHistoryPageSize {
+		return message.NewValidationError(field+".limit", fmt.Sprintf("must be between 0 and %d", room.MaxHistoryPageSize))
+	}
+
+	return nil
+}
+
+func validateFailure(id types.RoomID, reason string) error {
+	if err := validateRoomID("room_id", id); err != nil {
+		return err
+	}
+
+	return message.RequireNotEmpty("error", reason)
+}
+
+// IDENT
+
+func (m *Ident) Validate() error {
+	return message.RequireNotEmpty("header.sender", m.CurrentHeader.Sender)
+}
+
+func (m *IdentResponse) Validate() error {
+	return message.RequireNotEmpty("header.sender", m.CurrentHeader.Sender)
+}
+
+// ROOM
+
+func (m *CreateRoom) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	if err := message.RequirePositive("ttl", m.TTL); err != nil {
+		return err
+	}
+
+	if m.History != nil {
+		if err := message.RequirePositive("history.size", m.History.Size); err != nil {
+			return err
+		}
+
+		if m.History.Size > room.MaxHistorySize {
+			return message.NewValidationError("history.size", fmt.Sprintf("must not exceed %d", room.MaxHistorySize))
+		}
+
+		if m.History.Age < 0 {
+			return message.NewValidationError("history.age", "must not be negative")
+		}
+	}
+
+	return validateClientIDs("allowed", m.Allowed, false)
+}
+
+func (m *SuccessCreateRoom) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailCreateRoom) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *DeleteRoom) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *SuccessDeleteRoom) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailDeleteRoom) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *JoinRoom) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	if m.Replay != nil {
+		return validateHistoryQuery("replay", *m.Replay)
+	}
+
+	return nil
+}
+
+func (m *SuccessJoinRoom) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return message.RequireNotEmpty("client_id", m.ClientID)
+}
+
+func (m *FailJoinRoom) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *LeaveRoom) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *SuccessLeaveRoom) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return message.RequireNotEmpty("client_id", m.ClientID)
+}
+
+func (m *FailLeaveRoom) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *ToForward) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	if err := validateClientIDs("to", m.To, false); err != nil {
+		return err
+	}
+
+	if m.NextProtocol == message.NoneProtocol || len(m.NextPayload) == 0 {
+		return message.NewValidationError("next", "a message to forward is required")
+	}
+
+	return nil
+}
+
+func (m *ForwardedMessage) Validate() error {
+	if m.NextProtocol == message.NoneProtocol || len(m.NextPayload) == 0 {
+		return message.NewValidationError("next", "the forwarded message is missing")
+	}
+
+	return nil
+}
+
+func (m *FetchRoomHistory) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return validateHistoryQuery("query", m.HistoryQuery)
+}
+
+func (m *RoomHistory) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailFetchRoomHistory) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+// HEALTH
+
+func (m *StartHealthTracking) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return message.RequirePositive("interval", m.Interval)
+}
+
+func (m *SuccessTrackHealthInRoom) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailStartHealthTracking) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *StopHealthTracking) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *SuccessUnmarkRoomForHealthTracking) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailStopHealthTracking) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *SendHealthStats) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	return message.RequireNotZero("timestamp", m.Timestamp)
+}
+
+func (m *UpdateHealthStat) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	if m.Latency < 0 {
+		return message.NewValidationError("latency", "must not be negative")
+	}
+
+	return nil
+}
+
+func (m *StartStreamingHealthSnapshots) Validate() error {
+	if err := validateRoomID("roomid", m.Roomid); err != nil {
+		return err
+	}
+
+	return message.RequirePositive("interval", m.Interval)
+}
+
+func (m *SuccessStartHealthStreaming) Validate() error {
+	if err := validateRoomID("room_id", m.RoomID); err != nil {
+		return err
+	}
+
+	if err := message.RequirePositive("ttl", m.TTL); err != nil {
+		return err
+	}
+
+	return validateClientIDs("allowed", m.Allowed, false)
+}
+
+func (m *FailStartHealthStreaming) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
+
+func (m *UpdateHealthSnapshot) Validate() error {
+	if m.UpdateHealthSnapshot == nil {
+		return message.NewValidationError("snapshot", "must be set")
+	}
+
+	return validateRoomID("snapshot.roomid", m.Snapshot.Roomid)
+}
+
+func (m *StopStreamingHealthSnapshot) Validate() error {
+	return validateRoomID("roomid", m.Roomid)
+}
+
+func (m *SuccessStopHealthStreaming) Validate() error {
+	return validateRoomID("room_id", m.RoomID)
+}
+
+func (m *FailStopHealthStreaming) Validate() error {
+	return validateFailure(m.RoomID, m.Error)
+}
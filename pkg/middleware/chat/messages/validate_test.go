@@ -0,0 +1,112 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+)
+
+// jsonNames returns the JSON names of the fields of the struct, including the ones of its embedded structs
+func jsonNames(t reflect.Type, names map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			jsonNames(embedded, names)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+}
+
+func TestValidate_ReportsJSONFieldNames(t *testing.T) {
+	for _, registration := range Registrations() {
+		msg, err := registration.Factory.Create()
+		if err != nil {
+			t.Fatalf("%s: Create() error = %v", registration.Protocol, err)
+		}
+
+		v, ok := msg.(message.Validator)
+		if !ok {
+			continue
+		}
+
+		// NOTE: THE ZERO MESSAGE IS INVALID FOR MOST PROTOCOLS; THE REPORTED FIELD MUST BE A JSON NAME
+		var verr *message.ValidationError
+		if err := v.Validate(); !errors.As(err, &verr) || verr.Field == "" {
+			continue
+		}
+
+		names := make(map[string]bool)
+		jsonNames(reflect.TypeOf(msg), names)
+
+		field, _, _ := strings.Cut(verr.Field, ".")
+		if !names[field] {
+			t.Errorf("%s: Validate() reported field %q, which is not a JSON field of the message", registration.Protocol, verr.Field)
+		}
+	}
+}
+
+func TestValidate_RoomID(t *testing.T) {
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+
+	for _, data := range []string{
+		`{"protocol":"room:join_room","room_id":"","next_protocol":"none"}`,
+		`{"protocol":"chat:untrack_health","room_id":"","next_protocol":"none"}`,
+	} {
+		_, err := registry.UnmarshalRaw(message.Payload(data))
+
+		var verr *message.ValidationError
+		if !errors.As(err, &verr) || verr.Field != "room_id" {
+			t.Errorf("UnmarshalRaw(%s) error = %v, want a validation error of room_id", data, err)
+		}
+	}
+
+	if _, err := registry.UnmarshalRaw(message.Payload(`{"protocol":"room:join_room","room_id":"room","next_protocol":"none"}`)); err != nil {
+		t.Errorf("UnmarshalRaw() error = %v, want the room id read from room_id", err)
+	}
+}
+
+func TestValidate_HistorySize(t *testing.T) {
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+
+	data := fmt.Sprintf(`{"protocol":"room:create_room","room_id":"room","ttl":60000000000,"history":{"size":%d},"next_protocol":"none"}`, room.MaxHistorySize+1)
+
+	var verr *message.ValidationError
+	if _, err := registry.UnmarshalRaw(message.Payload(data)); !errors.As(err, &verr) || verr.Field != "history.size" {
+		t.Errorf("UnmarshalRaw() error = %v, want a validation error of history.size", err)
+	}
+}
@@ -10,7 +10,7 @@ import (
 
 // AddToRoom is a process that adds a state (client) to a room.
 type AddToRoom struct {
-	RoomID types.RoomID
+	RoomID types.RoomID `json:"room_id"`
 	AsyncProcess
 }
 
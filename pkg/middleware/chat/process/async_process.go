@@ -46,11 +46,14 @@ func (p *AsyncProcess) ProcessBackground(ctx context.Context, _p interceptor.Can
 	}
 
 	go func() {
-		err := p.Process(p.ctx, _p.(interceptor.CanProcess), s)
+		err := p.run(_p, s)
 		p.mux.Lock()
 		defer p.mux.Unlock()
 		defer p.cancel()
 
+		// NOTE: A RECOVERED PANIC IS COUNTED IN THE ERROR STATISTICS OF THE CHAT LAYER OF THE CHAIN
+		interceptor.ReportPanic(p.ctx, err)
+
 		p.err = err
 		p.done <- struct{}{}
 
@@ -62,6 +65,13 @@ func (p *AsyncProcess) ProcessBackground(ctx context.Context, _p interceptor.Can
 	return p
 }
 
+// run executes the process; a panic is returned as *interceptor.PanicError so that it only fails this process
+func (p *AsyncProcess) run(_p interceptor.CanProcessBackground, s interceptor.State) (err error) {
+	defer interceptor.RecoverPanic(&err)
+
+	return p.Process(p.ctx, _p.(interceptor.CanProcess), s)
+}
+
 func (p *AsyncProcess) Wait() error {
 	<-p.done
 	p.mux.RLock()
@@ -6,6 +6,7 @@ import (
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
 )
 
@@ -14,6 +15,8 @@ type CreateRoom struct {
 	RoomID  types.RoomID           `json:"room_id"`
 	Allowed []interceptor.ClientID `json:"allowed"`
 	TTL     time.Duration          `json:"ttl"`
+	// History sets the history kept by the room; the room keeps none if it is not set
+	History *History `json:"history,omitempty"`
 	AsyncProcess
 }
 
@@ -25,6 +28,12 @@ func NewCreateRoom(roomID types.RoomID, allowed []interceptor.ClientID, ttl time
 	}
 }
 
+// History sets the history kept by a room; see room.WithHistory
+type History struct {
+	Size int           `json:"size"`
+	Age  time.Duration `json:"age,omitempty"`
+}
+
 // Process requires room processor to be passed in.
 func (p *CreateRoom) Process(ctx context.Context, processor interceptor.CanProcess, _ interceptor.State) error {
 	select {
@@ -36,7 +45,12 @@ func (p *CreateRoom) Process(ctx context.Context, processor interceptor.CanProce
 			return interceptor.ErrInterfaceMisMatch
 		}
 
-		_, err := r.CreateRoom(p.RoomID, p.Allowed, p.TTL)
+		options := make([]room.Option, 0)
+		if p.History != nil {
+			options = append(options, room.WithHistory(p.History.Size, p.History.Age))
+		}
+
+		_, err := r.CreateRoom(p.RoomID, p.Allowed, p.TTL, options...)
 		if err != nil {
 			return err
 		}
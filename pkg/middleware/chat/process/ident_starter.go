@@ -17,7 +17,7 @@ func NewIdentInit() *IdentInit {
 
 func (p *IdentInit) Process(ctx context.Context, _ interceptor.CanProcess, s interceptor.State) error {
 	// TODO: SEND IDENT MESSAGE // PROBLEM HERE AS PROCESS MODULE CANNOT IMPORT MESSAGE
-	if err := s.Write(nil); err != nil {
+	if err := s.Write(ctx, nil); err != nil {
 		return err
 	}
 
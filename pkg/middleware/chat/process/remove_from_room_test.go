@@ -5,118 +5,55 @@ import (
 	"errors"
 	"testing"
 
-	chaterrors "github.com/harshabose/socket-comm/pkg/middleware/chat/errors"
-	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
-	"github.com/harshabose/socket-comm/pkg/middleware/chat/state"
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
 )
 
-// MockRemoveProcessor is a mock implementation of the Processor interface with CanRemove
-type MockRemoveProcessor struct {
-	removeFunc func(types.RoomID, *state.State) error
-}
-
-// Remove is a mock implementation of the CanRemove interface
-func (m *MockRemoveProcessor) Remove(roomID types.RoomID, s *state.State) error {
-	if m.removeFunc != nil {
-		return m.removeFunc(roomID, s)
-	}
-	return nil
-}
-
-// Process implements the CanProcess interface
-func (m *MockRemoveProcessor) Process(ctx context.Context, p interfaces.CanBeProcessed, s *state.State) error {
-	// This method should not be called in our tests
-	return errors.New("Process method should not be called in tests")
-}
-
-// ProcessBackground implements the CanProcessBackground interface
-func (m *MockRemoveProcessor) ProcessBackground(ctx context.Context, p interfaces.CanBeProcessedBackground, s *state.State) interfaces.CanBeProcessedBackground {
-	// This method should not be called in our tests
-	return nil
-}
-
-// MockProcessorWithoutRemove is a mock implementation of the Processor interface without CanRemove
-type MockProcessorWithoutRemove struct{}
-
-// Process implements the CanProcess interface
-func (m *MockProcessorWithoutRemove) Process(ctx context.Context, p interfaces.CanBeProcessed, s *state.State) error {
-	// This method should not be called in our tests
-	return errors.New("Process method should not be called in tests")
-}
-
-// ProcessBackground implements the CanProcessBackground interface
-func (m *MockProcessorWithoutRemove) ProcessBackground(ctx context.Context, p interfaces.CanBeProcessedBackground, s *state.State) interfaces.CanBeProcessedBackground {
-	// This method should not be called in our tests
-	return nil
-}
-
 func TestRemoveFromRoom_Process(t *testing.T) {
+	errRemove := errors.New("failed to remove from room")
+
 	tests := []struct {
-		name           string
-		roomID         types.RoomID
-		mockRemoveFunc func(types.RoomID, *state.State) error
-		wantErr        bool
-		expectedErr    error
+		name        string
+		roomID      types.RoomID
+		processor   interceptor.Processor
+		expectedErr error
 	}{
 		{
-			name:   "successful remove from room",
-			roomID: "test-room",
-			mockRemoveFunc: func(roomID types.RoomID, s *state.State) error {
-				return nil
-			},
-			wantErr: false,
+			name:      "successful remove from room",
+			roomID:    "test-room",
+			processor: &interceptortest.Rooms{},
 		},
 		{
 			name:   "error removing from room",
 			roomID: "test-room",
-			mockRemoveFunc: func(roomID types.RoomID, s *state.State) error {
-				return errors.New("failed to remove from room")
-			},
-			wantErr:     true,
-			expectedErr: errors.New("failed to remove from room"),
+			processor: &interceptortest.Rooms{RemoveFunc: func(types.RoomID, interceptor.State) error {
+				return errRemove
+			}},
+			expectedErr: errRemove,
 		},
 		{
-			name:           "interface mismatch",
-			roomID:         "test-room",
-			mockRemoveFunc: nil, // This will cause the type assertion to fail
-			wantErr:        true,
-			expectedErr:    chaterrors.ErrInterfaceMisMatch,
+			name:        "interface mismatch",
+			roomID:      "test-room",
+			processor:   &interceptortest.Processor{},
+			expectedErr: interceptor.ErrInterfaceMisMatch,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a dummy state
-			s := &state.State{}
+			s := interceptortest.NewState(context.Background())
+			process := &RemoveFromRoom{RoomID: tt.roomID}
 
-			// Create the process
-			process := &RemoveFromRoom{
-				RoomID: tt.roomID,
-			}
+			err := process.Process(context.Background(), tt.processor, s)
+			interceptortest.AssertError(t, err, tt.expectedErr)
 
-			// Use different mock processor for interface mismatch test
-			var processor interfaces.Processor
-			if tt.name == "interface mismatch" {
-				processor = &MockProcessorWithoutRemove{}
-			} else {
-				processor = &MockRemoveProcessor{
-					removeFunc: tt.mockRemoveFunc,
+			if rooms, ok := tt.processor.(*interceptortest.Rooms); ok {
+				calls := rooms.Calls.Calls()
+				if len(calls) != 1 || calls[0].Args[0] != tt.roomID || calls[0].Args[1] != s {
+					t.Errorf("calls = %v; want Remove(%s, state)", calls, tt.roomID)
 				}
 			}
-
-			// Execute the process
-			err := process.Process(context.Background(), processor, s)
-
-			// Check if the error matches expectations
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if tt.wantErr && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error() {
-				t.Errorf("Process() error = %v, expectedErr %v", err, tt.expectedErr)
-			}
 		})
 	}
 }
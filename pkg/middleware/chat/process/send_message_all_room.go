@@ -2,9 +2,7 @@ package process
 
 import (
 	"context"
-	"fmt"
 
-	"github.com/harshabose/socket-comm/internal/util"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
@@ -42,15 +40,12 @@ func (p *SendMessageToAllParticipantsInRoom) Process(ctx context.Context, proces
 			return err
 		}
 
-		participants := room.GetParticipants()
-		merr := util.NewMultiError()
-
-		for _, participant := range participants {
-			if err := NewSendMessageBetweenParticipantsInRoom(p.Roomid, participant, p.msgFactory).Process(ctx, processor, nil); err != nil {
-				fmt.Println("error while sending message to room; err: ", err.Error())
-			}
+		msg, err := p.msgFactory()
+		if err != nil {
+			return err
 		}
 
-		return merr.ErrorOrNil()
+		// NOTE: THE MESSAGE IS THE ROOM'S OWN; IT IS NOT WRITTEN ON BEHALF OF A PARTICIPANT (OR RECORDED)
+		return room.Notify(msg)
 	}
 }
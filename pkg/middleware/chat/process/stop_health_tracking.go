@@ -9,7 +9,7 @@ import (
 )
 
 type StopHealthTracking struct {
-	RoomID types.RoomID
+	RoomID types.RoomID `json:"room_id"`
 	AsyncProcess
 }
 
@@ -4,303 +4,159 @@ import (
 	"context"
 	"errors"
 	"testing"
-	"time"
 
-	chaterrors "github.com/harshabose/socket-comm/pkg/middleware/chat/errors"
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/health"
-	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
-	"github.com/harshabose/socket-comm/pkg/middleware/chat/state"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
 )
 
-// MockUpdateProcessor is a mock implementation of the Processor interface with CanUpdate
-type MockUpdateProcessor struct {
-	updateFunc func(types.RoomID, types.ClientID, *health.Stat) error
-}
-
-// Update is a mock implementation of the CanUpdate interface
-func (m *MockUpdateProcessor) Update(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-	if m.updateFunc != nil {
-		return m.updateFunc(roomID, clientID, stat)
-	}
-	return nil
-}
-
-// Process implements the CanProcess interface
-func (m *MockUpdateProcessor) Process(ctx context.Context, p interfaces.CanBeProcessed, s *state.State) error {
-	// This method should not be called in our tests
-	return errors.New("Process method should not be called in tests")
-}
-
-// ProcessBackground implements the CanProcessBackground interface
-func (m *MockUpdateProcessor) ProcessBackground(ctx context.Context, p interfaces.CanBeProcessedBackground, s *state.State) interfaces.CanBeProcessedBackground {
-	// This method should not be called in our tests
-	return nil
-}
-
-// MockProcessorWithoutUpdate is a mock implementation of the Processor interface without CanUpdate
-type MockProcessorWithoutUpdate struct{}
-
-// Process implements the CanProcess interface
-func (m *MockProcessorWithoutUpdate) Process(ctx context.Context, p interfaces.CanBeProcessed, s *state.State) error {
-	// Call the Process method on the CanBeProcessed interface
-	return p.Process(ctx, m, s)
-}
-
-// ProcessBackground implements the CanProcessBackground interface
-func (m *MockProcessorWithoutUpdate) ProcessBackground(ctx context.Context, p interfaces.CanBeProcessedBackground, s *state.State) interfaces.CanBeProcessedBackground {
-	// Return the CanBeProcessedBackground interface
-	return p
-}
-
-// MockBackgroundProcessorWithoutUpdate is a mock implementation of the Processor interface without CanUpdate
-// specifically for background processing tests
-type MockBackgroundProcessorWithoutUpdate struct{}
-
-// Process implements the CanProcess interface
-func (m *MockBackgroundProcessorWithoutUpdate) Process(ctx context.Context, p interfaces.CanBeProcessed, s *state.State) error {
-	// This method should not be called in our tests
-	return errors.New("Process method should not be called in tests")
-}
+var errUpdate = errors.New("failed to update health")
 
-// ProcessBackground implements the CanProcessBackground interface
-func (m *MockBackgroundProcessorWithoutUpdate) ProcessBackground(ctx context.Context, p interfaces.CanBeProcessedBackground, s *state.State) interfaces.CanBeProcessedBackground {
-	// This method should not be called in our tests
-	return p
+type updateHealthTest struct {
+	name           string
+	roomID         types.RoomID
+	stat           health.Stat
+	processor      interceptor.Processor
+	unknownClient  bool
+	contextTimeout bool
+	expectedErr    error
 }
 
-func TestUpdateHealthStat_Process(t *testing.T) {
-	tests := []struct {
-		name           string
-		roomID         types.RoomID
-		stat           health.Stat
-		mockUpdateFunc func(types.RoomID, types.ClientID, *health.Stat) error
-		contextTimeout bool
-		wantErr        bool
-		expectedErr    error
-	}{
+func updateHealthTests() []updateHealthTest {
+	return []updateHealthTest{
 		{
-			name:   "successful update health",
-			roomID: "test-room",
-			stat:   health.Stat{},
-			mockUpdateFunc: func(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-				return nil
-			},
-			wantErr: false,
+			name:      "successful update health",
+			roomID:    "test-room",
+			stat:      health.Stat{},
+			processor: &interceptortest.Health{},
 		},
 		{
 			name:   "error updating health",
 			roomID: "test-room",
 			stat:   health.Stat{},
-			mockUpdateFunc: func(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-				return errors.New("failed to update health")
-			},
-			wantErr:     true,
-			expectedErr: errors.New("failed to update health"),
+			processor: &interceptortest.Health{UpdateFunc: func(types.RoomID, interceptor.ClientID, *health.Stat) error {
+				return errUpdate
+			}},
+			expectedErr: errUpdate,
 		},
 		{
 			name:           "context cancelled",
 			roomID:         "test-room",
 			stat:           health.Stat{},
+			processor:      &interceptortest.Health{},
 			contextTimeout: true,
-			wantErr:        true,
-			expectedErr:    chaterrors.ErrContextCancelled,
+			expectedErr:    interceptor.ErrContextCancelled,
 		},
 		{
-			name:           "interface mismatch",
-			roomID:         "test-room",
-			stat:           health.Stat{},
-			mockUpdateFunc: nil, // This will cause the type assertion to fail
-			wantErr:        true,
-			expectedErr:    chaterrors.ErrInterfaceMisMatch,
+			name:          "unknown client id",
+			roomID:        "test-room",
+			stat:          health.Stat{},
+			processor:     &interceptortest.Health{},
+			unknownClient: true,
+			expectedErr:   interceptortest.ErrUnknownClientID,
+		},
+		{
+			name:        "interface mismatch",
+			roomID:      "test-room",
+			stat:        health.Stat{},
+			processor:   &interceptortest.Processor{},
+			expectedErr: interceptor.ErrInterfaceMisMatch,
 		},
 	}
+}
+
+func (tt updateHealthTest) state(ctx context.Context) interceptor.State {
+	if tt.unknownClient {
+		return interceptortest.NewState(ctx)
+	}
+
+	return interceptortest.NewStateWithID(ctx, "test-client")
+}
+
+// checkUpdate checks the health processor was updated with the client and room, when the process got that far
+func (tt updateHealthTest) checkUpdate(t *testing.T) {
+	t.Helper()
+
+	h, ok := tt.processor.(*interceptortest.Health)
+	if !ok {
+		return
+	}
+
+	if tt.contextTimeout || tt.unknownClient {
+		interceptortest.AssertCalled(t, &h.Calls, "Update", 0)
+		return
+	}
+
+	calls := h.Calls.Calls()
+	if len(calls) != 1 || calls[0].Args[0] != tt.roomID || calls[0].Args[1] != interceptor.ClientID("test-client") {
+		t.Errorf("calls = %v; want Update(%s, test-client, stat)", calls, tt.roomID)
+	}
+}
 
-	for _, tt := range tests {
+func TestUpdateHealthStat_Process(t *testing.T) {
+	for _, tt := range updateHealthTests() {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a context that can be cancelled if needed
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			// Cancel the context if the test requires it
 			if tt.contextTimeout {
 				cancel()
 			}
 
-			// Create a dummy state with a client id
-			s := &state.State{}
-			s.SetClientID("test-client")
-
-			// Create the process
 			process := &UpdateHealthStat{
 				RoomID: tt.roomID,
 				Stat:   tt.stat,
 			}
 
-			// Use different mock processor for interface mismatch test
-			var processor interfaces.Processor
-			if tt.name == "interface mismatch" {
-				processor = &MockProcessorWithoutUpdate{}
-			} else {
-				processor = &MockUpdateProcessor{
-					updateFunc: tt.mockUpdateFunc,
-				}
-			}
-
-			// Execute the process
-			err := process.Process(ctx, processor, s)
-
-			// Check if the error matches expectations
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if tt.wantErr && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error() {
-				t.Errorf("Process() error = %v, expectedErr %v", err, tt.expectedErr)
-			}
+			err := process.Process(ctx, tt.processor, tt.state(ctx))
+			interceptortest.AssertError(t, err, tt.expectedErr)
+			tt.checkUpdate(t)
 		})
 	}
 }
 
 func TestUpdateHealthStat_ProcessBackground(t *testing.T) {
-	tests := []struct {
-		name           string
-		roomID         types.RoomID
-		stat           health.Stat
-		mockUpdateFunc func(types.RoomID, types.ClientID, *health.Stat) error
-		contextTimeout bool
-		wantErr        bool
-		expectedErr    error
-	}{
-		{
-			name:   "successful background update health",
-			roomID: "test-room",
-			stat:   health.Stat{},
-			mockUpdateFunc: func(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-				return nil
-			},
-			wantErr: false,
-		},
-		{
-			name:   "error updating health in background",
-			roomID: "test-room",
-			stat:   health.Stat{},
-			mockUpdateFunc: func(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-				return errors.New("failed to update health")
-			},
-			wantErr:     true,
-			expectedErr: errors.New("failed to update health"),
-		},
-		{
-			name:           "context cancelled in background",
-			roomID:         "test-room",
-			stat:           health.Stat{},
-			contextTimeout: true,
-			wantErr:        true,
-			expectedErr:    chaterrors.ErrContextCancelled,
-		},
-		{
-			name:           "interface mismatch in background",
-			roomID:         "test-room",
-			stat:           health.Stat{},
-			mockUpdateFunc: nil, // This will cause the type assertion to fail
-			wantErr:        true,
-			expectedErr:    chaterrors.ErrInterfaceMisMatch,
-		},
-	}
-
-	for _, tt := range tests {
+	for _, tt := range updateHealthTests() {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a context that can be cancelled if needed
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			// Cancel the context if the test requires it
 			if tt.contextTimeout {
 				cancel()
 			}
 
-			// Create a dummy state with a client id
-			s := &state.State{}
-			s.SetClientID("test-client")
-
-			// Create the process
 			process := &UpdateHealthStat{
 				RoomID: tt.roomID,
 				Stat:   tt.stat,
 			}
-
 			process.AsyncProcess = AsyncProcess{
 				CanBeProcessed: process,
 			}
 
-			// Use different mock processor for interface mismatch test
-			var processor interfaces.Processor
-			if tt.name == "interface mismatch in background" {
-				processor = &MockBackgroundProcessorWithoutUpdate{}
-			} else {
-				processor = &MockUpdateProcessor{
-					updateFunc: tt.mockUpdateFunc,
-				}
-			}
-
-			// Execute the process in background
-			backgroundProcess := process.ProcessBackground(ctx, processor, s)
-
-			// Wait for the background process to complete
-			err := backgroundProcess.Wait()
-
-			// Check if the error matches expectations
-			if (err != nil) != tt.wantErr {
-				t.Errorf("ProcessBackground() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if tt.wantErr && tt.expectedErr != nil && err.Error() != tt.expectedErr.Error() {
-				t.Errorf("ProcessBackground() error = %v, expectedErr %v", err, tt.expectedErr)
-			}
+			err := interceptortest.Wait(t, process.ProcessBackground(ctx, tt.processor, tt.state(ctx)))
+			interceptortest.AssertError(t, err, tt.expectedErr)
+			tt.checkUpdate(t)
 		})
 	}
 }
 
 func TestUpdateHealthStat_ProcessBackground_Stop(t *testing.T) {
-	// Create a context
-	ctx := context.Background()
-
-	// Create a dummy state with a client id
-	s := &state.State{}
-	s.SetClientID("test-client")
-
-	// Create a mock processor that sleeps to simulate a long-running process
-	processor := &MockUpdateProcessor{
-		updateFunc: func(roomID types.RoomID, clientID types.ClientID, stat *health.Stat) error {
-			time.Sleep(500 * time.Millisecond)
-			return nil
-		},
-	}
+	ctx, cancel := context.WithCancel(context.Background())
 
-	// Create the process
 	process := &UpdateHealthStat{
 		RoomID: "test-room",
 		Stat:   health.Stat{},
 	}
+	process.AsyncProcess = ManualAsyncProcessInitialisation(ctx, cancel)
+	process.CanBeProcessed = process
 
-	process.AsyncProcess = AsyncProcess{
-		CanBeProcessed: process,
-	}
-
-	// Execute the process in background
-	backgroundProcess := process.ProcessBackground(ctx, processor, s)
+	// NOTE: STOPPED BEFORE IT RUNS SO THAT THE PROCESS DETERMINISTICALLY SEES THE CANCELLATION
+	process.Stop()
 
-	// Stop the background process immediately
-	backgroundProcess.Stop()
+	processor := &interceptortest.Health{}
+	err := interceptortest.Wait(t, process.ProcessBackground(context.Background(), processor, interceptortest.NewStateWithID(ctx, "test-client")))
 
-	// Wait for the background process to complete
-	err := backgroundProcess.Wait()
-
-	// The process should have been cancelled
-	if err != chaterrors.ErrContextCancelled {
-		t.Errorf("ProcessBackground() after Stop() error = %v, want %v", err, chaterrors.ErrContextCancelled)
-	}
+	interceptortest.AssertError(t, err, interceptor.ErrContextCancelled)
+	interceptortest.AssertCalled(t, &processor.Calls, "Update", 0)
 }
@@ -189,6 +189,33 @@ func (p *Health) RemoveHealthSnapshotStreamer(roomid types.RoomID, s interceptor
 	return nil
 }
 
+// RemoveAllHealthSnapshotStreamers stops the snapshot streamers of the client in every room, as when its
+// connection is unbound.
+func (p *Health) RemoveAllHealthSnapshotStreamers(s interceptor.State) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	id, err := s.GetClientID()
+	if err != nil {
+		// NOTE: AN UNIDENTIFIED CLIENT CANNOT HAVE ANY STREAMER
+		return nil
+	}
+
+	for _, session := range p.health {
+		streamer, exists := session.healthSnapshotStreamer[id]
+		if !exists {
+			continue
+		}
+
+		if streamer != nil {
+			streamer.Stop()
+		}
+		delete(session.healthSnapshotStreamer, id)
+	}
+
+	return nil
+}
+
 // Add adds the given client to the health tracking in the given room.
 // Only after calling this method, the stat responses from the clients are updated.
 func (p *Health) Add(roomid types.RoomID, id interceptor.ClientID) error {
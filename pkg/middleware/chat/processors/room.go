@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/harshabose/socket-comm/internal/util"
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/errors"
@@ -40,7 +42,12 @@ func (m *RoomManager) Add(id types.RoomID, s interceptor.State) error {
 		return err
 	}
 
-	return r.Add(id, s)
+	if err := r.Add(id, s); err != nil {
+		return err
+	}
+
+	m.publish(events.RoomJoined, id, s)
+	return nil
 }
 
 func (m *RoomManager) Remove(id types.RoomID, s interceptor.State) error {
@@ -49,7 +56,60 @@ func (m *RoomManager) Remove(id types.RoomID, s interceptor.State) error {
 		return err
 	}
 
-	return r.Remove(id, s)
+	if err := r.Remove(id, s); err != nil {
+		return err
+	}
+
+	m.publish(events.RoomLeft, id, s)
+	return nil
+}
+
+// LeaveAll removes the state from every room it participates in, as when its connection is unbound.
+// Returns:
+//   - []types.RoomID: ids of the rooms left; the remaining participants of these rooms can be notified
+//   - error: nil if successful, or the errors of the rooms which could not be left
+func (m *RoomManager) LeaveAll(s interceptor.State) ([]types.RoomID, error) {
+	id, err := s.GetClientID()
+	if err != nil {
+		// NOTE: AN UNIDENTIFIED CLIENT CANNOT BE A PARTICIPANT OF ANY ROOM
+		return nil, nil
+	}
+
+	m.mux.RLock()
+	rooms := make([]*room.Room, 0)
+	for _, session := range m.rooms {
+		if session != nil && session.room != nil && session.room.IsParticipant(id) {
+			rooms = append(rooms, session.room)
+		}
+	}
+	m.mux.RUnlock()
+
+	left := make([]types.RoomID, 0, len(rooms))
+	merr := util.NewMultiError()
+
+	for _, r := range rooms {
+		if err := r.Remove(r.ID(), s); err != nil {
+			merr.Add(err)
+			continue
+		}
+
+		left = append(left, r.ID())
+		m.publish(events.RoomLeft, r.ID(), s)
+	}
+
+	return left, merr.ErrorOrNil()
+}
+
+// publish publishes the room event; the client is left empty if the state is not identified yet
+func (m *RoomManager) publish(kind events.Kind, id types.RoomID, s interceptor.State) {
+	event := events.Event{Kind: kind, Room: string(id)}
+	if s != nil {
+		if client, err := s.GetClientID(); err == nil {
+			event.Client = client
+		}
+	}
+
+	events.Publish(m.ctx, event)
 }
 
 // CreateRoom creates a new room with the specified id, allowed a client list, and time-to-live duration.
@@ -59,25 +119,38 @@ func (m *RoomManager) Remove(id types.RoomID, s interceptor.State) error {
 //   - id: unique identifier for the room
 //   - allowed: a list of client IDs that are allowed to join the room
 //   - ttl: time-to-live duration after which the room will be automatically deleted
+//   - options: options of the room; for example, room.WithHistory
 //
 // Returns:
 //   - *room.Room: pointer to the newly created room
-//   - error: nil if successful, ErrRoomAlreadyExists if room already exists
-func (m *RoomManager) CreateRoom(id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration) (*room.Room, error) {
+//   - error: nil if successful, ErrRoomAlreadyExists if room already exists, or the error of an option
+func (m *RoomManager) CreateRoom(id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration, options ...room.Option) (*room.Room, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
 	if m.exists(id) {
 		return nil, fmt.Errorf("error while creating r with id %s; err: %s", id, errors.ErrRoomAlreadyExists)
 	}
 
+	r, err := room.NewRoom(m.ctx, id, allowed, ttl, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating r with id %s; err: %w", id, err)
+	}
+
 	m.rooms[id] = &roomSession{
-		room:                        room.NewRoom(m.ctx, id, allowed, ttl),
+		room:                        r,
 		deletionWaiter:              process.NewDeleteRoomWaiter(m.ctx, id, ttl).ProcessBackground(nil, m, nil),
 		healthTrackingRequestSender: nil,
 	}
 
+	m.publish(events.RoomCreated, id, nil)
 	return m.rooms[id].room, nil
 }
 
 func (m *RoomManager) GetRoom(id types.RoomID) (*room.Room, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
 	exists := m.exists(id)
 	if !exists {
 		return nil, fmt.Errorf("error while getting room with id %s; err: %s", id, errors.ErrRoomNotFound)
@@ -173,7 +246,10 @@ func (m *RoomManager) StopHealthTracking(id types.RoomID) error {
 // Returns:
 //   - error: nil if successful, ErrRoomNotFound if room does not exist, or other errors if closing fails
 func (m *RoomManager) DeleteRoom(id types.RoomID) error {
-	if m.exists(id) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if !m.exists(id) {
 		return fmt.Errorf("error while deleting r with id: %s; err: %s", id, errors.ErrRoomNotFound)
 	}
 
@@ -192,6 +268,8 @@ func (m *RoomManager) DeleteRoom(id types.RoomID) error {
 	}
 
 	delete(m.rooms, id)
+
+	m.publish(events.RoomDeleted, id, nil)
 	return nil
 }
 
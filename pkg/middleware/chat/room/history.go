@@ -0,0 +1,187 @@
+package room
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	// DefaultHistoryPageSize is the number of records in a page of history when the query sets no limit
+	DefaultHistoryPageSize = 100
+	// MaxHistoryPageSize bounds the number of records in a page of history
+	MaxHistoryPageSize = 1000
+	// MaxHistorySize bounds the number of records a room keeps; the size is requested by the clients creating
+	// the rooms, so it is bounded by the server
+	MaxHistorySize = 10000
+	// minHistoryCapacity is the capacity the ring of records starts with; it grows up to the size of the history
+	minHistoryCapacity = 16
+)
+
+// Record is a message written to the room, as kept in its history. The message is kept serialized, so
+// that it is replayed as it was written.
+type Record struct {
+	Seq      uint64                 `json:"seq"` // Seq numbers the messages of the room from 1, in the order they were written
+	From     interceptor.ClientID   `json:"from"`
+	To       []interceptor.ClientID `json:"to,omitempty"` // To lists the receivers of a targeted message; empty for broadcasts
+	At       time.Time              `json:"at"`
+	Protocol message.Protocol       `json:"protocol"`
+	Payload  message.Payload        `json:"payload"`
+}
+
+// visibleTo reports whether the record is replayed to the participant: broadcasts are replayed to every
+// participant, targeted messages only to their sender and receivers
+func (r Record) visibleTo(id interceptor.ClientID) bool {
+	return len(r.To) == 0 || r.From == id || slices.Contains(r.To, id)
+}
+
+// HistoryQuery selects the records of a page of history. The records written after Since are selected;
+// if Last is set, only the last Last of them. The page holds the oldest Limit of the selected records;
+// the next page is queried with Since set to the Next of the page.
+type HistoryQuery struct {
+	Since uint64 `json:"since,omitempty"`
+	Last  int    `json:"last,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// HistoryPage is a page of history, oldest record first
+type HistoryPage struct {
+	Records []Record `json:"records"`
+	Next    uint64   `json:"next"` // Next is the Since of the query of the next page
+	More    bool     `json:"more"` // More reports whether the query selected records after the page
+}
+
+// history keeps the last size records written to the room, and none older than age (if set)
+type history struct {
+	records []Record // records is a ring of up to size records; start is the oldest and n the number kept
+	start   int
+	n       int
+	size    int
+	age     time.Duration
+	seq     uint64
+	now     func() time.Time
+	mux     sync.Mutex
+}
+
+func newHistory(size int, age time.Duration) *history {
+	return &history{
+		size: size,
+		age:  age,
+		now:  time.Now,
+	}
+}
+
+// grow enlarges the ring, up to the size of the history, keeping the records in order
+func (h *history) grow() {
+	records := make([]Record, min(h.size, max(2*len(h.records), minHistoryCapacity)))
+	for index := 0; index < h.n; index++ {
+		records[index] = h.records[(h.start+index)%len(h.records)]
+	}
+
+	h.records, h.start = records, 0
+}
+
+// Sequenced is implemented by the messages which carry their sequence number in the room; the number is
+// set when the message is recorded, so that the receivers can tell where to resume the history from.
+type Sequenced interface {
+	SetSeq(uint64)
+}
+
+// add records the message of the sender to the receivers (none for a broadcast) and returns its sequence number
+func (h *history) add(from interceptor.ClientID, to []interceptor.ClientID, msg message.Message) (uint64, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if m, ok := msg.(Sequenced); ok {
+		m.SetSeq(h.seq + 1)
+	}
+
+	payload, err := message.Marshal(msg)
+	if err != nil {
+		return 0, fmt.Errorf("error while recording message in room history; err: %w", err)
+	}
+
+	now := h.now()
+	h.expire(now)
+
+	h.seq++
+	record := Record{
+		Seq:      h.seq,
+		From:     from,
+		To:       slices.Clone(to),
+		At:       now,
+		Protocol: msg.GetProtocol(),
+		Payload:  payload,
+	}
+
+	if h.n == len(h.records) && len(h.records) < h.size {
+		// NOTE: THE RING GROWS ON DEMAND; A LARGE HISTORY DOES NOT ALLOCATE ITS RECORDS UP FRONT
+		h.grow()
+	}
+
+	if h.n == len(h.records) {
+		// NOTE: FULL; THE OLDEST RECORD IS OVERWRITTEN
+		h.records[h.start] = record
+		h.start = (h.start + 1) % len(h.records)
+		return record.Seq, nil
+	}
+
+	h.records[(h.start+h.n)%len(h.records)] = record
+	h.n++
+
+	return record.Seq, nil
+}
+
+// expire forgets the records older than the age
+func (h *history) expire(now time.Time) {
+	if h.age <= 0 {
+		return
+	}
+
+	for h.n > 0 && now.Sub(h.records[h.start].At) > h.age {
+		h.records[h.start] = Record{}
+		h.start = (h.start + 1) % len(h.records)
+		h.n--
+	}
+}
+
+// page returns the page of the records visible to the participant selected by the query
+func (h *history) page(query HistoryQuery, receiver interceptor.ClientID) HistoryPage {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.expire(h.now())
+
+	selected := make([]Record, 0, h.n)
+	for index := 0; index < h.n; index++ {
+		if record := h.records[(h.start+index)%len(h.records)]; record.Seq > query.Since && record.visibleTo(receiver) {
+			selected = append(selected, record)
+		}
+	}
+
+	if query.Last > 0 && len(selected) > query.Last {
+		selected = selected[len(selected)-query.Last:]
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryPageSize
+	}
+	limit = min(limit, MaxHistoryPageSize)
+
+	page := HistoryPage{Records: make([]Record, 0), Next: query.Since}
+	if len(selected) > limit {
+		selected, page.More = selected[:limit], true
+	}
+
+	if len(selected) > 0 {
+		page.Records = selected
+		page.Next = selected[len(selected)-1].Seq
+	}
+
+	return page
+}
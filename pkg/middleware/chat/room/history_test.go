@@ -0,0 +1,231 @@
+package room_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/room"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+const (
+	roomID       types.RoomID     = "room"
+	textProtocol message.Protocol = "test:text"
+)
+
+type textMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+	Seq  uint64 `json:"seq,omitempty"`
+}
+
+func (m *textMessage) GetProtocol() message.Protocol {
+	return textProtocol
+}
+
+func (m *textMessage) SetSeq(seq uint64) {
+	m.Seq = seq
+}
+
+func newText(t *testing.T, text string) *textMessage {
+	t.Helper()
+
+	msg := &textMessage{Text: text}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+
+	msg.BaseMessage = bmsg
+	return msg
+}
+
+func newRoom(t *testing.T, options ...room.Option) (*room.Room, map[interceptor.ClientID]*interceptortest.State) {
+	t.Helper()
+
+	r, err := room.NewRoom(context.Background(), roomID, nil, time.Minute, options...)
+	if err != nil {
+		t.Fatalf("NewRoom() error = %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	states := make(map[interceptor.ClientID]*interceptortest.State)
+	for _, id := range []interceptor.ClientID{"alice", "bob", "carol"} {
+		s := interceptortest.NewStateWithID(context.Background(), id)
+		if err := r.Add(roomID, s); err != nil {
+			t.Fatalf("Add(%s) error = %v", id, err)
+		}
+		states[id] = s
+	}
+
+	return r, states
+}
+
+func broadcast(t *testing.T, r *room.Room, from interceptor.ClientID, texts ...string) {
+	t.Helper()
+
+	for _, text := range texts {
+		if err := r.WriteRoomMessage(roomID, newText(t, text), from); err != nil {
+			t.Fatalf("WriteRoomMessage() error = %v", err)
+		}
+	}
+}
+
+func seqs(page room.HistoryPage) []uint64 {
+	s := make([]uint64, 0, len(page.Records))
+	for _, record := range page.Records {
+		s = append(s, record.Seq)
+	}
+	return s
+}
+
+func TestRoom_BroadcastsAreRecorded(t *testing.T) {
+	r, states := newRoom(t, room.WithHistory(10, 0))
+
+	broadcast(t, r, "alice", "hello")
+	if err := r.WriteRoomMessage(roomID, newText(t, "private"), "alice", "bob"); err != nil {
+		t.Fatalf("WriteRoomMessage() error = %v", err)
+	}
+
+	if n := len(states["alice"].Writer().Messages()); n != 0 {
+		t.Errorf("sender received %d messages of its broadcast, want 0", n)
+	}
+	if n := len(states["bob"].Writer().Messages()); n != 2 {
+		t.Errorf("bob received %d messages, want the broadcast and the private message", n)
+	}
+	if n := len(states["carol"].Writer().Messages()); n != 1 {
+		t.Errorf("carol received %d messages, want the broadcast", n)
+	}
+
+	page, err := r.History(room.HistoryQuery{}, "carol")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	if len(page.Records) != 1 {
+		t.Fatalf("History() = %+v, want only the broadcast replayed to carol", page)
+	}
+
+	record := page.Records[0]
+	if record.Seq != 1 || record.From != "alice" || record.Protocol != textProtocol {
+		t.Errorf("record = %+v, want seq 1 from alice", record)
+	}
+
+	// NOTE: THE PRIVATE MESSAGE IS RECORDED, BUT REPLAYED ONLY TO ITS SENDER AND RECEIVER
+	for _, id := range []interceptor.ClientID{"alice", "bob"} {
+		page, _ := r.History(room.HistoryQuery{}, id)
+		if got := seqs(page); !slices.Equal(got, []uint64{1, 2}) {
+			t.Errorf("History(%s) seqs = %v, want the broadcast and the private message", id, got)
+		}
+		if to := page.Records[1].To; !slices.Equal(to, []interceptor.ClientID{"bob"}) {
+			t.Errorf("private record to = %v, want bob", to)
+		}
+	}
+
+	// NOTE: THE DELIVERED MESSAGE CARRIES ITS SEQUENCE NUMBER
+	if got := states["bob"].Writer().Messages()[0].(*textMessage).Seq; got != 1 {
+		t.Errorf("delivered message seq = %d, want 1", got)
+	}
+}
+
+func TestRoom_HistoryDisabled(t *testing.T) {
+	r, _ := newRoom(t)
+	broadcast(t, r, "alice", "hello")
+
+	if _, err := r.History(room.HistoryQuery{}, "alice"); err == nil {
+		t.Error("History() error = nil, want an error for a room without history")
+	}
+}
+
+func TestRoom_HistoryLimits(t *testing.T) {
+	r, _ := newRoom(t, room.WithHistory(3, 100*time.Millisecond))
+
+	broadcast(t, r, "alice", "1", "2", "3", "4")
+
+	page, err := r.History(room.HistoryQuery{}, "alice")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if got := seqs(page); !slices.Equal(got, []uint64{2, 3, 4}) {
+		t.Errorf("History() seqs = %v, want the last 3", got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	broadcast(t, r, "bob", "5")
+
+	page, _ = r.History(room.HistoryQuery{}, "alice")
+	if got := seqs(page); !slices.Equal(got, []uint64{5}) {
+		t.Errorf("History() seqs = %v, want the records older than the age forgotten", got)
+	}
+}
+
+func TestRoom_HistoryPages(t *testing.T) {
+	r, _ := newRoom(t, room.WithHistory(100, 0))
+	broadcast(t, r, "alice", "1", "2", "3", "4", "5", "6", "7")
+
+	tests := []struct {
+		name  string
+		query room.HistoryQuery
+		want  []uint64
+		next  uint64
+		more  bool
+	}{
+		{name: "everything", query: room.HistoryQuery{}, want: []uint64{1, 2, 3, 4, 5, 6, 7}, next: 7},
+		{name: "since", query: room.HistoryQuery{Since: 5}, want: []uint64{6, 7}, next: 7},
+		{name: "last", query: room.HistoryQuery{Last: 3}, want: []uint64{5, 6, 7}, next: 7},
+		{name: "first page", query: room.HistoryQuery{Limit: 3}, want: []uint64{1, 2, 3}, next: 3, more: true},
+		{name: "next page", query: room.HistoryQuery{Since: 3, Limit: 3}, want: []uint64{4, 5, 6}, next: 6, more: true},
+		{name: "last page", query: room.HistoryQuery{Since: 6, Limit: 3}, want: []uint64{7}, next: 7},
+		{name: "last paged", query: room.HistoryQuery{Last: 4, Limit: 2}, want: []uint64{4, 5}, next: 5, more: true},
+		{name: "up to date", query: room.HistoryQuery{Since: 7}, want: []uint64{}, next: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := r.History(tt.query, "alice")
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+
+			if got := seqs(page); !slices.Equal(got, tt.want) || page.Next != tt.next || page.More != tt.more {
+				t.Errorf("History(%+v) = %v next %d more %v; want %v next %d more %v", tt.query, got, page.Next, page.More, tt.want, tt.next, tt.more)
+			}
+		})
+	}
+}
+
+func TestWithHistory_Validate(t *testing.T) {
+	for name, option := range map[string]room.Option{
+		"size":     room.WithHistory(0, time.Minute),
+		"max size": room.WithHistory(room.MaxHistorySize+1, time.Minute),
+		"age":      room.WithHistory(10, -time.Second),
+	} {
+		if _, err := room.NewRoom(context.Background(), roomID, nil, time.Minute, option); err == nil {
+			t.Errorf("%s: NewRoom() error = nil, want an error", name)
+		}
+	}
+}
+
+func TestRoom_HistoryGrows(t *testing.T) {
+	r, _ := newRoom(t, room.WithHistory(room.MaxHistorySize, 0))
+
+	texts := make([]string, 40)
+	for index := range texts {
+		texts[index] = "text"
+	}
+	broadcast(t, r, "alice", texts...)
+
+	page, err := r.History(room.HistoryQuery{Last: 3}, "bob")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if got := seqs(page); !slices.Equal(got, []uint64{38, 39, 40}) {
+		t.Errorf("History() seqs = %v, want the last 3 of the 40 kept", got)
+	}
+}
@@ -3,6 +3,7 @@ package room
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
@@ -20,22 +21,48 @@ type Room struct {
 	isHealthTracked bool
 	cancel          context.CancelFunc
 	ctx             context.Context
+	history         *history     // history is nil unless the room keeps one (see WithHistory)
+	mux             sync.RWMutex // guards participants
+}
+
+type Option = func(*Room) error
+
+// WithHistory makes the room keep the last size messages written in it (see WriteRoomMessage), and none
+// older than age; a zero age keeps them for the lifetime of the room. The participants joining later can
+// be replayed the history, and can fetch it page by page (see History). The size is at most MaxHistorySize.
+func WithHistory(size int, age time.Duration) Option {
+	return func(r *Room) error {
+		if size <= 0 || size > MaxHistorySize {
+			return fmt.Errorf("room history size must be between 1 and %d; got %d", MaxHistorySize, size)
+		}
+
+		if age < 0 {
+			return fmt.Errorf("room history age must not be negative; got %s", age)
+		}
+
+		r.history = newHistory(size, age)
+		return nil
+	}
 }
 
-// TODO: DO I NEED MUX HERE?
-// UPDATE: YES
 // TODO: ADD SOME VALIDATION BEFORE CREATING THE ROOM
 
-func NewRoom(ctx context.Context, id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration) *Room {
-	ctx2, cancel := context.WithTimeout(ctx, ttl)
-	return &Room{
-		ctx:          ctx2,
-		cancel:       cancel,
+func NewRoom(ctx context.Context, id types.RoomID, allowed []interceptor.ClientID, ttl time.Duration, options ...Option) (*Room, error) {
+	r := &Room{
 		ttl:          ttl,
 		roomid:       id,
 		allowed:      allowed,
 		participants: make(map[interceptor.ClientID]interceptor.State),
 	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	r.ctx, r.cancel = context.WithTimeout(ctx, ttl)
+	return r, nil
 }
 
 func (r *Room) Ctx() context.Context {
@@ -68,6 +95,9 @@ func (r *Room) Add(roomid types.RoomID, s interceptor.State) error {
 			return fmt.Errorf("error while adding client to room. client id: %s; room id: %s; err: %s", id, r.roomid, errors.ErrClientNotAllowed.Error())
 		}
 
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
 		if r.isParticipant(id) {
 			return fmt.Errorf("client with id '%s' already existing in the room with id %s; err: %s", id, r.roomid, errors.ErrClientIsAlreadyParticipant)
 		}
@@ -128,6 +158,9 @@ func (r *Room) Remove(roomid types.RoomID, s interceptor.State) error {
 			return fmt.Errorf("error while removing client to room. client id: %s; room id: %s; err: %s", id, r.roomid, errors.ErrClientNotAllowed.Error())
 		}
 
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
 		if !r.isParticipant(id) {
 			return fmt.Errorf("client with id '%s' does not exist in the room with id %s; err: %s", id, r.roomid, errors.ErrClientNotAParticipant.Error())
 		}
@@ -137,6 +170,14 @@ func (r *Room) Remove(roomid types.RoomID, s interceptor.State) error {
 	}
 }
 
+// IsParticipant reports whether the client is currently a participant of the room
+func (r *Room) IsParticipant(id interceptor.ClientID) bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.isParticipant(id)
+}
+
 func (r *Room) isParticipant(id interceptor.ClientID) bool {
 	select {
 	case <-r.ctx.Done():
@@ -147,6 +188,11 @@ func (r *Room) isParticipant(id interceptor.ClientID) bool {
 	}
 }
 
+// WriteRoomMessage writes the message of the participant to the given participants of the room or, without
+// receivers, broadcasts it to every other participant.
+// NOTE: WITHOUT RECEIVERS THE MESSAGE IS BROADCAST; BEFORE ROOM HISTORY IT WAS AN ERROR
+// The messages are recorded in the history of the room, if it keeps one; the messages to given participants
+// are replayed only to their sender and receivers (see History).
 func (r *Room) WriteRoomMessage(roomid types.RoomID, msg message.Message, from interceptor.ClientID, tos ...interceptor.ClientID) error {
 	select {
 	case <-r.ctx.Done():
@@ -156,20 +202,36 @@ func (r *Room) WriteRoomMessage(roomid types.RoomID, msg message.Message, from i
 			return errors.ErrWrongRoom
 		}
 
-		if len(tos) == 0 {
-			return fmt.Errorf("atleast one receiver is need to use 'WriteRoomMessage' message")
-		}
-
 		if !r.forEachBoolean(r.isAllowed, append(tos, from)...) {
 			return errors.ErrClientNotAllowed
 		}
 
+		r.mux.RLock()
+		defer r.mux.RUnlock()
+
 		if !r.forEachBoolean(r.isParticipant, append(tos, from)...) {
 			return errors.ErrClientNotAParticipant
 		}
 
+		if r.history != nil {
+			if _, err := r.history.add(from, tos, msg); err != nil {
+				return err
+			}
+		}
+
+		if len(tos) == 0 {
+			for id := range r.participants {
+				if id != from {
+					tos = append(tos, id)
+				}
+			}
+		}
+
+		// NOTE: EVERY SENDER GETS ITS OWN LANE SO THAT FORWARDED STREAMS DO NOT DELAY THE OTHER MESSAGES OF THE RECEIVER
+		ctx := interceptor.WithLane(r.ctx, interceptor.Lane("room:"+string(r.roomid)+":"+string(from)))
+
 		for _, to := range tos {
-			if err := r.participants[to].Write(r.ctx, msg); err != nil {
+			if err := r.participants[to].Write(ctx, msg); err != nil {
 				return fmt.Errorf("error while sending message to peer in room; err: %s", err.Error())
 			}
 		}
@@ -178,6 +240,46 @@ func (r *Room) WriteRoomMessage(roomid types.RoomID, msg message.Message, from i
 	}
 }
 
+// Notify writes the message to every participant of the room. Unlike WriteRoomMessage, the message is
+// not written on behalf of a participant; it is used for the room's own notifications.
+func (r *Room) Notify(msg message.Message) error {
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("error while notifying participants in room; err: %s", interceptor.ErrContextCancelled.Error())
+	default:
+		r.mux.RLock()
+		defer r.mux.RUnlock()
+
+		ctx := interceptor.WithLane(r.ctx, interceptor.Lane("room:"+string(r.roomid)))
+
+		for id, participant := range r.participants {
+			if err := participant.Write(ctx, msg); err != nil {
+				return fmt.Errorf("error while notifying participant %s in room; err: %s", id, err.Error())
+			}
+		}
+
+		return nil
+	}
+}
+
+// History returns the page of the history of the room selected by the query, of the records visible to the
+// receiver: the broadcasts, and the messages the receiver sent or was sent.
+// Returns:
+//   - HistoryPage: the records of the page, oldest first
+//   - error: nil if successful, ErrRoomHistoryDisabled if the room keeps no history
+func (r *Room) History(query HistoryQuery, receiver interceptor.ClientID) (HistoryPage, error) {
+	select {
+	case <-r.ctx.Done():
+		return HistoryPage{}, fmt.Errorf("error while reading room history. room id: %s; err: %s", r.roomid, interceptor.ErrContextCancelled.Error())
+	default:
+		if r.history == nil {
+			return HistoryPage{}, fmt.Errorf("error while reading room history. room id: %s; err: %s", r.roomid, errors.ErrRoomHistoryDisabled.Error())
+		}
+
+		return r.history.page(query, receiver), nil
+	}
+}
+
 func (r *Room) StartHealthTracking(roomid types.RoomID) error {
 	select {
 	case <-r.ctx.Done():
@@ -219,6 +321,9 @@ func (r *Room) GetParticipants() []interceptor.ClientID {
 	case <-r.ctx.Done():
 		return make([]interceptor.ClientID, 0) // EMPTY LIST
 	default:
+		r.mux.RLock()
+		defer r.mux.RUnlock()
+
 		clients := make([]interceptor.ClientID, 0)
 		for id := range r.participants {
 			clients = append(clients, id)
@@ -233,6 +338,10 @@ func (r *Room) GetAllowed() []interceptor.ClientID {
 
 func (r *Room) Close() error {
 	r.cancel()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
 	r.participants = make(map[interceptor.ClientID]interceptor.State)
 	r.allowed = make([]interceptor.ClientID, 0)
 	return nil
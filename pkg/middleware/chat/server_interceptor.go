@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/interfaces"
 	"github.com/harshabose/socket-comm/pkg/middleware/chat/process"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/state"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
 )
 
 type ServerInterceptor struct {
@@ -36,10 +39,67 @@ func (i *ServerInterceptor) Init(connection interceptor.Connection) error {
 	return nil
 }
 
+// UnBindSocketConnection removes the client from all its rooms, notifying the remaining participants unless
+// the leave notification is disabled (see WithoutLeaveNotification), stops its health snapshot streamers and
+// removes its state.
 func (i *ServerInterceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	s, err := i.GetState(connection)
+	if err != nil {
+		return
+	}
+
+	if h, ok := i.Health.(interfaces.CanRemoveAllHealthSnapshotStreamers); ok {
+		if err := h.RemoveAllHealthSnapshotStreamers(s); err != nil {
+			fmt.Println("error while removing health snapshot streamers; err:", err.Error())
+		}
+	}
+
+	if r, ok := i.Rooms.(interfaces.CanLeaveAll); ok {
+		left, err := r.LeaveAll(s)
+		if err != nil {
+			fmt.Println("error while leaving rooms; err:", err.Error())
+		}
 
+		i.notifyLeft(s, left)
+	}
+
+	i.commonInterceptor.UnBindSocketConnection(connection)
+}
+
+// notifyLeft notifies the remaining participants of the rooms the client was removed from
+func (i *ServerInterceptor) notifyLeft(s *state.State, left []types.RoomID) {
+	if i.leaveNotification == nil || len(left) == 0 {
+		return
+	}
+
+	id, err := s.GetClientID()
+	if err != nil {
+		return
+	}
+
+	g, ok := i.Rooms.(interfaces.CanGetRoom)
+	if !ok {
+		return
+	}
+
+	for _, roomid := range left {
+		r, err := g.GetRoom(roomid)
+		if err != nil || r == nil {
+			continue
+		}
+
+		msg, err := i.leaveNotification(roomid, id)()
+		if err != nil {
+			fmt.Println("error while creating leave notification; err:", err.Error())
+			continue
+		}
+
+		if err := r.Notify(msg); err != nil {
+			fmt.Println("error while sending leave notification; err:", err.Error())
+		}
+	}
 }
 
 func (i *ServerInterceptor) Close() error {
-	return nil
+	return i.unBindAll(i.UnBindSocketConnection)
 }
@@ -0,0 +1,293 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/processors"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/state"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat/types"
+)
+
+const leftProtocol message.Protocol = "test:left"
+
+type leftMessage struct {
+	interceptor.BaseMessage
+	RoomID   types.RoomID         `json:"room_id"`
+	ClientID interceptor.ClientID `json:"client_id"`
+}
+
+func (m *leftMessage) GetProtocol() message.Protocol {
+	return leftProtocol
+}
+
+func newLeftMessageFactory(id types.RoomID, clientID interceptor.ClientID) func() (message.Message, error) {
+	return func() (message.Message, error) {
+		msg := &leftMessage{RoomID: id, ClientID: clientID}
+
+		bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.BaseMessage = bmsg
+		return msg, nil
+	}
+}
+
+// streamer is a background process which only records that it was stopped
+type streamer struct {
+	stopped atomic.Bool
+}
+
+func (s *streamer) ProcessBackground(_ context.Context, _ interceptor.CanProcessBackground, _ interceptor.State) interceptor.CanBeProcessedBackground {
+	return s
+}
+
+func (s *streamer) Wait() error {
+	return nil
+}
+
+func (s *streamer) Stop() {
+	s.stopped.Store(true)
+}
+
+type client struct {
+	connection *interceptortest.Connection
+	writer     *interceptortest.Writer
+	state      *state.State
+	streamer   *streamer
+}
+
+func newServerInterceptor(ctx context.Context) *ServerInterceptor {
+	return &ServerInterceptor{
+		commonInterceptor: &commonInterceptor{
+			NoOpInterceptor:      interceptor.NewNoOpInterceptor(ctx, "server", message.NewDefaultRegistry()),
+			readProcessMessages:  message.NewDefaultRegistry(),
+			writeProcessMessages: message.NewDefaultRegistry(),
+			states:               state.NewManager(),
+			leaveNotification:    newLeftMessageFactory,
+		},
+		Rooms:  processors.NewRoomProcessor(ctx),
+		Health: processors.NewHealthProcessor(ctx),
+	}
+}
+
+// join binds a client and makes it a participant, with a snapshot streamer, of the given rooms
+func join(t *testing.T, i *ServerInterceptor, id interceptor.ClientID, roomids ...types.RoomID) *client {
+	t.Helper()
+
+	c := &client{
+		connection: interceptortest.NewConnection(string(id)),
+		writer:     interceptortest.NewWriter(),
+		streamer:   &streamer{},
+	}
+
+	interceptortest.Bind(t, i, c.connection, c.writer, interceptortest.NewReader())
+
+	s, err := i.GetState(c.connection)
+	if err != nil {
+		t.Fatalf("GetState() error = %v", err)
+	}
+	if err := s.SetClientID(id); err != nil {
+		t.Fatalf("SetClientID() error = %v", err)
+	}
+	c.state = s
+
+	rooms := i.Rooms.(*processors.RoomManager)
+	health := i.Health.(*processors.Health)
+
+	for _, roomid := range roomids {
+		if err := rooms.Add(roomid, s); err != nil {
+			t.Fatalf("Add(%s) error = %v", roomid, err)
+		}
+		if err := health.Add(roomid, id); err != nil {
+			t.Fatalf("health Add(%s) error = %v", roomid, err)
+		}
+		if err := health.AddHealthSnapshotStreamer(roomid, s, c.streamer); err != nil {
+			t.Fatalf("AddHealthSnapshotStreamer(%s) error = %v", roomid, err)
+		}
+	}
+
+	return c
+}
+
+func createRooms(t *testing.T, i *ServerInterceptor, roomids ...types.RoomID) {
+	t.Helper()
+
+	for _, roomid := range roomids {
+		if _, err := i.Rooms.(*processors.RoomManager).CreateRoom(roomid, nil, time.Minute); err != nil {
+			t.Fatalf("CreateRoom(%s) error = %v", roomid, err)
+		}
+		if _, err := i.Health.(*processors.Health).CreateHealth(roomid, nil, time.Minute); err != nil {
+			t.Fatalf("CreateHealth(%s) error = %v", roomid, err)
+		}
+	}
+}
+
+func participants(t *testing.T, i *ServerInterceptor, roomid types.RoomID) []interceptor.ClientID {
+	t.Helper()
+
+	r, err := i.Rooms.(*processors.RoomManager).GetRoom(roomid)
+	if err != nil {
+		t.Fatalf("GetRoom(%s) error = %v", roomid, err)
+	}
+
+	return r.GetParticipants()
+}
+
+func TestServerInterceptorUnBindTearsDown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	i := newServerInterceptor(ctx)
+	createRooms(t, i, "room-a", "room-b")
+
+	alice := join(t, i, "alice", "room-a", "room-b")
+	bob := join(t, i, "bob", "room-a")
+	carol := join(t, i, "carol", "room-b")
+
+	i.UnBindSocketConnection(alice.connection)
+
+	if _, err := i.GetState(alice.connection); err == nil {
+		t.Error("GetState() after unbind: want error, got nil")
+	}
+	if alice.state.Ctx().Err() == nil {
+		t.Error("state context not cancelled after unbind")
+	}
+	if !alice.streamer.stopped.Load() {
+		t.Error("snapshot streamer not stopped after unbind")
+	}
+	if bob.streamer.stopped.Load() || carol.streamer.stopped.Load() {
+		t.Error("snapshot streamers of other clients stopped")
+	}
+
+	if got := participants(t, i, "room-a"); len(got) != 1 || got[0] != "bob" {
+		t.Errorf("room-a participants = %v, want [bob]", got)
+	}
+	if got := participants(t, i, "room-b"); len(got) != 1 || got[0] != "carol" {
+		t.Errorf("room-b participants = %v, want [carol]", got)
+	}
+
+	for name, c := range map[string]*client{"bob": bob, "carol": carol} {
+		msgs := c.writer.Messages()
+		if len(msgs) != 1 {
+			t.Fatalf("%s received %d messages, want 1 leave notification", name, len(msgs))
+		}
+
+		left, ok := msgs[0].(*leftMessage)
+		if !ok {
+			t.Fatalf("%s received %T, want *leftMessage", name, msgs[0])
+		}
+		if left.ClientID != "alice" {
+			t.Errorf("%s notified of %s leaving, want alice", name, left.ClientID)
+		}
+	}
+
+	if n := len(alice.writer.Messages()); n != 0 {
+		t.Errorf("disconnected client received %d messages, want 0", n)
+	}
+}
+
+func TestServerInterceptorNoLeaks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	i := newServerInterceptor(ctx)
+	createRooms(t, i, "room")
+
+	clients := make([]*client, 0, 50)
+	for n := 0; n < cap(clients); n++ {
+		clients = append(clients, join(t, i, interceptor.ClientID(fmt.Sprintf("client-%d", n)), "room"))
+	}
+
+	for _, c := range clients {
+		i.UnBindSocketConnection(c.connection)
+	}
+
+	if n := i.states.Len(); n != 0 {
+		t.Errorf("%d states left after unbinding every connection", n)
+	}
+	if got := participants(t, i, "room"); len(got) != 0 {
+		t.Errorf("participants left after unbinding every connection: %v", got)
+	}
+	for _, c := range clients {
+		if !c.streamer.stopped.Load() {
+			t.Fatalf("snapshot streamer of %s not stopped", c.connection.ID())
+		}
+	}
+}
+
+func TestServerInterceptorClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	i := newServerInterceptor(ctx)
+	createRooms(t, i, "room")
+
+	clients := []*client{join(t, i, "alice", "room"), join(t, i, "bob", "room")}
+
+	if err := i.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if n := i.states.Len(); n != 0 {
+		t.Errorf("%d states left after close", n)
+	}
+	if got := participants(t, i, "room"); len(got) != 0 {
+		t.Errorf("participants left after close: %v", got)
+	}
+	for _, c := range clients {
+		if c.state.Ctx().Err() == nil {
+			t.Errorf("state context of %s not cancelled after close", c.connection.ID())
+		}
+	}
+}
+
+func TestUnBindUnidentifiedConnection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	i := newServerInterceptor(ctx)
+	connection := interceptortest.NewConnection("anonymous")
+	interceptortest.Bind(t, i, connection, interceptortest.NewWriter(), interceptortest.NewReader())
+
+	i.UnBindSocketConnection(connection)
+
+	if _, err := i.GetState(connection); err == nil {
+		t.Error("GetState() after unbind: want error, got nil")
+	}
+
+	// NOTE: UNBINDING TWICE (AS THE CHAIN MAY ON CLOSE) IS A NO-OP
+	i.UnBindSocketConnection(connection)
+}
+
+func TestInterceptorFactoryLeaveNotification(t *testing.T) {
+	previous := defaultLeaveNotification
+	SetDefaultLeaveNotification(newLeftMessageFactory)
+	t.Cleanup(func() { SetDefaultLeaveNotification(previous) })
+
+	for _, tt := range []struct {
+		name    string
+		options []Option
+		want    bool
+	}{
+		{name: "default", want: true},
+		{name: "disabled", options: []Option{WithoutLeaveNotification}, want: false},
+	} {
+		i, err := NewInterceptorFactory(tt.options...).NewInterceptor(context.Background(), "server", message.NewDefaultRegistry())
+		if err != nil {
+			t.Fatalf("%s: NewInterceptor() error = %v", tt.name, err)
+		}
+
+		if got := i.(*commonInterceptor).leaveNotification != nil; got != tt.want {
+			t.Errorf("%s: leave notification set = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
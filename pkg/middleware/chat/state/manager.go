@@ -5,7 +5,6 @@ import (
 
 	"github.com/harshabose/socket-comm/internal/util"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
-	"github.com/harshabose/socket-comm/pkg/middleware/chat/errors"
 )
 
 type Manager struct {
@@ -25,7 +24,7 @@ func (m *Manager) GetState(connection interceptor.Connection) (*State, error) {
 
 	s, exists := m.states[connection]
 	if !exists {
-		return nil, errors.ErrConnectionNotFound
+		return nil, interceptor.ErrConnectionNotFound
 	}
 
 	return s, nil
@@ -36,7 +35,7 @@ func (m *Manager) SetState(connection interceptor.Connection, s *State) error {
 	defer m.mux.Unlock()
 
 	if _, exists := m.states[connection]; exists {
-		return errors.ErrConnectionExists
+		return interceptor.ErrConnectionExists
 	}
 
 	m.states[connection] = s
@@ -50,7 +49,7 @@ func (m *Manager) RemoveState(connection interceptor.Connection) error {
 
 	_, exists := m.states[connection]
 	if !exists {
-		return errors.ErrConnectionNotFound
+		return interceptor.ErrConnectionNotFound
 	}
 
 	delete(m.states, connection)
@@ -71,3 +70,11 @@ func (m *Manager) ForEach(fn func(connection interceptor.Connection, state *Stat
 
 	return errs.ErrorOrNil()
 }
+
+// Len returns the number of states in the manager
+func (m *Manager) Len() int {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	return len(m.states)
+}
@@ -50,5 +50,16 @@ func (s *State) SetClientID(id interceptor.ClientID) error {
 	}
 
 	s.id = id
+	interceptor.Set(s.Attributes(), interceptor.ClientIDKey, id)
+
 	return nil
 }
+
+// Close cancels the state's context; the background processes bound to it stop
+func (s *State) Close() {
+	s.cancel()
+}
+
+func (s *State) Attributes() *interceptor.Attributes {
+	return interceptor.AttributesOf(s.connection)
+}
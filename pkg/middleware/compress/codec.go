@@ -0,0 +1,117 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Algorithm names a compression format in the offers exchanged with the peer
+type Algorithm string
+
+const (
+	// AlgorithmGzip is the gzip format (RFC 1952)
+	AlgorithmGzip Algorithm = "gzip"
+	// AlgorithmDeflate is DEFLATE in the zlib format (RFC 1950), as the "deflate" content coding of HTTP
+	AlgorithmDeflate Algorithm = "deflate"
+)
+
+// Codec compresses and decompresses the serialized messages with an algorithm. Other algorithms (for
+// example, zstd) can be plugged in with WithCodec.
+// NOTE: THE COMPRESSED DATA IS CARRIED AS AN OPAQUE message.Payload AND MUST NEVER BE A VALID JSON DOCUMENT;
+// NOTE: FRAMED FORMATS (GZIP, ZLIB, ZSTD) START WITH A MAGIC NUMBER AND NEVER ARE
+type Codec interface {
+	Algorithm() Algorithm
+	Compress(data []byte) ([]byte, error)
+	// Decompress fails with ErrDecompressedTooLong if the data decompresses to more than limit bytes
+	Decompress(data []byte, limit int) ([]byte, error)
+}
+
+// streamCodec implements Codec over the writers and readers of the standard library
+type streamCodec struct {
+	algorithm Algorithm
+	writers   sync.Pool
+	newReader func(io.Reader) (io.ReadCloser, error)
+}
+
+type resettableWriter interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+func newGzipCodec(level int) (Codec, error) {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, fmt.Errorf("error while creating gzip codec; err: %w", err)
+	}
+
+	return &streamCodec{
+		algorithm: AlgorithmGzip,
+		writers: sync.Pool{New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			return w
+		}},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	}, nil
+}
+
+func newDeflateCodec(level int) (Codec, error) {
+	if _, err := zlib.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, fmt.Errorf("error while creating deflate codec; err: %w", err)
+	}
+
+	return &streamCodec{
+		algorithm: AlgorithmDeflate,
+		writers: sync.Pool{New: func() any {
+			w, _ := zlib.NewWriterLevel(io.Discard, level)
+			return w
+		}},
+		newReader: zlib.NewReader,
+	}, nil
+}
+
+func (c *streamCodec) Algorithm() Algorithm {
+	return c.algorithm
+}
+
+func (c *streamCodec) Compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	w := c.writers.Get().(resettableWriter)
+	defer c.writers.Put(w)
+	w.Reset(&buffer)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (c *streamCodec) Decompress(data []byte, limit int) ([]byte, error) {
+	r, err := c.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// NOTE: ONE BYTE MORE THAN THE LIMIT IS READ TO TELL A MESSAGE OF EXACTLY THE LIMIT FROM A LONGER ONE
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out) > limit {
+		return nil, ErrDecompressedTooLong
+	}
+
+	return out, nil
+}
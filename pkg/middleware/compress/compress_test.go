@@ -0,0 +1,324 @@
+package compress
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/chat"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt"
+	"github.com/harshabose/socket-comm/pkg/middleware/fragment"
+)
+
+const (
+	textProtocol  message.Protocol = "test:text"
+	otherProtocol message.Protocol = "test:other"
+)
+
+type textMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *textMessage) GetProtocol() message.Protocol {
+	return textProtocol
+}
+
+type otherMessage struct {
+	textMessage
+}
+
+func (m *otherMessage) GetProtocol() message.Protocol {
+	return otherProtocol
+}
+
+func newText(t *testing.T, text string) *textMessage {
+	t.Helper()
+
+	msg := &textMessage{Text: text}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+
+	msg.BaseMessage = bmsg
+	return msg
+}
+
+func newRegistry(t *testing.T) message.Registry {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+	if err := message.RegisterAll(registry, message.Type[textMessage](textProtocol), message.Type[otherMessage](otherProtocol)); err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+
+	return registry
+}
+
+// peer is one end of a connection bound to a compression interceptor
+type peer struct {
+	interceptor *Interceptor
+	connection  *interceptortest.Connection
+	w           *interceptortest.Writer
+	r           *interceptortest.Reader
+	writer      interceptor.Writer
+	reader      interceptor.Reader
+	registry    message.Registry
+}
+
+func newPeer(t *testing.T, options ...Option) *peer {
+	t.Helper()
+
+	registry := newRegistry(t)
+
+	i, err := NewInterceptorFactory(options...).NewInterceptor(context.Background(), "test", registry)
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	p := &peer{
+		interceptor: i.(*Interceptor),
+		connection:  interceptortest.NewConnection("test"),
+		w:           interceptortest.NewWriter(),
+		r:           interceptortest.NewReader(),
+		registry:    registry,
+	}
+	p.writer, p.reader = interceptortest.Bind(t, i, p.connection, p.w, p.r)
+
+	if err := i.Init(p.connection); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	return p
+}
+
+// send delivers the messages written by the peer to the other over the wire (serialized) and returns the
+// messages read by the other
+func (p *peer) send(t *testing.T, to *peer) []message.Message {
+	t.Helper()
+
+	for _, msg := range p.w.Messages() {
+		data, err := message.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		received, err := to.registry.Unmarshal(msg.GetProtocol(), data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", msg.GetProtocol(), err)
+		}
+
+		to.r.Queue(received)
+	}
+	p.w.Reset()
+
+	var msgs []message.Message
+	for {
+		msg, err := to.reader.Read(context.Background(), to.connection)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+
+		if msg == nil {
+			return msgs
+		}
+
+		msgs = append(msgs, msg)
+	}
+}
+
+// connect exchanges the offers of the peers
+func connect(t *testing.T, a, b *peer) {
+	t.Helper()
+
+	if msgs := a.send(t, b); len(msgs) != 0 {
+		t.Fatalf("offer of a read as %v, want it consumed", msgs)
+	}
+	if msgs := b.send(t, a); len(msgs) != 0 {
+		t.Fatalf("offer of b read as %v, want it consumed", msgs)
+	}
+}
+
+func write(t *testing.T, p *peer, msgs ...message.Message) {
+	t.Helper()
+
+	for _, msg := range msgs {
+		if err := p.writer.Write(context.Background(), p.connection, msg); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+}
+
+func TestInterceptor_RoundTrip(t *testing.T) {
+	a, b := newPeer(t), newPeer(t)
+	connect(t, a, b)
+
+	text := strings.Repeat("compressible ", 1000)
+	msg := newText(t, text)
+	msg.SetSender("alice")
+	msg.SetID("1")
+	write(t, a, msg)
+
+	written := a.w.Messages()
+	compressed, ok := written[0].(*Compressed)
+	if !ok {
+		t.Fatalf("written %T, want *Compressed", written[0])
+	}
+	if compressed.Algorithm != AlgorithmGzip {
+		t.Errorf("compressed with %s, want %s", compressed.Algorithm, AlgorithmGzip)
+	}
+	if header := compressed.GetCurrentHeader(); header.Sender != "alice" || header.ID != "1" {
+		t.Errorf("envelope header = %+v, want the sender and ID of the message", header)
+	}
+
+	read := a.send(t, b)
+	if len(read) != 1 {
+		t.Fatalf("read %d messages, want 1", len(read))
+	}
+
+	got, ok := read[0].(*textMessage)
+	if !ok || got.Text != text {
+		t.Fatalf("read %T, want the text message", read[0])
+	}
+
+	stats := a.interceptor.Stats()
+	if stats.Compressed != 1 || stats.BytesOut >= stats.BytesIn {
+		t.Errorf("Stats() = %+v, want 1 message compressed smaller", stats)
+	}
+	if stats := b.interceptor.Stats(); stats.Decompressed != 1 {
+		t.Errorf("Stats().Decompressed = %d, want 1", stats.Decompressed)
+	}
+}
+
+func TestInterceptor_Threshold(t *testing.T) {
+	a, b := newPeer(t, WithThreshold(4096)), newPeer(t)
+	connect(t, a, b)
+
+	write(t, a, newText(t, strings.Repeat("a", 1000)), newText(t, strings.Repeat("a", 5000)))
+
+	interceptortest.AssertProtocols(t, a.w.Messages(), textProtocol, CompressedProtocol)
+}
+
+func TestInterceptor_PlainUntilOffered(t *testing.T) {
+	a, b := newPeer(t), newPeer(t)
+
+	// NOTE: THE OFFER OF b IS NOT READ YET
+	a.w.Reset()
+	write(t, a, newText(t, strings.Repeat("a", 5000)))
+
+	interceptortest.AssertProtocols(t, a.w.Messages(), textProtocol)
+
+	b.send(t, a)
+	write(t, a, newText(t, strings.Repeat("a", 5000)))
+
+	interceptortest.AssertProtocols(t, a.w.Messages(), textProtocol, CompressedProtocol)
+}
+
+func TestInterceptor_Protocols(t *testing.T) {
+	a, b := newPeer(t, WithProtocols(otherProtocol)), newPeer(t)
+	connect(t, a, b)
+
+	other := &otherMessage{textMessage: *newText(t, strings.Repeat("a", 5000))}
+	other.CurrentProtocol = otherProtocol
+	write(t, a, newText(t, strings.Repeat("a", 5000)), other)
+
+	interceptortest.AssertProtocols(t, a.w.Messages(), textProtocol, CompressedProtocol)
+}
+
+func TestInterceptor_ProtocolAlgorithm(t *testing.T) {
+	a := newPeer(t, WithProtocolAlgorithm(textProtocol, AlgorithmDeflate))
+	b := newPeer(t)
+	only := newPeer(t, WithAlgorithms(AlgorithmGzip))
+	connect(t, a, b)
+
+	write(t, a, newText(t, strings.Repeat("a", 5000)))
+	if got := a.w.Messages()[0].(*Compressed).Algorithm; got != AlgorithmDeflate {
+		t.Errorf("compressed with %s, want %s", got, AlgorithmDeflate)
+	}
+
+	// NOTE: THE PEER NOT OFFERING THE ALGORITHM GETS THE MOST PREFERRED ONE IT OFFERED
+	c := newPeer(t, WithProtocolAlgorithm(textProtocol, AlgorithmDeflate))
+	connect(t, c, only)
+
+	write(t, c, newText(t, strings.Repeat("a", 5000)))
+	if got := c.w.Messages()[0].(*Compressed).Algorithm; got != AlgorithmGzip {
+		t.Errorf("compressed with %s, want %s", got, AlgorithmGzip)
+	}
+
+	if read := c.send(t, only); len(read) != 1 {
+		t.Errorf("read %d messages, want 1", len(read))
+	}
+}
+
+func TestInterceptor_MaxSize(t *testing.T) {
+	a, b := newPeer(t), newPeer(t, WithMaxSize(2048))
+	connect(t, a, b)
+
+	write(t, a, newText(t, strings.Repeat("a", 5000)))
+	b.r.Queue(a.w.Messages()...)
+
+	_, err := b.reader.Read(context.Background(), b.connection)
+	if !errors.Is(err, ErrDecompressedTooLong) {
+		t.Errorf("Read() error = %v, want %v", err, ErrDecompressedTooLong)
+	}
+}
+
+func TestInterceptor_UnknownAlgorithm(t *testing.T) {
+	a, b := newPeer(t), newPeer(t, WithAlgorithms(AlgorithmDeflate))
+	connect(t, a, b)
+
+	write(t, a, newText(t, strings.Repeat("a", 5000)))
+	compressed := a.w.Messages()[0].(*Compressed)
+	if compressed.Algorithm != AlgorithmDeflate {
+		t.Fatalf("compressed with %s, want the algorithm offered by the peer", compressed.Algorithm)
+	}
+
+	compressed.Algorithm = "zstd"
+	b.r.Queue(compressed)
+
+	_, err := b.reader.Read(context.Background(), b.connection)
+	interceptortest.AssertError(t, err, ErrUnknownAlgorithm)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	for name, option := range map[string]Option{
+		"threshold":          WithThreshold(0),
+		"max size":           WithMaxSize(-1),
+		"level":              WithLevel(42),
+		"algorithms":         WithAlgorithms(),
+		"unknown algorithm":  WithAlgorithms("zstd"),
+		"protocol algorithm": WithProtocolAlgorithm(textProtocol, "zstd"),
+	} {
+		if _, err := NewInterceptorFactory(option).NewInterceptor(context.Background(), "test", message.NewDefaultRegistry()); err == nil {
+			t.Errorf("%s: NewInterceptor() error = nil, want an error", name)
+		}
+	}
+}
+
+func TestInterceptorFactory_Descriptor(t *testing.T) {
+	registry := interceptor.NewRegistry()
+	registry.Register(fragment.NewInterceptorFactory())
+	registry.Register(encrypt.NewInterceptorFactory())
+	registry.Register(NewInterceptorFactory())
+	registry.Register(chat.NewInterceptorFactory())
+
+	// NOTE: STREAM, RATELIMIT AND DEDUP ARE NOT REGISTERED; THEIR CONSTRAINTS ARE OPTIONAL
+	got, err := registry.Order()
+	if err != nil {
+		t.Fatalf("Order() error = %v", err)
+	}
+
+	if want := []string{chat.InterceptorName, InterceptorName, encrypt.InterceptorName, fragment.InterceptorName}; !slices.Equal(got, want) {
+		t.Errorf("Order() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+package compress
+
+import "errors"
+
+var (
+	ErrNotBound            = errors.New("connection not bound to the compression interceptor")
+	ErrUnknownAlgorithm    = errors.New("unknown compression algorithm")
+	ErrDecompressedTooLong = errors.New("decompressed message longer than allowed")
+)
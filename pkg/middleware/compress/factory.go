@@ -0,0 +1,101 @@
+package compress
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// InterceptorName names the compression interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Compress
+
+// Descriptor places compress above encrypt and fragment, so that the messages are compressed before they
+// are encrypted (ciphertext does not compress) and split, and beneath the interceptors handling the
+// messages.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Before:   []string{names.Encrypt, names.Fragment},
+		After:    []string{names.Chat, names.Stream, names.RateLimit, names.Dedup},
+		Optional: []string{names.Encrypt, names.Fragment, names.Chat, names.Stream, names.RateLimit, names.Dedup},
+	}
+}
+
+// NewInterceptor creates the compression interceptor. The offers and the compressed envelopes are read by
+// the socket, so both ends register them in the registry of their socket (see RegisterMessages); the
+// decompressed messages are decoded with the given registry.
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		threshold:       DefaultThreshold,
+		maxSize:         DefaultMaxSize,
+		level:           DefaultLevel,
+		algorithms:      slices.Clone(DefaultAlgorithms),
+		codecs:          make(map[Algorithm]Codec),
+		protocols:       make(map[message.Protocol]struct{}),
+		perProtocol:     make(map[message.Protocol]Algorithm),
+		sessions:        make(map[interceptor.Connection]*session),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := i.buildCodecs(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// buildCodecs creates the built-in codecs of the offered algorithms not given with WithCodec
+func (i *Interceptor) buildCodecs() error {
+	for _, algorithm := range i.algorithms {
+		if _, exists := i.codecs[algorithm]; exists {
+			continue
+		}
+
+		var (
+			codec Codec
+			err   error
+		)
+
+		switch algorithm {
+		case AlgorithmGzip:
+			codec, err = newGzipCodec(i.level)
+		case AlgorithmDeflate:
+			codec, err = newDeflateCodec(i.level)
+		default:
+			return fmt.Errorf("error while creating codec of %s; err: %w", algorithm, ErrUnknownAlgorithm)
+		}
+		if err != nil {
+			return err
+		}
+
+		i.codecs[algorithm] = codec
+	}
+
+	for protocol, algorithm := range i.perProtocol {
+		if !slices.Contains(i.algorithms, algorithm) {
+			return fmt.Errorf("error while selecting %s for %s; err: %w", algorithm, protocol, ErrUnknownAlgorithm)
+		}
+	}
+
+	return nil
+}
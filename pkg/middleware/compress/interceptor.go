@@ -0,0 +1,305 @@
+package compress
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor compresses the written messages larger than the threshold into a Compressed envelope, and
+// decompresses the read envelopes. As it works on the messages, and not on the frames of a transport, it
+// works over every transport and, placed above encrypt, compresses the messages before they are encrypted.
+//
+// When a connection is initialised, both the peers offer the algorithms they decompress (see Offer). The
+// messages are written uncompressed until the offer of the peer is read, and with an algorithm the peer
+// offered afterwards; a peer without the interceptor never receives a compressed message.
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	threshold   int
+	maxSize     int
+	level       int
+	algorithms  []Algorithm
+	codecs      map[Algorithm]Codec
+	protocols   map[message.Protocol]struct{}
+	perProtocol map[message.Protocol]Algorithm
+	sessions    map[interceptor.Connection]*session
+	stats       counters
+	mux         sync.RWMutex
+}
+
+// session holds the algorithms offered by the peer of a connection
+type session struct {
+	writer interceptor.Writer
+	peer   []Algorithm // peer is nil until the offer of the peer is read
+	mux    sync.RWMutex
+}
+
+func (s *session) offered() []Algorithm {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	return s.peer
+}
+
+func (s *session) offer(algorithms []Algorithm) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.peer = slices.Clone(algorithms)
+}
+
+// Stats counts the messages compressed and decompressed by the interceptor
+type Stats struct {
+	Compressed   uint64 `json:"compressed"`
+	Decompressed uint64 `json:"decompressed"`
+	BytesIn      uint64 `json:"bytes_in"`  // BytesIn is the uncompressed size of the compressed messages
+	BytesOut     uint64 `json:"bytes_out"` // BytesOut is the compressed size of the compressed messages
+}
+
+type counters struct {
+	compressed   atomic.Uint64
+	decompressed atomic.Uint64
+	bytesIn      atomic.Uint64
+	bytesOut     atomic.Uint64
+}
+
+func (i *Interceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if _, exists := i.sessions[connection]; exists {
+		return nil, nil, interceptor.ErrConnectionExists
+	}
+
+	i.sessions[connection] = &session{writer: writer}
+
+	return writer, reader, nil
+}
+
+// Init offers the algorithms of the interceptor to the peer
+func (i *Interceptor) Init(connection interceptor.Connection) error {
+	s, err := i.getSession(connection)
+	if err != nil {
+		return err
+	}
+
+	offer, err := NewOffer(i.algorithms)
+	if err != nil {
+		return fmt.Errorf("error while creating compression offer; err: %w", err)
+	}
+	offer.SetSender(message.Sender(i.ID()))
+
+	if err := s.writer.Write(i.Ctx(), connection, offer); err != nil {
+		return fmt.Errorf("error while writing compression offer; err: %w", err)
+	}
+
+	return nil
+}
+
+func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		if msg == nil || !i.applies(msg.GetProtocol()) {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		s, err := i.getSession(connection)
+		if err != nil {
+			return err
+		}
+
+		codec := i.codecFor(msg.GetProtocol(), s.offered())
+		if codec == nil {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		compressed, err := i.compress(codec, msg)
+		if err != nil {
+			return fmt.Errorf("error while compressing message; err: %w", err)
+		}
+
+		if compressed == nil {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		return writer.Write(ctx, connection, compressed)
+	})
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			switch m := msg.(type) {
+			case *Offer:
+				s, err := i.getSession(connection)
+				if err != nil {
+					return nil, err
+				}
+
+				s.offer(m.Algorithms)
+				// NOTE: OFFER CONSUMED; KEEP READING
+				continue
+			case *Compressed:
+				decompressed, err := i.decompress(m)
+				if err != nil {
+					return nil, fmt.Errorf("error while decompressing message; err: %w", err)
+				}
+
+				return decompressed, nil
+			default:
+				return msg, nil
+			}
+		}
+	})
+}
+
+// applies reports whether the messages of the protocol are compressed; the messages of the interceptor
+// itself never are
+func (i *Interceptor) applies(protocol message.Protocol) bool {
+	if protocol == OfferProtocol || protocol == CompressedProtocol {
+		return false
+	}
+
+	if len(i.protocols) == 0 {
+		return true
+	}
+
+	_, ok := i.protocols[protocol]
+	return ok
+}
+
+// codecFor returns the codec of the algorithm selected for the protocol, if the peer offered it, or of the
+// most preferred algorithm the peer offered; nil if the peer offered none of the algorithms (or nothing yet)
+func (i *Interceptor) codecFor(protocol message.Protocol, peer []Algorithm) Codec {
+	if len(peer) == 0 {
+		return nil
+	}
+
+	if algorithm, exists := i.perProtocol[protocol]; exists && slices.Contains(peer, algorithm) {
+		return i.codecs[algorithm]
+	}
+
+	for _, algorithm := range i.algorithms {
+		if slices.Contains(peer, algorithm) {
+			return i.codecs[algorithm]
+		}
+	}
+
+	return nil
+}
+
+// compress returns the envelope of the compressed message, or nil if the message is not larger than the
+// threshold or does not get smaller
+func (i *Interceptor) compress(codec Codec, msg message.Message) (*Compressed, error) {
+	var (
+		data []byte
+		size int
+	)
+
+	if err := message.Encode(msg, func(encoded []byte) error {
+		size = len(encoded)
+		if size <= i.threshold {
+			return nil
+		}
+
+		compressed, err := codec.Compress(encoded)
+		if err != nil {
+			return err
+		}
+
+		data = compressed
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if data == nil || len(data) >= size {
+		return nil, nil
+	}
+
+	compressed, err := NewCompressed(msg, codec.Algorithm(), size, data)
+	if err != nil {
+		return nil, err
+	}
+
+	i.stats.compressed.Add(1)
+	i.stats.bytesIn.Add(uint64(size))
+	i.stats.bytesOut.Add(uint64(len(data)))
+
+	return compressed, nil
+}
+
+func (i *Interceptor) decompress(m *Compressed) (message.Message, error) {
+	codec, exists := i.codecs[m.Algorithm]
+	if !exists {
+		return nil, fmt.Errorf("error while selecting codec of %s; err: %w", m.Algorithm, ErrUnknownAlgorithm)
+	}
+
+	if m.Size > i.maxSize {
+		return nil, ErrDecompressedTooLong
+	}
+
+	compressed, err := m.NextPayload.Opaque()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := codec.Decompress(compressed, i.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := i.GetMessageRegistry().Unmarshal(m.NextProtocol, data)
+	if err != nil {
+		return nil, err
+	}
+
+	i.stats.decompressed.Add(1)
+	return msg, nil
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	delete(i.sessions, connection)
+}
+
+func (i *Interceptor) Close() error {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.sessions = make(map[interceptor.Connection]*session)
+	return nil
+}
+
+// Stats returns the counts of the messages compressed and decompressed by the interceptor
+func (i *Interceptor) Stats() Stats {
+	return Stats{
+		Compressed:   i.stats.compressed.Load(),
+		Decompressed: i.stats.decompressed.Load(),
+		BytesIn:      i.stats.bytesIn.Load(),
+		BytesOut:     i.stats.bytesOut.Load(),
+	}
+}
+
+func (i *Interceptor) getSession(connection interceptor.Connection) (*session, error) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	s, exists := i.sessions[connection]
+	if !exists {
+		return nil, ErrNotBound
+	}
+
+	return s, nil
+}
@@ -0,0 +1,105 @@
+package compress
+
+import (
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	OfferProtocol      message.Protocol = "compress:offer"
+	CompressedProtocol message.Protocol = "compress:compressed"
+)
+
+// Offer lists the algorithms the sender decompresses, in the order it prefers them. Both peers send their
+// offer when the connection is initialised; each compresses its messages with an algorithm of the other's
+// offer, and sends them uncompressed until the offer arrives.
+type Offer struct {
+	interceptor.BaseMessage
+	Algorithms []Algorithm `json:"algorithms"`
+}
+
+func NewOffer(algorithms []Algorithm) (*Offer, error) {
+	msg := &Offer{
+		Algorithms: algorithms,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Offer) GetProtocol() message.Protocol {
+	return OfferProtocol
+}
+
+func (m *Offer) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+// Compressed carries a compressed message as its next payload; NextProtocol is the protocol of the message.
+type Compressed struct {
+	interceptor.BaseMessage
+	Algorithm Algorithm `json:"algorithm"`
+	Size      int       `json:"size"` // Size is the length of the uncompressed serialized message
+}
+
+func NewCompressed(msg message.Message, algorithm Algorithm, size int, data []byte) (*Compressed, error) {
+	compressed := &Compressed{
+		Algorithm: algorithm,
+		Size:      size,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(msg.GetProtocol(), message.NewOpaquePayload(data), compressed)
+	if err != nil {
+		return nil, err
+	}
+	compressed.BaseMessage = bmsg
+
+	// NOTE: THE HEADER OF msg IS COMPRESSED; THE ENVELOPE CARRIES WHAT THE LAYERS BENEATH MAY NEED
+	header := msg.GetCurrentHeader()
+	compressed.SetSender(header.Sender)
+	compressed.SetReceiver(header.Receiver)
+	compressed.SetID(header.ID)
+	message.InheritExpiry(compressed, msg)
+	message.InheritTrace(compressed, msg)
+	interceptor.InheritPriority(compressed, msg)
+
+	return compressed, nil
+}
+
+func (m *Compressed) GetProtocol() message.Protocol {
+	return CompressedProtocol
+}
+
+func (m *Compressed) Validate() error {
+	if err := message.RequireNotEmpty("algorithm", m.Algorithm); err != nil {
+		return err
+	}
+
+	if err := message.RequirePositive("size", m.Size); err != nil {
+		return err
+	}
+
+	if m.NextProtocol == message.NoneProtocol || len(m.NextPayload) == 0 {
+		return message.NewValidationError("next", "the compressed message is missing")
+	}
+
+	return nil
+}
+
+// Registrations returns the compression messages paired with their protocols
+func Registrations() []message.Registration {
+	return []message.Registration{
+		message.Type[Offer](OfferProtocol),
+		message.Type[Compressed](CompressedProtocol),
+	}
+}
+
+// RegisterMessages registers the compression messages in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
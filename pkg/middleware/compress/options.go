@@ -0,0 +1,119 @@
+package compress
+
+import (
+	"compress/flate"
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	// DefaultThreshold is the serialized size above which a message is compressed; compressing smaller
+	// messages rarely pays for the envelope
+	DefaultThreshold = 1024
+	// DefaultMaxSize bounds the size a message decompresses to, so that a small message cannot expand
+	// without limit (a decompression bomb)
+	DefaultMaxSize = 16 << 20
+	// DefaultLevel is the compression level of the built-in codecs
+	DefaultLevel = flate.DefaultCompression
+)
+
+// DefaultAlgorithms are the algorithms offered to the peer, in the order they are preferred
+var DefaultAlgorithms = []Algorithm{AlgorithmGzip, AlgorithmDeflate}
+
+type Option = func(*Interceptor) error
+
+// WithThreshold sets the serialized size above which a message is compressed
+func WithThreshold(threshold int) Option {
+	return func(i *Interceptor) error {
+		if threshold <= 0 {
+			return fmt.Errorf("compression threshold must be positive; got %d", threshold)
+		}
+
+		i.threshold = threshold
+		return nil
+	}
+}
+
+// WithMaxSize sets the size a read message may decompress to; larger messages fail the read with
+// ErrDecompressedTooLong
+func WithMaxSize(size int) Option {
+	return func(i *Interceptor) error {
+		if size <= 0 {
+			return fmt.Errorf("compression max size must be positive; got %d", size)
+		}
+
+		i.maxSize = size
+		return nil
+	}
+}
+
+// WithLevel sets the compression level of the built-in codecs (see compress/flate); from
+// flate.HuffmanOnly to flate.BestCompression
+func WithLevel(level int) Option {
+	return func(i *Interceptor) error {
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			return fmt.Errorf("compression level must be between %d and %d; got %d", flate.HuffmanOnly, flate.BestCompression, level)
+		}
+
+		i.level = level
+		return nil
+	}
+}
+
+// WithAlgorithms sets the algorithms offered to the peer, in the order they are preferred. Algorithms other
+// than the built-in ones need their codec (see WithCodec).
+func WithAlgorithms(algorithms ...Algorithm) Option {
+	return func(i *Interceptor) error {
+		if len(algorithms) == 0 {
+			return fmt.Errorf("compression algorithms must not be empty")
+		}
+
+		i.algorithms = append([]Algorithm(nil), algorithms...)
+		return nil
+	}
+}
+
+// WithCodec adds a codec for another algorithm (for example, zstd), or replaces a built-in one. The
+// algorithm of the codec is offered to the peer after the algorithms set before.
+func WithCodec(codec Codec) Option {
+	return func(i *Interceptor) error {
+		if codec == nil {
+			return fmt.Errorf("compression codec must not be nil")
+		}
+
+		algorithm := codec.Algorithm()
+		i.codecs[algorithm] = codec
+
+		for _, a := range i.algorithms {
+			if a == algorithm {
+				return nil
+			}
+		}
+		i.algorithms = append(i.algorithms, algorithm)
+
+		return nil
+	}
+}
+
+// WithProtocols limits the compression to the messages of the given protocols; every message is compressed
+// by default
+func WithProtocols(protocols ...message.Protocol) Option {
+	return func(i *Interceptor) error {
+		for _, protocol := range protocols {
+			i.protocols[protocol] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// WithProtocolAlgorithm compresses the messages of the protocol with the given algorithm, if the peer
+// offered it, instead of the most preferred algorithm both the peers support; for example, a faster
+// algorithm for latency sensitive messages. The algorithm must be one of the offered algorithms.
+func WithProtocolAlgorithm(protocol message.Protocol, algorithm Algorithm) Option {
+	return func(i *Interceptor) error {
+		i.perProtocol[protocol] = algorithm
+		return nil
+	}
+}
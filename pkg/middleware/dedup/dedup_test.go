@@ -0,0 +1,285 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	roomProtocol    message.Protocol = "test:room"
+	controlProtocol message.Protocol = "test:control"
+)
+
+type testMessage struct {
+	interceptor.BaseMessage
+	protocol message.Protocol
+}
+
+func (m *testMessage) GetProtocol() message.Protocol {
+	return m.protocol
+}
+
+func newMessage(t *testing.T, protocol message.Protocol, sender message.Sender, id string) *testMessage {
+	t.Helper()
+
+	msg := &testMessage{protocol: protocol}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+
+	msg.BaseMessage = bmsg
+	msg.SetSender(sender)
+	msg.SetID(id)
+	return msg
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newInterceptor(t *testing.T, options ...Option) (*Interceptor, *clock) {
+	t.Helper()
+
+	i, err := NewInterceptorFactory(options...).NewInterceptor(context.Background(), "test", message.NewDefaultRegistry())
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	c := &clock{now: time.Unix(0, 0)}
+	d := i.(*Interceptor)
+	d.now = c.Now
+
+	return d, c
+}
+
+// drain reads every queued message and returns their IDs
+func drain(t *testing.T, reader interceptor.Reader, connection interceptor.Connection) []string {
+	t.Helper()
+
+	var ids []string
+	for {
+		msg, err := reader.Read(context.Background(), connection)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+
+		if msg == nil {
+			return ids
+		}
+
+		ids = append(ids, msg.GetCurrentHeader().ID)
+	}
+}
+
+// bind binds a new connection of the client to the interceptor; connections without a client are unidentified
+func bind(t *testing.T, i *Interceptor, name string, client interceptor.ClientID) (interceptor.Connection, *interceptortest.Reader, interceptor.Reader) {
+	t.Helper()
+
+	connection := interceptortest.NewConnection(name)
+	r := interceptortest.NewReader()
+	_, reader := interceptortest.Bind(t, i, connection, interceptortest.NewWriter(), r)
+
+	if client != "" {
+		interceptor.Set(interceptor.AttributesOf(connection), interceptor.ClientIDKey, client)
+	}
+
+	return connection, r, reader
+}
+
+func TestInterceptor_DropsDuplicatesWithinWindow(t *testing.T) {
+	i, c := newInterceptor(t, WithWindow(time.Minute))
+	alice, ra, readerA := bind(t, i, "a", "alice")
+	bob, rb, readerB := bind(t, i, "b", "bob")
+
+	ra.Queue(
+		newMessage(t, roomProtocol, "alice", "1"),
+		newMessage(t, roomProtocol, "alice", "2"),
+		newMessage(t, roomProtocol, "alice", "1"),
+	)
+	rb.Queue(newMessage(t, roomProtocol, "bob", "1")) // NOTE: SAME ID, DIFFERENT SENDER
+
+	if got := append(drain(t, readerA, alice), drain(t, readerB, bob)...); len(got) != 3 {
+		t.Fatalf("delivered %v, want 3 messages", got)
+	}
+
+	c.advance(2 * time.Minute)
+	ra.Queue(newMessage(t, roomProtocol, "alice", "1"))
+
+	if got := drain(t, readerA, alice); len(got) != 1 {
+		t.Errorf("delivered %v after the window, want the message again", got)
+	}
+
+	stats := i.Stats()
+	if stats.Passed != 4 || stats.Duplicates != 1 || stats.Dropped[roomProtocol] != 1 || stats.Senders != 2 {
+		t.Errorf("Stats() = %+v, want 4 passed and 1 duplicate of 2 senders", stats)
+	}
+}
+
+func TestInterceptor_DuplicatesAcrossConnections(t *testing.T) {
+	i, _ := newInterceptor(t)
+	first, r1, reader1 := bind(t, i, "first", "alice")
+	second, r2, reader2 := bind(t, i, "second", "alice")
+
+	// NOTE: THE MESSAGES DO NOT NAME THEIR SENDER; THE CLIENT ID OF THE CONNECTION IDENTIFIES IT
+	r1.Queue(newMessage(t, roomProtocol, message.UnknownSender, "1"))
+	r2.Queue(newMessage(t, roomProtocol, message.UnknownSender, "1"))
+
+	if got := drain(t, reader1, first); len(got) != 1 {
+		t.Fatalf("first path delivered %v, want 1 message", got)
+	}
+
+	if got := drain(t, reader2, second); len(got) != 0 {
+		t.Errorf("second path delivered %v, want the duplicate of the same client dropped", got)
+	}
+}
+
+func TestInterceptor_IgnoresHeaderSender(t *testing.T) {
+	i, _ := newInterceptor(t)
+	first, r1, reader1 := bind(t, i, "first", "")
+	second, r2, reader2 := bind(t, i, "second", "")
+
+	// NOTE: THE SENDER IN THE HEADER IS SET BY THE CLIENT; IT NEITHER SPLITS NOR JOINS SENDERS
+	r1.Queue(
+		newMessage(t, roomProtocol, "alice", "1"),
+		newMessage(t, roomProtocol, "bob", "1"),
+	)
+	r2.Queue(newMessage(t, roomProtocol, "alice", "1"))
+
+	if got := drain(t, reader1, first); len(got) != 1 {
+		t.Errorf("delivered %v, want the duplicate of the connection dropped", got)
+	}
+
+	if got := drain(t, reader2, second); len(got) != 1 {
+		t.Errorf("delivered %v, want the message of another unidentified connection passed", got)
+	}
+}
+
+func TestInterceptor_UnidentifiedMessagesPass(t *testing.T) {
+	i, _ := newInterceptor(t)
+	connection := interceptortest.NewConnection("a")
+	r := interceptortest.NewReader()
+	_, reader := interceptortest.Bind(t, i, connection, interceptortest.NewWriter(), r)
+
+	r.Queue(newMessage(t, roomProtocol, "alice", ""), newMessage(t, roomProtocol, "alice", ""))
+
+	if got := drain(t, reader, connection); len(got) != 2 {
+		t.Fatalf("delivered %v, want both messages without an ID", got)
+	}
+
+	if stats := i.Stats(); stats.Unidentified != 2 {
+		t.Errorf("Stats().Unidentified = %d, want 2", stats.Unidentified)
+	}
+}
+
+func TestInterceptor_Protocols(t *testing.T) {
+	i, _ := newInterceptor(t, WithProtocols(roomProtocol))
+	connection := interceptortest.NewConnection("a")
+	r := interceptortest.NewReader()
+	_, reader := interceptortest.Bind(t, i, connection, interceptortest.NewWriter(), r)
+
+	r.Queue(
+		newMessage(t, controlProtocol, "alice", "1"),
+		newMessage(t, controlProtocol, "alice", "1"),
+		newMessage(t, roomProtocol, "alice", "2"),
+		newMessage(t, roomProtocol, "alice", "2"),
+	)
+
+	if got := drain(t, reader, connection); len(got) != 3 {
+		t.Errorf("delivered %v, want only the room duplicate dropped", got)
+	}
+}
+
+func TestInterceptor_BoundedMemory(t *testing.T) {
+	i, _ := newInterceptor(t, WithCapacity(2), WithMaxSenders(2))
+	alice, ra, readerA := bind(t, i, "a", "alice")
+	bob, rb, readerB := bind(t, i, "b", "bob")
+	carol, rc, readerC := bind(t, i, "c", "carol")
+
+	// NOTE: THE CAPACITY FORGETS THE OLDEST ID OF THE SENDER
+	ra.Queue(
+		newMessage(t, roomProtocol, "alice", "1"),
+		newMessage(t, roomProtocol, "alice", "2"),
+		newMessage(t, roomProtocol, "alice", "3"),
+		newMessage(t, roomProtocol, "alice", "1"),
+	)
+
+	if got := drain(t, readerA, alice); len(got) != 4 {
+		t.Errorf("delivered %v, want the forgotten ID delivered again", got)
+	}
+
+	// NOTE: ALICE IS SEEN AGAIN AFTER BOB; CAROL MAKES THE LEAST RECENTLY SEEN BOB FORGOTTEN
+	rb.Queue(newMessage(t, roomProtocol, "bob", "1"))
+	drain(t, readerB, bob)
+	ra.Queue(newMessage(t, roomProtocol, "alice", "4"))
+	drain(t, readerA, alice)
+	rc.Queue(newMessage(t, roomProtocol, "carol", "1"))
+	drain(t, readerC, carol)
+
+	if stats := i.Stats(); stats.Senders != 2 {
+		t.Errorf("Stats().Senders = %d, want at most 2", stats.Senders)
+	}
+
+	ra.Queue(newMessage(t, roomProtocol, "alice", "4"))
+	if got := drain(t, readerA, alice); len(got) != 0 {
+		t.Errorf("delivered %v, want the recently seen sender remembered", got)
+	}
+
+	rb.Queue(newMessage(t, roomProtocol, "bob", "1"))
+	if got := drain(t, readerB, bob); len(got) != 1 {
+		t.Errorf("delivered %v, want the least recently seen sender forgotten", got)
+	}
+}
+
+func TestInterceptor_Stamping(t *testing.T) {
+	i, _ := newInterceptor(t, WithStamping)
+	connection := interceptortest.NewConnection("a")
+	w := interceptortest.NewWriter()
+	writer, _ := interceptortest.Bind(t, i, connection, w, interceptortest.NewReader())
+
+	stamped := newMessage(t, roomProtocol, "alice", "")
+	kept := newMessage(t, roomProtocol, "alice", "given")
+
+	for _, msg := range []message.Message{stamped, kept, stamped} {
+		if err := writer.Write(context.Background(), connection, msg); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	written := w.Messages()
+	if id := written[0].GetCurrentHeader().ID; id == "" {
+		t.Errorf("written message was not stamped")
+	}
+	if id := written[1].GetCurrentHeader().ID; id != "given" {
+		t.Errorf("written message ID = %q, want the given ID kept", id)
+	}
+	if written[0].GetCurrentHeader().ID != written[2].GetCurrentHeader().ID {
+		t.Errorf("message written again got a new ID")
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	for name, option := range map[string]Option{
+		"window":      WithWindow(0),
+		"capacity":    WithCapacity(0),
+		"max senders": WithMaxSenders(-1),
+	} {
+		if _, err := NewInterceptorFactory(option).NewInterceptor(context.Background(), "test", message.NewDefaultRegistry()); err == nil {
+			t.Errorf("%s: NewInterceptor() error = nil, want an error", name)
+		}
+	}
+}
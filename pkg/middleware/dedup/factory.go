@@ -0,0 +1,57 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// InterceptorName names the deduplication interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Dedup
+
+// Descriptor places dedup above encrypt and fragment, so that the decrypted, reassembled messages are
+// deduplicated, and beneath ratelimit, chat and stream, so that the duplicates are dropped before they are
+// counted or processed.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Before:   []string{names.Encrypt, names.Fragment},
+		After:    []string{names.Chat, names.Stream, names.RateLimit},
+		Optional: []string{names.Encrypt, names.Fragment, names.Chat, names.Stream, names.RateLimit},
+	}
+}
+
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		window:          DefaultWindow,
+		capacity:        DefaultCapacity,
+		maxSenders:      DefaultMaxSenders,
+		protocols:       make(map[message.Protocol]struct{}),
+		now:             time.Now,
+		senders:         make(map[sender]*list.Element),
+		recent:          list.New(),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
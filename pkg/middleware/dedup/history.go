@@ -0,0 +1,55 @@
+package dedup
+
+import "time"
+
+type entry struct {
+	id string
+	at time.Time
+}
+
+// history remembers the recent message IDs of a sender in a ring of bounded capacity; once full, the
+// oldest ID is forgotten for every new one
+type history struct {
+	ring     []entry
+	next     int
+	capacity int
+	ids      map[string]time.Time // ids holds the time every remembered ID was last remembered at
+	last     time.Time            // last is the time the sender was last seen at
+}
+
+func newHistory(capacity int) *history {
+	return &history{
+		capacity: capacity,
+		ids:      make(map[string]time.Time),
+	}
+}
+
+// seen reports whether the ID was remembered within the window; otherwise, the ID is remembered
+func (h *history) seen(id string, now time.Time, window time.Duration) bool {
+	h.last = now
+
+	if at, ok := h.ids[id]; ok && now.Sub(at) <= window {
+		return true
+	}
+
+	e := entry{id: id, at: now}
+
+	if len(h.ring) < h.capacity {
+		h.ring = append(h.ring, e)
+	} else {
+		// NOTE: THE FORGOTTEN ID MAY HAVE BEEN REMEMBERED AGAIN SINCE; ONLY ITS LATEST ENTRY REMOVES IT
+		if old := h.ring[h.next]; h.ids[old.id].Equal(old.at) {
+			delete(h.ids, old.id)
+		}
+		h.ring[h.next] = e
+		h.next = (h.next + 1) % h.capacity
+	}
+
+	h.ids[id] = now
+	return false
+}
+
+// expired reports whether the sender was not seen within the window; all its IDs are forgettable
+func (h *history) expired(now time.Time, window time.Duration) bool {
+	return now.Sub(h.last) > window
+}
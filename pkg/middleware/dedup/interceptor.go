@@ -0,0 +1,207 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor drops the read messages whose ID (see message.Header) was already read from the same sender
+// within the window, for example, messages sent again by a reconnecting client or delivered over more than
+// one path. Messages without an ID are passed on.
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	window     time.Duration
+	capacity   int
+	maxSenders int
+	protocols  map[message.Protocol]struct{}
+	stamp      bool
+	now        func() time.Time
+	senders    map[sender]*list.Element // senders holds the elements of recent; their values are *remembered
+	recent     *list.List               // recent orders the senders from the most to the least recently seen
+	stats      counters
+	mux        sync.Mutex
+}
+
+// sender identifies the source of the message IDs. Senders are identified by the verified client ID of
+// their connection (see interceptor.ClientIDKey), never by the sender in the header of their messages, which
+// any client can set; the IDs of unidentified senders are only compared with the other messages of their
+// connection.
+type sender struct {
+	id         string
+	connection interceptor.Connection
+}
+
+type remembered struct {
+	sender  sender
+	history *history
+}
+
+// Stats counts the messages read by the interceptor
+type Stats struct {
+	Passed       uint64 `json:"passed"`
+	Duplicates   uint64 `json:"duplicates"`
+	Unidentified uint64 `json:"unidentified"` // Unidentified counts the passed messages without an ID
+	Senders      int    `json:"senders"`      // Senders is the number of senders remembered
+	// Dropped counts the duplicates by protocol
+	Dropped map[message.Protocol]uint64 `json:"dropped"`
+}
+
+type counters struct {
+	passed       atomic.Uint64
+	duplicates   atomic.Uint64
+	unidentified atomic.Uint64
+	dropped      map[message.Protocol]uint64
+	mux          sync.Mutex
+}
+
+func (c *counters) drop(protocol message.Protocol) {
+	c.duplicates.Add(1)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.dropped == nil {
+		c.dropped = make(map[message.Protocol]uint64)
+	}
+	c.dropped[protocol]++
+}
+
+func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
+	if !i.stamp {
+		return writer
+	}
+
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		if msg != nil && msg.GetCurrentHeader().ID == "" {
+			if m, ok := msg.(message.Identifiable); ok {
+				m.SetID(message.NewID())
+			}
+		}
+
+		return writer.Write(ctx, connection, msg)
+	})
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			if !i.applies(msg.GetProtocol()) {
+				return msg, nil
+			}
+
+			header := msg.GetCurrentHeader()
+			if header.ID == "" {
+				i.stats.unidentified.Add(1)
+				i.stats.passed.Add(1)
+				return msg, nil
+			}
+
+			if i.duplicate(senderOf(connection), header.ID) {
+				i.stats.drop(msg.GetProtocol())
+				// NOTE: DUPLICATE DROPPED; KEEP READING
+				continue
+			}
+
+			i.stats.passed.Add(1)
+			return msg, nil
+		}
+	})
+}
+
+func (i *Interceptor) applies(protocol message.Protocol) bool {
+	if len(i.protocols) == 0 {
+		return true
+	}
+
+	_, ok := i.protocols[protocol]
+	return ok
+}
+
+func senderOf(connection interceptor.Connection) sender {
+	if id, ok := interceptor.Get(interceptor.AttributesOf(connection), interceptor.ClientIDKey); ok {
+		return sender{id: string(id)}
+	}
+
+	return sender{connection: connection}
+}
+
+// duplicate reports whether the ID was seen from the sender within the window; otherwise, it is remembered
+func (i *Interceptor) duplicate(s sender, id string) bool {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	now := i.now()
+
+	element, exists := i.senders[s]
+	if exists {
+		i.recent.MoveToFront(element)
+	} else {
+		i.evict(now)
+
+		element = i.recent.PushFront(&remembered{sender: s, history: newHistory(i.capacity)})
+		i.senders[s] = element
+	}
+
+	return element.Value.(*remembered).history.seen(id, now, i.window)
+}
+
+// evict forgets the least recently seen senders which were not seen within the window, and the least
+// recently seen sender if the interceptor still remembers the maximum number of senders
+func (i *Interceptor) evict(now time.Time) {
+	for back := i.recent.Back(); back != nil && back.Value.(*remembered).history.expired(now, i.window); back = i.recent.Back() {
+		i.forget(back)
+	}
+
+	if back := i.recent.Back(); back != nil && len(i.senders) >= i.maxSenders {
+		i.forget(back)
+	}
+}
+
+func (i *Interceptor) forget(element *list.Element) {
+	i.recent.Remove(element)
+	delete(i.senders, element.Value.(*remembered).sender)
+}
+
+// UnBindSocketConnection forgets the unidentified senders of the connection
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if element, ok := i.senders[sender{connection: connection}]; ok {
+		i.forget(element)
+	}
+}
+
+// Stats returns the counts of the messages read by the interceptor
+func (i *Interceptor) Stats() Stats {
+	i.mux.Lock()
+	senders := len(i.senders)
+	i.mux.Unlock()
+
+	i.stats.mux.Lock()
+	defer i.stats.mux.Unlock()
+
+	dropped := make(map[message.Protocol]uint64, len(i.stats.dropped))
+	for protocol, n := range i.stats.dropped {
+		dropped[protocol] = n
+	}
+
+	return Stats{
+		Passed:       i.stats.passed.Load(),
+		Duplicates:   i.stats.duplicates.Load(),
+		Unidentified: i.stats.unidentified.Load(),
+		Senders:      senders,
+		Dropped:      dropped,
+	}
+}
@@ -0,0 +1,78 @@
+package dedup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	// DefaultWindow is how long a message ID is remembered
+	DefaultWindow = time.Minute
+	// DefaultCapacity is the number of message IDs remembered per sender; the oldest are forgotten first
+	DefaultCapacity = 1024
+	// DefaultMaxSenders is the number of senders whose message IDs are remembered; the least recently
+	// seen senders are forgotten first
+	DefaultMaxSenders = 4096
+)
+
+type Option = func(*Interceptor) error
+
+// WithWindow sets how long a message ID is remembered; a message is a duplicate if its ID was seen within
+// the window
+func WithWindow(window time.Duration) Option {
+	return func(i *Interceptor) error {
+		if window <= 0 {
+			return fmt.Errorf("dedup window must be positive; got %s", window)
+		}
+
+		i.window = window
+		return nil
+	}
+}
+
+// WithCapacity sets the number of message IDs remembered per sender. Together with WithMaxSenders, it
+// bounds the memory of the interceptor; a sender sending more messages than the capacity within the
+// window may get its oldest duplicates through.
+func WithCapacity(capacity int) Option {
+	return func(i *Interceptor) error {
+		if capacity <= 0 {
+			return fmt.Errorf("dedup capacity must be positive; got %d", capacity)
+		}
+
+		i.capacity = capacity
+		return nil
+	}
+}
+
+// WithMaxSenders sets the number of senders whose message IDs are remembered
+func WithMaxSenders(n int) Option {
+	return func(i *Interceptor) error {
+		if n <= 0 {
+			return fmt.Errorf("dedup max senders must be positive; got %d", n)
+		}
+
+		i.maxSenders = n
+		return nil
+	}
+}
+
+// WithProtocols limits the deduplication to the messages of the given protocols; every message is
+// deduplicated by default
+func WithProtocols(protocols ...message.Protocol) Option {
+	return func(i *Interceptor) error {
+		for _, protocol := range protocols {
+			i.protocols[protocol] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// WithStamping makes the interceptor set a new ID (see message.NewID) on the written messages without one,
+// so that the peer can deduplicate them. Messages written again keep the ID they were stamped with.
+func WithStamping(i *Interceptor) error {
+	i.stamp = true
+	return nil
+}
@@ -0,0 +1,313 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/config"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptionerr"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptor"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/interfaces"
+)
+
+const textProtocol message.Protocol = "test:text"
+
+type textMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *textMessage) GetProtocol() message.Protocol {
+	return textProtocol
+}
+
+func newText(t *testing.T, text string) *textMessage {
+	t.Helper()
+
+	msg := &textMessage{Text: text}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+
+	msg.BaseMessage = bmsg
+	return msg
+}
+
+type keyProvider struct {
+	signing      ed25519.PrivateKey
+	verification ed25519.PublicKey
+}
+
+func newKeyProvider(t *testing.T) *keyProvider {
+	t.Helper()
+
+	verification, signing, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	return &keyProvider{signing: signing, verification: verification}
+}
+
+func (p *keyProvider) GetSigningKey() ed25519.PrivateKey {
+	return p.signing
+}
+
+func (p *keyProvider) GetVerificationKey() ed25519.PublicKey {
+	return p.verification
+}
+
+func (p *keyProvider) Close() error {
+	return nil
+}
+
+func newRegistry(t *testing.T) message.Registry {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+	if err := message.RegisterAll(registry, message.Type[textMessage](textProtocol)); err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+
+	return registry
+}
+
+// peer is one end of a connection bound to an encryption interceptor
+type peer struct {
+	interceptor *Interceptor
+	connection  *interceptortest.Connection
+	w           *interceptortest.Writer
+	r           *interceptortest.Reader
+	writer      interceptor.Writer
+	reader      interceptor.Reader
+	registry    message.Registry
+}
+
+func newPeer(t *testing.T, server bool, provider *keyProvider) *peer {
+	t.Helper()
+
+	c := config.DefaultConfig()
+	c.IsServer = server
+
+	registry := newRegistry(t)
+
+	i, err := NewInterceptorFactory(WithConfig(c), WithKeyProvider(provider)).NewInterceptor(context.Background(), "test", registry)
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	p := &peer{
+		interceptor: i.(*Interceptor),
+		connection:  interceptortest.NewConnection("test"),
+		w:           interceptortest.NewWriter(),
+		r:           interceptortest.NewReader(),
+		registry:    registry,
+	}
+	p.writer, p.reader = interceptortest.Bind(t, i, p.connection, p.w, p.r)
+
+	return p
+}
+
+// send delivers the messages written by the peer to the other over the wire (serialized) and returns the
+// messages read by the other
+func (p *peer) send(t *testing.T, to *peer) []message.Message {
+	t.Helper()
+
+	for _, msg := range p.w.Messages() {
+		data, err := message.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		received, err := to.registry.Unmarshal(msg.GetProtocol(), data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", msg.GetProtocol(), err)
+		}
+
+		to.r.Queue(received)
+	}
+	p.w.Reset()
+
+	var msgs []message.Message
+	for {
+		msg, err := to.reader.Read(context.Background(), to.connection)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+
+		if msg == nil {
+			return msgs
+		}
+
+		msgs = append(msgs, msg)
+	}
+}
+
+// init starts the handshake of the peer; the returned channel receives the result of Init
+func (p *peer) init() <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- p.interceptor.Init(p.connection)
+	}()
+
+	return done
+}
+
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// connect runs the key exchange between the client and the server
+func connect(t *testing.T, client, server *peer) {
+	t.Helper()
+
+	clientDone := client.init()
+	// NOTE: THE CLIENT MUST BE WAITING FOR THE KEY EXCHANGE BEFORE THE SERVER STARTS IT
+	eventually(t, func() bool {
+		s, err := client.interceptor.GetState(client.connection)
+		return err == nil && s.GetKeyExchangeSessionID() != ""
+	})
+
+	serverDone := server.init()
+	eventually(t, func() bool {
+		return len(server.w.Messages()) == 1
+	})
+
+	// NOTE: INIT, RESPONSE, DONE AND DONE RESPONSE; ALL CONSUMED BY THE INTERCEPTORS
+	for index := 0; index < 2; index++ {
+		if msgs := server.send(t, client); len(msgs) != 0 {
+			t.Fatalf("key exchange read as %v, want it consumed", msgs)
+		}
+		if msgs := client.send(t, server); len(msgs) != 0 {
+			t.Fatalf("key exchange read as %v, want it consumed", msgs)
+		}
+	}
+
+	for name, done := range map[string]<-chan error{"client": clientDone, "server": serverDone} {
+		if err := <-done; err != nil {
+			t.Fatalf("%s: Init() error = %v", name, err)
+		}
+	}
+}
+
+func TestInterceptor_RoundTrip(t *testing.T) {
+	provider := newKeyProvider(t)
+	client, server := newPeer(t, false, provider), newPeer(t, true, provider)
+	connect(t, client, server)
+
+	for _, p := range []*peer{client, server} {
+		if _, ok := interceptor.Get(interceptor.AttributesOf(p.connection), HandshakeCompletedKey); !ok {
+			t.Error("handshake completion not recorded on the connection")
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		from, to *peer
+	}{
+		{name: "client to server", from: client, to: server},
+		{name: "server to client", from: server, to: client},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.from.writer.Write(context.Background(), tt.from.connection, newText(t, "secret")); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			written := tt.from.w.Messages()
+			if len(written) != 1 || written[0].GetProtocol() != encryptor.EncryptedMessageProtocol {
+				t.Fatalf("written %v, want the encrypted message", tt.from.w.Protocols())
+			}
+
+			read := tt.from.send(t, tt.to)
+			if len(read) != 1 {
+				t.Fatalf("read %d messages, want 1", len(read))
+			}
+
+			got, ok := read[0].(*textMessage)
+			if !ok || got.Text != "secret" {
+				t.Fatalf("read %T, want the text message", read[0])
+			}
+		})
+	}
+}
+
+func TestInterceptor_RequireEncryption(t *testing.T) {
+	p := newPeer(t, true, newKeyProvider(t))
+
+	p.r.Queue(newText(t, "plain"))
+	if _, err := p.reader.Read(context.Background(), p.connection); !errors.Is(err, encryptionerr.ErrInvalidInterceptor) {
+		t.Errorf("Read() error = %v, want %v for an unencrypted message", err, encryptionerr.ErrInvalidInterceptor)
+	}
+}
+
+func TestInterceptor_UnBindDropsState(t *testing.T) {
+	p := newPeer(t, true, newKeyProvider(t))
+
+	s, err := p.interceptor.GetState(p.connection)
+	if err != nil {
+		t.Fatalf("GetState() error = %v", err)
+	}
+
+	// NOTE: STARTS THE KEY EXCHANGE WITHOUT WAITING FOR THE PEER
+	if err := p.interceptor.keyExchangeManager.Init(s); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	p.interceptor.UnBindSocketConnection(p.connection)
+
+	if _, err := p.interceptor.GetState(p.connection); !errors.Is(err, encryptionerr.ErrConnectionNotFound) {
+		t.Errorf("GetState() error = %v, want %v", err, encryptionerr.ErrConnectionNotFound)
+	}
+
+	states := 0
+	_ = p.interceptor.stateManager.ForEach(func(interceptor.Connection, interfaces.State) error {
+		states++
+		return nil
+	})
+	if states != 0 {
+		t.Errorf("%d states left, want none", states)
+	}
+
+	if err := p.interceptor.keyExchangeManager.Remove(s); !errors.Is(err, encryptionerr.ErrSessionNotFound) {
+		t.Errorf("Remove() error = %v, want the key exchange session dropped", err)
+	}
+}
+
+func TestEncryptedMessage_Validate(t *testing.T) {
+	registry := newRegistry(t)
+
+	for _, tt := range []struct {
+		data  string
+		field string
+	}{
+		{data: `{"protocol":"encrypt:encrypted_message","next_protocol":"test:text","next_payload":"AA=="}`, field: "nonce"},
+		{data: `{"protocol":"encrypt:encrypted_message","nonce":[1,0,0,0,0,0,0,0,0,0,0,0],"next_protocol":"test:text","next_payload":"AA=="}`, field: "session_id"},
+	} {
+		_, err := registry.UnmarshalRaw(message.Payload(tt.data))
+
+		var verr *message.ValidationError
+		if !errors.As(err, &verr) || verr.Field != tt.field {
+			t.Errorf("UnmarshalRaw(%s) error = %v, want a validation error of %s", tt.data, err, tt.field)
+		}
+	}
+}
@@ -61,6 +61,10 @@ func (a *AES256Encryptor) Encrypt(msg message.Message) (message.Message, error)
 		return nil, encryptionerr.ErrInvalidInterceptor // JUST TO BE SURE
 	}
 
+	if a.encryptor == nil {
+		return nil, encryptionerr.ErrEncryptionNotReady
+	}
+
 	nonce := types.Nonce{}
 
 	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
@@ -69,7 +73,10 @@ func (a *AES256Encryptor) Encrypt(msg message.Message) (message.Message, error)
 
 	encryptedData := a.encryptor.Seal(nil, nonce[:], m.NextPayload, a.sessionID[:])
 
-	m.NextPayload = encryptedData
+	m.SetNextPayload(message.NewOpaquePayload(encryptedData))
+	// NOTE: THE PEER OPENS THE PAYLOAD WITH THE NONCE AND SESSION CARRIED BY THE ENVELOPE
+	m.Nonce = nonce
+	m.SessionID = a.sessionID
 
 	return m, nil
 }
@@ -83,12 +90,21 @@ func (a *AES256Encryptor) Decrypt(msg message.Message) (message.Message, error)
 		return nil, encryptionerr.ErrInvalidInterceptor // JUST TO BE SURE
 	}
 
-	data, err := a.decryptor.Open(nil, m.Nonce[:], m.NextPayload, a.sessionID[:])
+	if a.decryptor == nil {
+		return nil, encryptionerr.ErrEncryptionNotReady
+	}
+
+	encryptedData, err := m.NextPayload.Opaque()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.decryptor.Open(nil, m.Nonce[:], encryptedData, a.sessionID[:])
 	if err != nil {
 		return nil, fmt.Errorf("decryption failed: %w", err)
 	}
 
-	m.NextPayload = data
+	m.SetNextPayload(data)
 
 	return m, nil
 }
@@ -107,7 +123,13 @@ func (a *AES256Encryptor) Ready() bool {
 	return a.encryptor != nil && a.decryptor != nil
 }
 
+// Close discards the keys; the encryptor is no longer ready and fails to encrypt or decrypt until SetKeys is called again
 func (a *AES256Encryptor) Close() error {
-	// TODO implement me
-	panic("implement me")
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.encryptor = nil
+	a.decryptor = nil
+
+	return nil
 }
@@ -1,6 +1,7 @@
 package encryptor
 
 import (
+	"context"
 	"time"
 
 	"github.com/harshabose/socket-comm/pkg/interceptor"
@@ -10,11 +11,13 @@ import (
 	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/types"
 )
 
+const EncryptedMessageProtocol message.Protocol = "encrypt:encrypted_message"
+
 type EncryptedMessage struct {
 	interceptor.BaseMessage
-	Nonce     types.Nonce
-	Timestamp time.Time
-	SessionID types.EncryptionSessionID
+	Nonce     types.Nonce               `json:"nonce"`
+	Timestamp time.Time                 `json:"timestamp"`
+	SessionID types.EncryptionSessionID `json:"session_id"`
 }
 
 func NewEncryptedMessage(msg message.Message) (*EncryptedMessage, error) {
@@ -28,11 +31,35 @@ func NewEncryptedMessage(msg message.Message) (*EncryptedMessage, error) {
 	}
 
 	em.BaseMessage = bmsg
+	// NOTE: THE HEADER OF msg IS ENCRYPTED; THE ENVELOPE CARRIES ITS EXPIRY, TRACE CONTEXT AND PRIORITY
+	message.InheritExpiry(em, msg)
+	message.InheritTrace(em, msg)
+	interceptor.InheritPriority(em, msg)
 
 	return em, nil
 }
 
-func (m *EncryptedMessage) WriteProcess(_i interceptor.Interceptor, connection interceptor.Connection) error {
+func (m *EncryptedMessage) GetProtocol() message.Protocol {
+	return EncryptedMessageProtocol
+}
+
+func (m *EncryptedMessage) Validate() error {
+	if err := message.RequireNotZero("nonce", m.Nonce); err != nil {
+		return err
+	}
+
+	if err := message.RequireNotZero("session_id", m.SessionID); err != nil {
+		return err
+	}
+
+	if m.NextProtocol == message.NoneProtocol || len(m.NextPayload) == 0 {
+		return message.NewValidationError("next", "the encrypted message is missing")
+	}
+
+	return nil
+}
+
+func (m *EncryptedMessage) WriteProcess(_ context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	i, ok := _i.(interfaces.CanGetState)
 	if !ok {
 		return encryptionerr.ErrInvalidInterceptor
@@ -58,12 +85,12 @@ func (m *EncryptedMessage) WriteProcess(_i interceptor.Interceptor, connection i
 		return encryptionerr.ErrInvalidInterceptor
 	}
 
-	m.NextPayload = msg.NextPayload
+	m.SetNextPayload(msg.NextPayload)
 
 	return nil
 }
 
-func (m *EncryptedMessage) ReadProcess(_i interceptor.Interceptor, conn interceptor.Connection) error {
+func (m *EncryptedMessage) ReadProcess(_ context.Context, _i interceptor.Interceptor, conn interceptor.Connection) error {
 	i, ok := _i.(interfaces.CanGetState)
 	if !ok {
 		return encryptionerr.ErrInvalidInterceptor
@@ -89,7 +116,7 @@ func (m *EncryptedMessage) ReadProcess(_i interceptor.Interceptor, conn intercep
 		return encryptionerr.ErrInvalidInterceptor // JUST TO BE SURE
 	}
 
-	m.NextPayload = msg.NextPayload // JUST MAKING SURE
+	m.SetNextPayload(msg.NextPayload) // JUST MAKING SURE
 
 	return nil
 }
@@ -1,5 +1,21 @@
 package encryptor
 
-func NewEncryptor(cipherSuite string) (Encryptor, error) {
-	return nil, nil
+import (
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptionerr"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/interfaces"
+)
+
+// CipherSuiteAES256GCM names the AES-256-GCM cipher suite (see types.EncryptionProtocol)
+const CipherSuiteAES256GCM = "AES-256-GCM"
+
+// NewEncryptor creates the encryptor of the cipher suite; the encryptor is not ready until its keys are set
+func NewEncryptor(cipherSuite string) (interfaces.Encryptor, error) {
+	switch cipherSuite {
+	case CipherSuiteAES256GCM:
+		return &AES256Encryptor{}, nil
+	default:
+		return nil, fmt.Errorf("error while creating encryptor of %q; err: %w", cipherSuite, encryptionerr.ErrInvalidConfig)
+	}
 }
@@ -0,0 +1,64 @@
+package encrypt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/config"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptionerr"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/keyexchange"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/state"
+)
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// Descriptor names the encryption interceptor and declares its error policy. The interceptors around it
+// place themselves relative to encrypt: the ones handling and transforming messages run before it, and
+// fragment runs after it, so that the encrypted envelopes are split.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:        InterceptorName,
+		ErrorPolicy: DefaultErrorPolicy,
+	}
+}
+
+// NewInterceptor creates the encryption interceptor. The key provider must be set (see WithKeyProvider); the
+// server signs its half of the key exchange with it and the client verifies the signature. The key exchange
+// messages and the encrypted envelopes are read by the socket, so both ends register them in the registry of
+// their socket (see RegisterMessages); the decrypted messages are decoded with the given registry.
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	local := message.NewDefaultRegistry()
+	if err := RegisterMessages(local); err != nil {
+		return nil, err
+	}
+
+	i := &Interceptor{
+		NoOpInterceptor:      interceptor.NewNoOpInterceptor(ctx, id, registry),
+		localMessageRegistry: local,
+		keyExchangeManager:   keyexchange.NewManager(),
+		stateManager:         state.NewManager(),
+		config:               config.DefaultConfig(),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.keyProvider == nil {
+		return nil, fmt.Errorf("error while creating encryption interceptor; err: %w", encryptionerr.ErrInvalidProvider)
+	}
+
+	return i, nil
+}
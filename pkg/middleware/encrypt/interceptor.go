@@ -2,9 +2,14 @@ package encrypt
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/config"
 	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptionerr"
@@ -16,6 +21,20 @@ import (
 	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/types"
 )
 
+// InterceptorName names the encryption interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Encrypt
+
+// DefaultErrorPolicy closes the connection with the "policy violation" close code when a message cannot be
+// encrypted or decrypted; passing it on unencrypted, or dropping it silently, would hide a broken session.
+var DefaultErrorPolicy = interceptor.ErrorPolicy{
+	Default: interceptor.ErrorClose,
+	Code:    1008,
+}
+
+// HandshakeCompletedKey is the connection attribute holding the time the key exchange completed; other
+// interceptors use it to know the connection is encrypted
+var HandshakeCompletedKey = interceptor.NewKey[time.Time]("encrypt:handshake_completed")
+
 type Interceptor struct {
 	interceptor.NoOpInterceptor
 	localMessageRegistry message.Registry
@@ -49,6 +68,19 @@ func (i *Interceptor) Init(connection interceptor.Connection) error {
 		return err
 	}
 
+	if err := i.handshake(s); err != nil {
+		events.Publish(i.Ctx(), events.Event{Kind: events.HandshakeFailed, Source: i.ID(), Connection: connection, Err: err})
+		return err
+	}
+
+	interceptor.Set(interceptor.AttributesOf(connection), HandshakeCompletedKey, time.Now())
+	events.Publish(i.Ctx(), events.Event{Kind: events.HandshakeCompleted, Source: i.ID(), Connection: connection})
+
+	return nil
+}
+
+// handshake runs the key exchange of the connection's state until its keys are set
+func (i *Interceptor) handshake(s interfaces.State) error {
 	if err := i.keyExchangeManager.Init(s, keyexchange.WithKeySignature(i.keyProvider)); err != nil {
 		return err
 	}
@@ -66,61 +98,98 @@ func (i *Interceptor) Init(connection interceptor.Connection) error {
 }
 
 func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
-	return interceptor.WriterFunc(func(conn interceptor.Connection, msg message.Message) error {
-		var m *encryptor.EncryptedMessage
-
-		if !i.localMessageRegistry.Check(msg.GetProtocol()) {
-			m, err := encryptor.NewEncryptedMessage(msg)
-			if err != nil {
-				return err
-			}
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		// NOTE: THE KEY EXCHANGE MESSAGES ARE WRITTEN AS THEY ARE; THEY SET UP THE KEYS OF THE OTHERS
+		if msg == nil || i.localMessageRegistry.Check(msg.GetProtocol()) {
+			return writer.Write(ctx, connection, msg)
+		}
 
-			msg = m
+		m, err := encryptor.NewEncryptedMessage(msg)
+		if err != nil {
+			return err
 		}
 
-		// TODO: m might be nil
-		if err := m.WriteProcess(i, conn); err != nil {
+		if err := m.WriteProcess(ctx, i, connection); err != nil {
 			return err
 		}
 
-		return writer.Write(conn, m)
+		return writer.Write(ctx, connection, m)
 	})
 }
 
 func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
-	return interceptor.ReaderFunc(func(conn interceptor.Connection) (message.Message, error) {
-		msg, err := reader.Read(conn)
-		if err != nil {
-			return msg, err
-		}
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
 
-		if !i.localMessageRegistry.Check(msg.GetProtocol()) {
-			if !i.config.RequireEncryption {
-				return msg, nil
+			if !i.localMessageRegistry.Check(msg.GetProtocol()) {
+				if !i.config.RequireEncryption {
+					return msg, nil
+				}
+				return nil, encryptionerr.ErrInvalidInterceptor
 			}
-			return nil, encryptionerr.ErrInvalidInterceptor
-		}
 
-		m, ok := msg.(interceptor.Message)
-		if !ok {
-			return nil, encryptionerr.ErrInvalidInterceptor
-		}
+			m, ok := msg.(interceptor.Message)
+			if !ok {
+				return nil, encryptionerr.ErrInvalidInterceptor
+			}
 
-		if err := m.ReadProcess(i, conn); err != nil {
-			return nil, err
-		}
+			if err := m.ReadProcess(ctx, i, connection); err != nil {
+				return nil, err
+			}
+
+			next, err := m.GetNext(i.GetMessageRegistry())
+			if err != nil {
+				return nil, err
+			}
 
-		return m.GetNext(i.GetMessageRegistry())
+			if next == nil {
+				// NOTE: KEY EXCHANGE MESSAGE CONSUMED; KEEP READING
+				continue
+			}
+
+			return next, nil
+		}
 	})
 }
 
+// UnBindSocketConnection drops the key exchange session and the state of the connection, discarding its keys
 func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
-	// TODO: Implement full closing
+	s, err := i.stateManager.GetState(connection)
+	if err != nil {
+		return
+	}
+
+	if err := i.keyExchangeManager.Remove(s); err != nil && !errors.Is(err, encryptionerr.ErrSessionNotFound) {
+		fmt.Println("error while removing key exchange session; err:", err.Error())
+	}
+
+	if err := i.stateManager.RemoveState(connection); err != nil {
+		fmt.Println("error while removing state; err:", err.Error())
+	}
+
+	if closer, ok := s.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			fmt.Println("error while closing state; err:", err.Error())
+		}
+	}
 }
 
 func (i *Interceptor) Close() error {
-	// TODO: Use UnBindSocketConnection to close all
-	// TODO: Close interceptor
+	connections := make([]interceptor.Connection, 0)
+	_ = i.stateManager.ForEach(func(connection interceptor.Connection, _ interfaces.State) error {
+		connections = append(connections, connection)
+		return nil
+	})
+
+	// NOTE: ForEach HOLDS THE READ LOCK OF THE MANAGER; THE CONNECTIONS ARE UNBOUND AFTER IT RETURNS
+	for _, connection := range connections {
+		i.UnBindSocketConnection(connection)
+	}
+
 	return nil
 }
 
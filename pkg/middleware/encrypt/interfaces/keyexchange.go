@@ -11,6 +11,17 @@ type ProtocolProcessor interface {
 type KeyExchangeManager interface {
 	Init(state State, options ...ProtocolFactoryOption) error
 	Finalise(state State) error
+	Remove(state State) error
+}
+
+// SessionIDGetter is implemented by the key exchange protocols agreeing on the encryption session
+type SessionIDGetter interface {
+	GetSessionID() types.EncryptionSessionID
+}
+
+// SessionIDSetter is implemented by the states whose encryptor binds the messages to their session
+type SessionIDSetter interface {
+	SetSessionID(id types.EncryptionSessionID)
 }
 
 type CanGetSessionState interface {
@@ -1,6 +1,7 @@
 package keyexchange
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -14,6 +15,13 @@ import (
 	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/types"
 )
 
+const (
+	InitProtocol         message.Protocol = "encrypt:key_exchange_init"
+	ResponseProtocol     message.Protocol = "encrypt:key_exchange_response"
+	DoneProtocol         message.Protocol = "encrypt:key_exchange_done"
+	DoneResponseProtocol message.Protocol = "encrypt:key_exchange_done_response"
+)
+
 type Init struct {
 	// TODO: MANAGE STATE USING KEY EXCHANGE SESSION id
 	interceptor.BaseMessage
@@ -40,11 +48,35 @@ func NewInit(pubKey types.PublicKey, sign []byte, sessionID types.EncryptionSess
 	return msg, nil
 }
 
-func (m *Init) WriteProcess(_ interceptor.Interceptor, _ interceptor.Connection) error {
+func (m *Init) GetProtocol() message.Protocol {
+	return InitProtocol
+}
+
+func (m *Init) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *Init) Validate() error {
+	if err := message.RequireNotZero("public_key", m.PublicKey); err != nil {
+		return err
+	}
+
+	if len(m.Signature) != ed25519.SignatureSize {
+		return message.NewValidationError("signature", fmt.Sprintf("must be %d bytes long", ed25519.SignatureSize))
+	}
+
+	if err := message.RequireNotZero("session_id", m.SessionID); err != nil {
+		return err
+	}
+
+	return message.RequireNotZero("salt", m.Salt)
+}
+
+func (m *Init) WriteProcess(_ context.Context, _ interceptor.Interceptor, _ interceptor.Connection) error {
 	return nil
 }
 
-func (m *Init) ReadProcess(_interceptor interceptor.Interceptor, connection interceptor.Connection) error {
+func (m *Init) ReadProcess(_ context.Context, _interceptor interceptor.Interceptor, connection interceptor.Connection) error {
 	ss, ok := _interceptor.(interfaces.CanGetState)
 	if !ok {
 		return encryptionerr.ErrInvalidInterceptor
@@ -69,7 +101,7 @@ func (m *Init) Process(protocol interfaces.Protocol, s interfaces.State) error {
 		return encryptionerr.ErrInvalidMessageType
 	}
 
-	if p.state != types.SessionStateInitial {
+	if p.GetState() != types.SessionStateInitial {
 		return encryptionerr.ErrInvalidSessionState
 	}
 
@@ -102,7 +134,7 @@ func (m *Init) Process(protocol interfaces.Protocol, s interfaces.State) error {
 		return err
 	}
 
-	p.state = types.SessionStateInProgress
+	p.setState(types.SessionStateInProgress)
 	return nil
 }
 
@@ -125,11 +157,23 @@ func NewResponse(pubKey types.PublicKey) (*Response, error) {
 	return msg, nil
 }
 
-func (m *Response) WriteProcess(_ interceptor.Interceptor, _ interceptor.Connection) error {
+func (m *Response) GetProtocol() message.Protocol {
+	return ResponseProtocol
+}
+
+func (m *Response) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *Response) Validate() error {
+	return message.RequireNotZero("public_key", m.PublicKey)
+}
+
+func (m *Response) WriteProcess(_ context.Context, _ interceptor.Interceptor, _ interceptor.Connection) error {
 	return nil
 }
 
-func (m *Response) ReadProcess(_i interceptor.Interceptor, connection interceptor.Connection) error {
+func (m *Response) ReadProcess(_ context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	ss, ok := _i.(interfaces.CanGetState)
 	if !ok {
 		return encryptionerr.ErrInvalidInterceptor
@@ -154,7 +198,7 @@ func (m *Response) Process(protocol interfaces.Protocol, s interfaces.State) err
 		return encryptionerr.ErrInvalidMessageType
 	}
 
-	if p.state != types.SessionStateInitial {
+	if p.GetState() != types.SessionStateInitial {
 		return encryptionerr.ErrInvalidSessionState
 	}
 
@@ -180,7 +224,7 @@ func (m *Response) Process(protocol interfaces.Protocol, s interfaces.State) err
 		return err
 	}
 
-	p.state = types.SessionStateInProgress
+	p.setState(types.SessionStateInProgress)
 	return nil
 }
 
@@ -202,11 +246,23 @@ func NewDone() (*Done, error) {
 	return msg, nil
 }
 
-func (m *Done) WriteProcess(_ interceptor.Interceptor, _ interceptor.Connection) error {
+func (m *Done) GetProtocol() message.Protocol {
+	return DoneProtocol
+}
+
+func (m *Done) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *Done) Validate() error {
+	return message.RequireNotZero("timestamp", m.Timestamp)
+}
+
+func (m *Done) WriteProcess(_ context.Context, _ interceptor.Interceptor, _ interceptor.Connection) error {
 	return nil
 }
 
-func (m *Done) ReadProcess(_i interceptor.Interceptor, connection interceptor.Connection) error {
+func (m *Done) ReadProcess(_ context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
 	ss, ok := _i.(interfaces.CanGetState)
 	if !ok {
 		return encryptionerr.ErrInvalidInterceptor
@@ -231,6 +287,10 @@ func (m *Done) Process(protocol interfaces.Protocol, s interfaces.State) error {
 		return encryptionerr.ErrInvalidMessageType
 	}
 
+	if p.GetState() != types.SessionStateInProgress {
+		return encryptionerr.ErrInvalidSessionState
+	}
+
 	msg, err := NewDoneResponse()
 	if err != nil {
 		return err
@@ -240,7 +300,7 @@ func (m *Done) Process(protocol interfaces.Protocol, s interfaces.State) error {
 		return err
 	}
 
-	p.state = types.SessionStateCompleted
+	p.setState(types.SessionStateCompleted)
 	return nil
 }
 
@@ -263,8 +323,33 @@ func NewDoneResponse() (*DoneResponse, error) {
 	return msg, nil
 }
 
-// TODO: ADD WRITE OR READ PROCESS METHODS
-// TODO: ADD PROTOCOLS
+func (m *DoneResponse) GetProtocol() message.Protocol {
+	return DoneResponseProtocol
+}
+
+func (m *DoneResponse) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+// ReadProcess processes the DoneResponse itself; the ReadProcess of the embedded Done would process it as a Done
+func (m *DoneResponse) ReadProcess(_ context.Context, _i interceptor.Interceptor, connection interceptor.Connection) error {
+	ss, ok := _i.(interfaces.CanGetState)
+	if !ok {
+		return encryptionerr.ErrInvalidInterceptor
+	}
+
+	s, err := ss.GetState(connection)
+	if err != nil {
+		return err
+	}
+
+	pp, ok := _i.(interfaces.ProtocolProcessor)
+	if !ok {
+		return encryptionerr.ErrInvalidInterceptor
+	}
+
+	return pp.Process(m, s)
+}
 
 func (m *DoneResponse) Process(protocol interfaces.Protocol, _ interfaces.State) error {
 	p, ok := protocol.(*Curve25519Protocol)
@@ -272,6 +357,10 @@ func (m *DoneResponse) Process(protocol interfaces.Protocol, _ interfaces.State)
 		return encryptionerr.ErrInvalidMessageType
 	}
 
-	p.state = types.SessionStateCompleted
+	if p.GetState() != types.SessionStateInProgress {
+		return encryptionerr.ErrInvalidSessionState
+	}
+
+	p.setState(types.SessionStateCompleted)
 	return nil
 }
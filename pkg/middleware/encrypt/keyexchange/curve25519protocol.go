@@ -3,6 +3,7 @@ package keyexchange
 import (
 	"crypto/rand"
 	"io"
+	"sync"
 
 	"golang.org/x/crypto/curve25519"
 	"golang.org/x/crypto/ed25519"
@@ -23,8 +24,8 @@ type Curve25519Protocol struct {
 	decKey       types.Key
 	state        types.SessionState
 	options      Curve25519Options
-
-	// TODO: mutex is needed here for SessionState and Keys
+	// NOTE: THE STATE IS GUARDED; THE KEYS ARE SET BEFORE THE STATE COMPLETES AND ARE ONLY READ AFTER IT DID
+	mux sync.RWMutex
 }
 
 type Curve25519Options struct {
@@ -33,6 +34,22 @@ type Curve25519Options struct {
 	RequireSignature bool
 }
 
+// Curve25519Ed25519 names the Curve25519 key exchange signed with Ed25519 (see types.EncryptionProtocol)
+const Curve25519Ed25519 types.KeyExchangeProtocol = "curve25519-ed25519"
+
+// NewCurve25519Protocol is the ProtocolFactory of Curve25519Protocol
+func NewCurve25519Protocol(options ...interfaces.ProtocolFactoryOption) (interfaces.Protocol, error) {
+	p := &Curve25519Protocol{}
+
+	for _, option := range options {
+		if err := option(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
 func (p *Curve25519Protocol) Init(s interfaces.State) error {
 	if _, err := io.ReadFull(rand.Reader, p.privKey[:]); err != nil {
 		return err
@@ -42,12 +59,12 @@ func (p *Curve25519Protocol) Init(s interfaces.State) error {
 
 	if s.GetConfig().IsServer && p.options.RequireSignature {
 		if _, err := io.ReadFull(rand.Reader, p.salt[:]); err != nil {
-			p.state = types.SessionStateError
+			p.setState(types.SessionStateError)
 			return err
 		}
 
 		if _, err := io.ReadFull(rand.Reader, p.sessionID[:]); err != nil {
-			p.state = types.SessionStateError
+			p.setState(types.SessionStateError)
 			return err
 		}
 
@@ -59,34 +76,49 @@ func (p *Curve25519Protocol) Init(s interfaces.State) error {
 		}
 
 		if err := s.WriteMessage(msg); err != nil {
-			p.state = types.SessionStateError
+			p.setState(types.SessionStateError)
 			return err
 		}
 	}
 
-	p.state = types.SessionStateInitial
+	p.setState(types.SessionStateInitial)
 	return nil
 }
 
 func (p *Curve25519Protocol) GetKeys() (encKey types.Key, decKey types.Key, err error) {
-	if p.state != types.SessionStateCompleted {
+	if p.GetState() != types.SessionStateCompleted {
 		return types.Key{}, types.Key{}, encryptionerr.ErrExchangeNotComplete
 	}
 
 	return p.encKey, p.decKey, nil
 }
 
+// GetSessionID returns the encryption session; the server generates it and sends it to the client with Init
+func (p *Curve25519Protocol) GetSessionID() types.EncryptionSessionID {
+	return p.sessionID
+}
+
 func (p *Curve25519Protocol) GetState() types.SessionState {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
 	return p.state
 }
 
+func (p *Curve25519Protocol) setState(state types.SessionState) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.state = state
+}
+
 func (p *Curve25519Protocol) IsComplete() bool {
-	return p.state == types.SessionStateCompleted
+	return p.GetState() == types.SessionStateCompleted
 }
 
 func (p *Curve25519Protocol) Process(msg interfaces.CanProcess, s interfaces.State) error {
 	if err := msg.Process(p, s); err != nil {
-		p.state = types.SessionStateError
+		p.setState(types.SessionStateError)
 		return err
 	}
 
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/hkdf"
@@ -23,9 +24,23 @@ type Session struct {
 type Manager struct {
 	registry map[types.KeyExchangeProtocol]ProtocolFactory
 	sessions map[types.KeyExchangeSessionID]*Session
+	mux      sync.RWMutex
+}
+
+// NewManager creates the key exchange manager, with the protocols of this package registered
+func NewManager() *Manager {
+	return &Manager{
+		registry: map[types.KeyExchangeProtocol]ProtocolFactory{
+			Curve25519Ed25519: NewCurve25519Protocol,
+		},
+		sessions: make(map[types.KeyExchangeSessionID]*Session),
+	}
 }
 
 func (m *Manager) Init(s interfaces.State, options ...interfaces.ProtocolFactoryOption) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
 	sessionID := s.GenerateKeyExchangeSessionID()
 	_, exists := m.sessions[sessionID]
 	if exists {
@@ -56,8 +71,10 @@ func (m *Manager) Init(s interfaces.State, options ...interfaces.ProtocolFactory
 }
 
 func (m *Manager) Finalise(s interfaces.State) error {
-	sessionID := s.GetKeyExchangeSessionID()
-	session, exists := m.sessions[sessionID]
+	m.mux.RLock()
+	session, exists := m.sessions[s.GetKeyExchangeSessionID()]
+	m.mux.RUnlock()
+
 	if !exists {
 		return encryptionerr.ErrExchangeNotComplete
 	}
@@ -77,11 +94,29 @@ func (m *Manager) Finalise(s interfaces.State) error {
 		return err
 	}
 
-	return ss.SetKeys(encKey, decKey)
+	// NOTE: THE SESSION ID IS AUTHENTICATED WITH EVERY ENCRYPTED MESSAGE; BOTH PEERS MUST USE THE AGREED ONE
+	if getter, ok := session.protocol.(interfaces.SessionIDGetter); ok {
+		if setter, ok := s.(interfaces.SessionIDSetter); ok {
+			setter.SetSessionID(getter.GetSessionID())
+		}
+	}
+
+	if err := ss.SetKeys(encKey, decKey); err != nil {
+		return err
+	}
+
+	m.mux.Lock()
+	session.completedAt = time.Now()
+	m.mux.Unlock()
+
+	return nil
 }
 
 func (m *Manager) Process(msg interfaces.CanProcess, s interfaces.State) error {
+	m.mux.RLock()
 	session, exists := m.sessions[s.GetKeyExchangeSessionID()]
+	m.mux.RUnlock()
+
 	if !exists {
 		return encryptionerr.ErrSessionNotFound
 	}
@@ -94,6 +129,20 @@ func (m *Manager) Process(msg interfaces.CanProcess, s interfaces.State) error {
 	return processor.Process(msg, s)
 }
 
+// Remove drops the key exchange session of the state, whether complete or not
+func (m *Manager) Remove(s interfaces.State) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	sessionID := s.GetKeyExchangeSessionID()
+	if _, exists := m.sessions[sessionID]; !exists {
+		return encryptionerr.ErrSessionNotFound
+	}
+
+	delete(m.sessions, sessionID)
+	return nil
+}
+
 // Derive generates encryption keys from shared secret
 func Derive(shared []byte, salt types.Salt, info string) (types.Key, types.Key, error) {
 	hkdfReader := hkdf.New(sha256.New, shared, salt[:], []byte(info))
@@ -0,0 +1,24 @@
+package encrypt
+
+import (
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptor"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/keyexchange"
+)
+
+// Registrations returns the encrypted envelope and the key exchange messages
+// paired with their protocols.
+func Registrations() []message.Registration {
+	return []message.Registration{
+		message.Type[encryptor.EncryptedMessage](encryptor.EncryptedMessageProtocol),
+		message.Type[keyexchange.Init](keyexchange.InitProtocol),
+		message.Type[keyexchange.Response](keyexchange.ResponseProtocol),
+		message.Type[keyexchange.Done](keyexchange.DoneProtocol),
+		message.Type[keyexchange.DoneResponse](keyexchange.DoneResponseProtocol),
+	}
+}
+
+// RegisterMessages registers all the encryption messages in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
@@ -0,0 +1,43 @@
+package encrypt
+
+import (
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/config"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptionerr"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/keyprovider"
+)
+
+type Option = func(*Interceptor) error
+
+// WithConfig replaces the default configuration (see config.DefaultConfig)
+func WithConfig(c config.Config) Option {
+	return func(i *Interceptor) error {
+		if c.RequireEncryption && c.DisableEncryption {
+			return fmt.Errorf("encryption cannot be both required and disabled; err: %w", encryptionerr.ErrInvalidConfig)
+		}
+
+		i.config = c
+		return nil
+	}
+}
+
+// WithKeyProvider sets the provider of the keys signing and verifying the key exchange
+func WithKeyProvider(provider keyprovider.KeyProvider) Option {
+	return func(i *Interceptor) error {
+		if provider == nil {
+			return fmt.Errorf("key provider must not be nil; err: %w", encryptionerr.ErrInvalidProvider)
+		}
+
+		i.keyProvider = provider
+		return nil
+	}
+}
+
+// WithNonceValidator sets the validator protecting the encrypted messages against replays
+func WithNonceValidator(validator NonceValidator) Option {
+	return func(i *Interceptor) error {
+		i.nonceValidator = validator
+		return nil
+	}
+}
@@ -67,18 +67,18 @@ func (s *State) GetConnection() interceptor.Connection {
 	return s.connection
 }
 
+// WriteMessage writes the message to the connection beneath the encryption interceptor; the message is not encrypted
 func (s *State) WriteMessage(msg interceptor.Message) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	// TODO: MANAGE CLIENT DISCOVERY
-	return s.writer.Write(s.connection, msg)
+	return s.writer.Write(s.ctx, s.connection, msg)
 }
 
-func (s *State) ReadMessage(msg interceptor.Message) error {
-	s.mux.Lock()
-	defer s.mux.Unlock()
-
-	return s.reader.Read()
+// ReadMessage reads the next message from the connection beneath the encryption interceptor; the message is not decrypted
+func (s *State) ReadMessage() (message.Message, error) {
+	// NOTE: THE LOCK IS NOT HELD; THE READ BLOCKS UNTIL A MESSAGE ARRIVES
+	return s.reader.Read(s.ctx, s.connection)
 }
 
 func (s *State) GetKeyExchangeSessionID() types.KeyExchangeSessionID {
@@ -102,6 +102,28 @@ func (s *State) SetKeys(encKey, decKey types.Key) error {
 	return s.encryptor.SetKeys(encKey, decKey)
 }
 
+// SetSessionID sets the encryption session agreed in the key exchange
+func (s *State) SetSessionID(id types.EncryptionSessionID) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.encryptSessionID = id
+	s.encryptor.SetSessionID(id)
+}
+
+func (s *State) Encrypt(msg message.Message) (message.Message, error) {
+	return s.encryptor.Encrypt(msg)
+}
+
 func (s *State) Decrypt(msg message.Message) (message.Message, error) {
 	return s.encryptor.Decrypt(msg)
 }
+
+// Close cancels the state's context and discards the session keys
+func (s *State) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.cancel()
+	return s.encryptor.Close()
+}
@@ -14,6 +14,12 @@ type Manager struct {
 	mux    sync.RWMutex
 }
 
+func NewManager() *Manager {
+	return &Manager{
+		states: make(map[interceptor.Connection]interfaces.State),
+	}
+}
+
 func (m *Manager) GetState(connection interceptor.Connection) (interfaces.State, error) {
 	m.mux.RLock()
 	defer m.mux.RUnlock()
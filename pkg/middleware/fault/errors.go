@@ -0,0 +1,9 @@
+package fault
+
+import "errors"
+
+var (
+	ErrInjectedDisconnect = errors.New("injected disconnect")
+	ErrInjectedCorruption = errors.New("injected corruption")
+	ErrNotBound           = errors.New("connection not bound to the fault interceptor")
+)
@@ -0,0 +1,59 @@
+package fault
+
+import (
+	"context"
+	"math"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// InterceptorName names the fault interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Fault
+
+type InterceptorFactory struct {
+	injector *Injector
+	err      error
+}
+
+// NewInterceptorFactory creates the factory and the injector shared by its interceptors.
+// NOTE: THE OPTIONS ARE APPLIED TO THE SHARED INJECTOR HERE; AN INVALID OPTION FAILS NewInterceptor
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	f := &InterceptorFactory{injector: newInjector()}
+
+	for _, option := range options {
+		if err := option(f.injector); err != nil {
+			f.err = err
+			break
+		}
+	}
+
+	return f
+}
+
+// Injector returns the injector of the interceptors created by the factory; use it to switch faults at runtime
+func (f *InterceptorFactory) Injector() *Injector {
+	return f.injector
+}
+
+// Descriptor places fault as close to the socket as possible so that the faults look like network faults to
+// every other interceptor; corrupted messages are written to the connection directly.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Priority: math.MaxInt,
+	}
+}
+
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		injector:        f.injector,
+		connections:     make(map[interceptor.Connection]*state),
+	}, nil
+}
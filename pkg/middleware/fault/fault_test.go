@@ -0,0 +1,481 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	pingProtocol message.Protocol = "test:ping"
+	pongProtocol message.Protocol = "test:pong"
+)
+
+type ping struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *ping) GetProtocol() message.Protocol {
+	return pingProtocol
+}
+
+type pong struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *pong) GetProtocol() message.Protocol {
+	return pongProtocol
+}
+
+func newPing(t *testing.T, text string) *ping {
+	t.Helper()
+
+	msg := &ping{Text: text}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	return msg
+}
+
+func newPong(t *testing.T, text string) *pong {
+	t.Helper()
+
+	msg := &pong{Text: text}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	return msg
+}
+
+func text(msg message.Message) string {
+	switch m := msg.(type) {
+	case *ping:
+		return m.Text
+	case *pong:
+		return m.Text
+	default:
+		return ""
+	}
+}
+
+type testConnection struct {
+	raw    [][]byte
+	closed bool
+	mux    sync.Mutex
+}
+
+func (c *testConnection) Write(_ context.Context, p []byte) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.raw = append(c.raw, slices.Clone(p))
+	return nil
+}
+
+func (c *testConnection) Read(_ context.Context) ([]byte, error) { return nil, nil }
+
+func (c *testConnection) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// feed is the reader and writer beneath the interceptor; reads return the queued messages in order
+type feed struct {
+	queue   []message.Message
+	written []message.Message
+	mux     sync.Mutex
+}
+
+func (f *feed) Read(_ context.Context, _ interceptor.Connection) (message.Message, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if len(f.queue) == 0 {
+		return nil, nil
+	}
+
+	msg := f.queue[0]
+	f.queue = f.queue[1:]
+	return msg, nil
+}
+
+func (f *feed) Write(_ context.Context, _ interceptor.Connection, msg message.Message) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.written = append(f.written, msg)
+	return nil
+}
+
+func (f *feed) texts() []string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	texts := make([]string, 0, len(f.written))
+	for _, msg := range f.written {
+		texts = append(texts, text(msg))
+	}
+
+	return texts
+}
+
+func newRegistry(t *testing.T) message.Registry {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	message.MustRegister(registry, pingProtocol, message.EmptyFactoryFunc(func() (message.Message, error) { return &ping{}, nil }))
+	message.MustRegister(registry, pongProtocol, message.EmptyFactoryFunc(func() (message.Message, error) { return &pong{}, nil }))
+
+	return registry
+}
+
+// bound is an interceptor bound to a test connection
+type bound struct {
+	interceptor *Interceptor
+	connection  *testConnection
+	feed        *feed
+	writer      interceptor.Writer
+	reader      interceptor.Reader
+}
+
+func bind(t *testing.T, factory *InterceptorFactory) *bound {
+	t.Helper()
+
+	i, err := factory.NewInterceptor(context.Background(), "server", newRegistry(t))
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	b := &bound{interceptor: i.(*Interceptor), connection: &testConnection{}, feed: &feed{}}
+
+	writer, reader, err := b.interceptor.BindSocketConnection(b.connection, b.feed, b.feed)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	b.writer = b.interceptor.InterceptSocketWriter(writer)
+	b.reader = b.interceptor.InterceptSocketReader(reader)
+
+	t.Cleanup(func() { _ = b.interceptor.Close() })
+
+	return b
+}
+
+func (b *bound) write(t *testing.T, msgs ...message.Message) {
+	t.Helper()
+
+	for _, msg := range msgs {
+		if err := b.writer.Write(context.Background(), b.connection, msg); err != nil {
+			t.Fatalf("Write(%s) error = %v", text(msg), err)
+		}
+	}
+}
+
+// drain reads until the feed is empty and returns the texts of the messages delivered
+func (b *bound) drain(t *testing.T) []string {
+	t.Helper()
+
+	texts := make([]string, 0)
+	for {
+		msg, err := b.reader.Read(context.Background(), b.connection)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if msg == nil {
+			return texts
+		}
+		texts = append(texts, text(msg))
+	}
+}
+
+func TestOutboundFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   Fault
+		written []string
+	}{
+		{name: "drop", fault: FaultDrop, written: []string{}},
+		{name: "duplicate", fault: FaultDuplicate, written: []string{"a", "a", "b", "b"}},
+		{name: "delay", fault: FaultDelay, written: []string{"a", "b"}},
+		{name: "reorder", fault: FaultReorder, written: []string{"b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bind(t, NewInterceptorFactory(WithSeed(1), WithRules(Rule{Fault: tt.fault, Probability: 1})))
+
+			b.write(t, newPing(t, "a"), newPing(t, "b"))
+
+			if got := b.feed.texts(); !slices.Equal(got, tt.written) {
+				t.Errorf("written = %v; want %v", got, tt.written)
+			}
+
+			if got := b.interceptor.injector.Stats()[tt.fault.String()]; got != 2 {
+				t.Errorf("Stats()[%s] = %d; want 2", tt.fault, got)
+			}
+		})
+	}
+}
+
+func TestInboundFaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     Fault
+		delivered []string
+	}{
+		{name: "drop", fault: FaultDrop, delivered: []string{}},
+		{name: "duplicate", fault: FaultDuplicate, delivered: []string{"a", "a", "b", "b", "c", "c"}},
+		{name: "delay", fault: FaultDelay, delivered: []string{"a", "b", "c"}},
+		{name: "reorder", fault: FaultReorder, delivered: []string{"b", "a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bind(t, NewInterceptorFactory(WithSeed(1), WithRules(Rule{Fault: tt.fault, Probability: 1, Direction: Inbound})))
+			b.feed.queue = []message.Message{newPing(t, "a"), newPing(t, "b"), newPing(t, "c")}
+
+			got := b.drain(t)
+			if tt.fault == FaultReorder {
+				// NOTE: THE LAST MESSAGE STAYS PARKED UNTIL ANOTHER ONE IS READ
+				got = append(got, text(b.interceptor.connections[b.connection].parked))
+			}
+
+			if !slices.Equal(got, tt.delivered) {
+				t.Errorf("delivered = %v; want %v", got, tt.delivered)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	const delay = 20 * time.Millisecond
+
+	b := bind(t, NewInterceptorFactory(WithRules(Rule{Fault: FaultDelay, Probability: 1, Delay: delay, Jitter: delay})))
+
+	start := time.Now()
+	b.write(t, newPing(t, "a"))
+
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Write() took %s; want at least %s", elapsed, delay)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.writer.Write(ctx, b.connection, newPing(t, "b")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() with cancelled context error = %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestReorderTimeout(t *testing.T) {
+	b := bind(t, NewInterceptorFactory(
+		WithRules(Rule{Fault: FaultReorder, Probability: 1}),
+		WithReorderTimeout(10*time.Millisecond),
+	))
+
+	b.write(t, newPing(t, "a"))
+
+	if got := b.feed.texts(); len(got) != 0 {
+		t.Fatalf("written = %v; want the message held back", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(b.feed.texts()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := b.feed.texts(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("written = %v; want [a] after the reorder timeout", got)
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	t.Run("outbound", func(t *testing.T) {
+		b := bind(t, NewInterceptorFactory(WithSeed(7), WithRules(Rule{Fault: FaultCorrupt, Probability: 1})))
+
+		msg := newPing(t, "a")
+		b.write(t, msg)
+
+		if got := b.feed.texts(); len(got) != 0 {
+			t.Errorf("written to the writer = %v; want the corrupted bytes written to the connection", got)
+		}
+
+		original, err := message.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		if len(b.connection.raw) != 1 || len(b.connection.raw[0]) != len(original) || slices.Equal(b.connection.raw[0], original) {
+			t.Errorf("connection got %q; want %q with a flipped bit", b.connection.raw, original)
+		}
+	})
+
+	t.Run("inbound", func(t *testing.T) {
+		b := bind(t, NewInterceptorFactory(WithSeed(7), WithRules(Rule{Fault: FaultCorrupt, Probability: 1, Direction: Inbound})))
+
+		for range 20 {
+			b.feed.queue = []message.Message{newPing(t, "abcdef")}
+
+			msg, err := b.reader.Read(context.Background(), b.connection)
+			if err != nil {
+				if !errors.Is(err, ErrInjectedCorruption) {
+					t.Fatalf("Read() error = %v; want %v", err, ErrInjectedCorruption)
+				}
+				continue
+			}
+
+			original, _ := message.Marshal(newPing(t, "abcdef"))
+			got, _ := message.Marshal(msg)
+			if slices.Equal(original, got) {
+				t.Errorf("Read() = %s; want a corrupted message", got)
+			}
+		}
+	})
+}
+
+func TestDisconnect(t *testing.T) {
+	b := bind(t, NewInterceptorFactory(WithRules(Rule{Fault: FaultDisconnect, Probability: 1})))
+
+	if err := b.writer.Write(context.Background(), b.connection, newPing(t, "a")); !errors.Is(err, ErrInjectedDisconnect) {
+		t.Errorf("Write() error = %v; want %v", err, ErrInjectedDisconnect)
+	}
+
+	if !b.connection.closed {
+		t.Error("connection not closed")
+	}
+
+	b.feed.queue = []message.Message{newPing(t, "b")}
+	if _, err := b.reader.Read(context.Background(), b.connection); !errors.Is(err, ErrInjectedDisconnect) {
+		t.Errorf("Read() error = %v; want %v", err, ErrInjectedDisconnect)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	run := func(seed uint64) []string {
+		b := bind(t, NewInterceptorFactory(WithSeed(seed), WithRules(Rule{Fault: FaultDrop, Probability: 0.5})))
+
+		for _, s := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p"} {
+			b.write(t, newPing(t, s))
+		}
+
+		return b.feed.texts()
+	}
+
+	first, second, other := run(42), run(42), run(43)
+
+	if !slices.Equal(first, second) {
+		t.Errorf("same seed wrote %v and %v; want the same messages", first, second)
+	}
+
+	if slices.Equal(first, other) {
+		t.Errorf("seeds 42 and 43 both wrote %v; want different messages", first)
+	}
+
+	if len(first) == 0 || len(first) == 16 {
+		t.Errorf("written %v; want about half of the messages dropped", first)
+	}
+}
+
+func TestMatching(t *testing.T) {
+	b := bind(t, NewInterceptorFactory(WithRules(
+		Rule{Fault: FaultDrop, Probability: 1, Direction: Outbound, Protocols: []message.Protocol{pongProtocol}},
+	)))
+
+	b.write(t, newPing(t, "a"), newPong(t, "b"), newPing(t, "c"))
+
+	if got := b.feed.texts(); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("written = %v; want [a c]", got)
+	}
+
+	b.feed.queue = []message.Message{newPong(t, "d")}
+	if got := b.drain(t); !slices.Equal(got, []string{"d"}) {
+		t.Errorf("delivered = %v; want [d]; the rule is outbound only", got)
+	}
+}
+
+func TestRuntimeSwitch(t *testing.T) {
+	factory := NewInterceptorFactory(WithDisabled(), WithRules(Rule{Fault: FaultDrop, Probability: 1}))
+	b := bind(t, factory)
+	injector := factory.Injector()
+
+	b.write(t, newPing(t, "a"))
+
+	injector.Enable()
+	b.write(t, newPing(t, "b"))
+
+	injector.Disable()
+	b.write(t, newPing(t, "c"))
+
+	if err := injector.SetRules(Rule{Fault: FaultDuplicate, Probability: 1}); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	injector.Enable()
+	b.write(t, newPing(t, "d"))
+
+	if got := b.feed.texts(); !slices.Equal(got, []string{"a", "c", "d", "d"}) {
+		t.Errorf("written = %v; want [a c d d]", got)
+	}
+
+	stats := injector.Stats()
+	if stats[FaultDrop.String()] != 1 || stats[FaultDuplicate.String()] != 1 {
+		t.Errorf("Stats() = %v; want one drop and one duplicate", stats)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		option Option
+	}{
+		{name: "probability above one", option: WithRules(Rule{Fault: FaultDrop, Probability: 1.5})},
+		{name: "negative probability", option: WithRules(Rule{Fault: FaultDrop, Probability: -0.1})},
+		{name: "unknown fault", option: WithRules(Rule{Fault: FaultDisconnect + 1, Probability: 1})},
+		{name: "unknown direction", option: WithRules(Rule{Fault: FaultDrop, Direction: Outbound + 1})},
+		{name: "negative delay", option: WithRules(Rule{Fault: FaultDelay, Delay: -time.Second})},
+		{name: "zero reorder timeout", option: WithReorderTimeout(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewInterceptorFactory(tt.option).NewInterceptor(context.Background(), "server", nil)
+			if err == nil {
+				t.Error("NewInterceptor() error = nil; want an error")
+			}
+		})
+	}
+}
+
+func TestNotBound(t *testing.T) {
+	i, err := NewInterceptorFactory().NewInterceptor(context.Background(), "server", nil)
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	f := &feed{}
+	connection := &testConnection{}
+
+	if err := i.InterceptSocketWriter(f).Write(context.Background(), connection, newPing(t, "a")); !errors.Is(err, ErrNotBound) {
+		t.Errorf("Write() error = %v; want %v", err, ErrNotBound)
+	}
+
+	if _, err := i.InterceptSocketReader(f).Read(context.Background(), connection); !errors.Is(err, ErrNotBound) {
+		t.Errorf("Read() error = %v; want %v", err, ErrNotBound)
+	}
+}
@@ -0,0 +1,149 @@
+package fault
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Injector decides which faults are injected. It is shared by all the interceptors created by a factory,
+// so that faults can be switched on and off, and the rules changed, while the connections are running.
+type Injector struct {
+	enabled        atomic.Bool
+	rules          []Rule
+	rng            *rand.Rand
+	reorderTimeout time.Duration
+	injected       [FaultDisconnect + 1]atomic.Uint64
+	mux            sync.Mutex
+}
+
+func newInjector() *Injector {
+	i := &Injector{
+		rules:          make([]Rule, 0),
+		reorderTimeout: DefaultReorderTimeout,
+	}
+
+	i.enabled.Store(true)
+	i.Reseed(rand.Uint64())
+
+	return i
+}
+
+// Enable starts injecting faults
+func (i *Injector) Enable() {
+	i.enabled.Store(true)
+}
+
+// Disable stops injecting faults; messages held back for reordering are still released
+func (i *Injector) Disable() {
+	i.enabled.Store(false)
+}
+
+// Enabled reports if faults are injected
+func (i *Injector) Enabled() bool {
+	return i.enabled.Load()
+}
+
+// SetRules replaces the rules
+func (i *Injector) SetRules(rules ...Rule) error {
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.rules = slices.Clone(rules)
+	return nil
+}
+
+// Rules returns the current rules
+func (i *Injector) Rules() []Rule {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	return slices.Clone(i.rules)
+}
+
+// Reseed restarts the random draws from the seed
+func (i *Injector) Reseed(seed uint64) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// Stats returns the number of injected faults, by fault
+func (i *Injector) Stats() map[string]uint64 {
+	stats := make(map[string]uint64, len(faults))
+	for _, fault := range faults {
+		stats[fault.String()] = i.injected[fault].Load()
+	}
+
+	return stats
+}
+
+// plan is the set of faults drawn for a message
+type plan struct {
+	faults uint8
+	delay  time.Duration
+	bit    uint64 // bit selects the bit flipped by FaultCorrupt
+}
+
+func (p plan) has(fault Fault) bool {
+	return p.faults&(1<<fault) != 0
+}
+
+func (p plan) none() bool {
+	return p.faults == 0
+}
+
+// draw rolls every matching rule for the message.
+// NOTE: EVERY MATCHING RULE DRAWS, HIT OR NOT, SO THAT THE SAME SEED AND MESSAGES ALWAYS GIVE THE SAME FAULTS
+func (i *Injector) draw(direction Direction, protocol message.Protocol) plan {
+	var p plan
+
+	if !i.Enabled() {
+		return p
+	}
+
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	for _, rule := range i.rules {
+		if !rule.matches(direction, protocol) {
+			continue
+		}
+
+		if i.rng.Float64() >= rule.Probability {
+			continue
+		}
+
+		p.faults |= 1 << rule.Fault
+
+		switch rule.Fault {
+		case FaultDelay:
+			delay := rule.Delay
+			if rule.Jitter > 0 {
+				delay += time.Duration(i.rng.Int64N(int64(rule.Jitter)))
+			}
+			p.delay = max(p.delay, delay)
+		case FaultCorrupt:
+			p.bit = i.rng.Uint64()
+		}
+	}
+
+	for _, fault := range faults {
+		if p.has(fault) {
+			i.injected[fault].Add(1)
+		}
+	}
+
+	return p
+}
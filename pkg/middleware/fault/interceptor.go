@@ -0,0 +1,329 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor injects the faults drawn by its Injector into the messages written to and read from its
+// connections.
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	injector    *Injector
+	connections map[interceptor.Connection]*state
+	mux         sync.RWMutex
+}
+
+// state holds the messages of a single connection waiting to be reordered or duplicated
+type state struct {
+	held    message.Message // held is the outbound message waiting for the next one
+	timer   *time.Timer
+	parked  message.Message // parked is the inbound message waiting for the next one
+	pending []message.Message
+	mux     sync.Mutex
+}
+
+func (i *Interceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if _, exists := i.connections[connection]; exists {
+		return nil, nil, interceptor.ErrConnectionExists
+	}
+
+	i.connections[connection] = &state{pending: make([]message.Message, 0)}
+
+	return writer, reader, nil
+}
+
+func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		s, err := i.getState(connection)
+		if err != nil {
+			return err
+		}
+
+		if msg == nil {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		p := i.injector.draw(Outbound, msg.GetProtocol())
+		if p.none() {
+			return i.write(ctx, connection, s, writer, msg)
+		}
+
+		if p.has(FaultDisconnect) {
+			return i.disconnect(connection, msg.GetProtocol())
+		}
+
+		if p.has(FaultDrop) {
+			return nil
+		}
+
+		if p.has(FaultCorrupt) {
+			// NOTE: THE CORRUPTED BYTES CANNOT BE A MESSAGE; THEY GO STRAIGHT TO THE CONNECTION
+			return message.Encode(msg, func(data []byte) error {
+				flip(data, p.bit)
+				return connection.Write(ctx, data)
+			})
+		}
+
+		if p.has(FaultDelay) {
+			if err := sleep(ctx, p.delay); err != nil {
+				return err
+			}
+		}
+
+		if p.has(FaultReorder) && i.hold(connection, s, writer, msg) {
+			return nil
+		}
+
+		if err := i.write(ctx, connection, s, writer, msg); err != nil {
+			return err
+		}
+
+		if p.has(FaultDuplicate) {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		return nil
+	})
+}
+
+// write writes the message followed by the message held back before it, if any
+func (i *Interceptor) write(ctx context.Context, connection interceptor.Connection, s *state, writer interceptor.Writer, msg message.Message) error {
+	err := writer.Write(ctx, connection, msg)
+
+	if held := s.release(); held != nil {
+		if heldErr := writer.Write(ctx, connection, held); err == nil {
+			err = heldErr
+		}
+	}
+
+	return err
+}
+
+// hold holds the message back until the next message is written or the reorder timeout expires.
+// It reports false if another message is already held back.
+func (i *Interceptor) hold(connection interceptor.Connection, s *state, writer interceptor.Writer, msg message.Message) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.held != nil {
+		return false
+	}
+
+	s.held = msg
+	s.timer = time.AfterFunc(i.injector.reorderTimeout, func() {
+		if held := s.release(); held != nil {
+			// NOTE: NO ONE IS WAITING ON THE WRITE ANYMORE; A FAILED WRITE IS A LOST MESSAGE
+			_ = writer.Write(i.Ctx(), connection, held)
+		}
+	})
+
+	return true
+}
+
+func (s *state) release() message.Message {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	held := s.held
+	s.held = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	return held
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		s, err := i.getState(connection)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			if msg := s.pop(); msg != nil {
+				return msg, nil
+			}
+
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			p := i.injector.draw(Inbound, msg.GetProtocol())
+
+			if p.has(FaultDisconnect) {
+				return nil, i.disconnect(connection, msg.GetProtocol())
+			}
+
+			if p.has(FaultDrop) {
+				continue
+			}
+
+			if p.has(FaultCorrupt) {
+				if msg, err = i.corrupt(msg, p.bit); err != nil {
+					return nil, err
+				}
+			}
+
+			if p.has(FaultDelay) {
+				if err := sleep(ctx, p.delay); err != nil {
+					return nil, err
+				}
+			}
+
+			// NOTE: A PARKED MESSAGE IS DELIVERED AFTER THE NEXT ONE READ; IT WAITS AS LONG AS THE PEER IS SILENT
+			if p.has(FaultReorder) && s.park(msg) {
+				continue
+			}
+
+			if p.has(FaultDuplicate) {
+				s.push(msg)
+			}
+
+			s.unpark()
+
+			return msg, nil
+		}
+	})
+}
+
+// corrupt flips a bit of the serialized message and decodes it back
+func (i *Interceptor) corrupt(msg message.Message, bit uint64) (message.Message, error) {
+	data, err := message.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error while corrupting %s; err: %w", msg.GetProtocol(), err)
+	}
+
+	flip(data, bit)
+
+	registry := i.GetMessageRegistry()
+	if registry == nil {
+		return nil, fmt.Errorf("error while reading %s; err: %w", msg.GetProtocol(), ErrInjectedCorruption)
+	}
+
+	corrupted, err := registry.UnmarshalRaw(data)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading %s; %w; err: %w", msg.GetProtocol(), ErrInjectedCorruption, err)
+	}
+
+	return corrupted, nil
+}
+
+func (s *state) park(msg message.Message) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.parked != nil {
+		return false
+	}
+
+	s.parked = msg
+	return true
+}
+
+// unpark queues the parked message behind the message being delivered
+func (s *state) unpark() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.parked != nil {
+		s.pending = append(s.pending, s.parked)
+		s.parked = nil
+	}
+}
+
+func (s *state) push(msg message.Message) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.pending = append(s.pending, msg)
+}
+
+func (s *state) pop() message.Message {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	msg := s.pending[0]
+	s.pending = s.pending[1:]
+
+	return msg
+}
+
+func (i *Interceptor) disconnect(connection interceptor.Connection, protocol message.Protocol) error {
+	_ = connection.Close()
+	return fmt.Errorf("error while processing %s; err: %w", protocol, ErrInjectedDisconnect)
+}
+
+// flip flips a bit of the data
+func flip(data []byte, bit uint64) {
+	if len(data) == 0 {
+		return
+	}
+
+	data[(bit/8)%uint64(len(data))] ^= 1 << (bit % 8)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (i *Interceptor) getState(connection interceptor.Connection) (*state, error) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	s, exists := i.connections[connection]
+	if !exists {
+		return nil, ErrNotBound
+	}
+
+	return s, nil
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if s, exists := i.connections[connection]; exists {
+		s.release()
+		delete(i.connections, connection)
+	}
+}
+
+func (i *Interceptor) Close() error {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	for _, s := range i.connections {
+		s.release()
+	}
+	i.connections = make(map[interceptor.Connection]*state)
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package fault
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultReorderTimeout is the longest an outbound message is held back to be reordered
+const DefaultReorderTimeout = 100 * time.Millisecond
+
+// Fault is a kind of fault the interceptor injects
+type Fault uint8
+
+const (
+	// FaultDelay holds the message for Rule.Delay plus up to Rule.Jitter
+	FaultDelay Fault = iota
+	// FaultDrop loses the message
+	FaultDrop
+	// FaultDuplicate delivers the message twice
+	FaultDuplicate
+	// FaultReorder swaps the message with the next one of the connection in the same direction
+	FaultReorder
+	// FaultCorrupt flips a byte of the serialized message
+	FaultCorrupt
+	// FaultDisconnect closes the connection
+	FaultDisconnect
+)
+
+// faults lists the faults in the order they are applied when a message draws several of them
+var faults = []Fault{FaultDisconnect, FaultDrop, FaultCorrupt, FaultDelay, FaultReorder, FaultDuplicate}
+
+func (f Fault) String() string {
+	switch f {
+	case FaultDelay:
+		return "delay"
+	case FaultDrop:
+		return "drop"
+	case FaultDuplicate:
+		return "duplicate"
+	case FaultReorder:
+		return "reorder"
+	case FaultCorrupt:
+		return "corrupt"
+	case FaultDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("fault(%d)", uint8(f))
+	}
+}
+
+// Direction selects the path a rule applies to
+type Direction uint8
+
+const (
+	Both Direction = iota
+	Inbound
+	Outbound
+)
+
+// Rule injects the fault in the messages it matches with the given probability
+type Rule struct {
+	Fault       Fault
+	Probability float64 // Probability is between 0 and 1
+	Direction   Direction
+	Protocols   []message.Protocol // Protocols limits the rule to the given protocols; all if empty
+	Delay       time.Duration      // Delay is the delay of FaultDelay
+	Jitter      time.Duration      // Jitter is the maximum random delay added to Delay
+}
+
+func (r Rule) validate() error {
+	if r.Fault > FaultDisconnect {
+		return fmt.Errorf("unknown fault %s", r.Fault)
+	}
+
+	if r.Probability < 0 || r.Probability > 1 {
+		return fmt.Errorf("fault %s probability must be between 0 and 1; got %f", r.Fault, r.Probability)
+	}
+
+	if r.Direction > Outbound {
+		return fmt.Errorf("fault %s has an unknown direction %d", r.Fault, r.Direction)
+	}
+
+	if r.Delay < 0 || r.Jitter < 0 {
+		return fmt.Errorf("fault %s delay and jitter must not be negative", r.Fault)
+	}
+
+	return nil
+}
+
+func (r Rule) matches(direction Direction, protocol message.Protocol) bool {
+	if r.Direction != Both && r.Direction != direction {
+		return false
+	}
+
+	return len(r.Protocols) == 0 || slices.Contains(r.Protocols, protocol)
+}
+
+type Option = func(*Injector) error
+
+// WithRules sets the rules of the injector
+func WithRules(rules ...Rule) Option {
+	return func(i *Injector) error {
+		return i.SetRules(rules...)
+	}
+}
+
+// WithSeed seeds the random draws of the injector; the same seed and the same messages give the same faults
+func WithSeed(seed uint64) Option {
+	return func(i *Injector) error {
+		i.Reseed(seed)
+		return nil
+	}
+}
+
+// WithDisabled creates the injector disabled; see Injector.Enable
+func WithDisabled() Option {
+	return func(i *Injector) error {
+		i.Disable()
+		return nil
+	}
+}
+
+// WithReorderTimeout sets the longest an outbound message is held back to be reordered
+func WithReorderTimeout(timeout time.Duration) Option {
+	return func(i *Injector) error {
+		if timeout <= 0 {
+			return fmt.Errorf("reorder timeout must be positive; got %s", timeout)
+		}
+
+		i.reorderTimeout = timeout
+		return nil
+	}
+}
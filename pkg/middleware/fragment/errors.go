@@ -0,0 +1,11 @@
+package fragment
+
+import "errors"
+
+var (
+	ErrMemoryCapExceeded = errors.New("reassembly memory cap exceeded")
+	ErrTransferTimeout   = errors.New("transfer did not complete in time")
+	ErrInvalidFragment   = errors.New("fragment does not match its transfer")
+	ErrNotBound          = errors.New("connection not bound to the fragment interceptor")
+	ErrTooManyFragments  = errors.New("message needs more fragments than allowed")
+)
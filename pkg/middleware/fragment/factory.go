@@ -0,0 +1,54 @@
+package fragment
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// InterceptorName names the fragmentation interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Fragment
+
+// Descriptor places fragment after encrypt so that the encrypted envelopes, and not their plain
+// payloads, are split. It is the closest interceptor to the socket unless others are ordered after it.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		After:    []string{names.Encrypt},
+		Optional: []string{names.Encrypt},
+	}
+}
+
+// NewInterceptor creates the fragmentation interceptor. The fragments are read by the socket before they are
+// reassembled, so both ends register the fragment message in the registry of their socket (see
+// RegisterMessages); the reassembled messages are decoded with the given registry.
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		threshold:       DefaultThreshold,
+		fragmentSize:    DefaultFragmentSize,
+		timeout:         DefaultReassemblyTime,
+		maxBuffered:     DefaultMaxBufferedBytes,
+		reassemblers:    make(map[interceptor.Connection]*reassembler),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
@@ -0,0 +1,297 @@
+package fragment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const testProtocol message.Protocol = "test:message"
+
+type testMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *testMessage) GetProtocol() message.Protocol {
+	return testProtocol
+}
+
+func newTestMessage(t *testing.T, text string) *testMessage {
+	t.Helper()
+
+	msg := &testMessage{Text: text}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	return msg
+}
+
+type testConnection struct{}
+
+func (c *testConnection) Write(_ context.Context, _ []byte) error { return nil }
+func (c *testConnection) Read(_ context.Context) ([]byte, error)  { return nil, nil }
+func (c *testConnection) Close() error                            { return nil }
+
+// wire serializes the written messages and decodes them back on read, like a socket would
+type wire struct {
+	registry message.Registry
+	frames   [][]byte
+}
+
+func (w *wire) Write(_ context.Context, _ interceptor.Connection, msg message.Message) error {
+	data, err := message.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	w.frames = append(w.frames, data)
+	return nil
+}
+
+func (w *wire) Read(_ context.Context, _ interceptor.Connection) (message.Message, error) {
+	if len(w.frames) == 0 {
+		return nil, errors.New("no more frames")
+	}
+
+	frame := w.frames[0]
+	w.frames = w.frames[1:]
+
+	return w.registry.UnmarshalRaw(frame)
+}
+
+type progressRecorder struct {
+	progress []Progress
+	mux      sync.Mutex
+}
+
+func (r *progressRecorder) record(_ interceptor.Connection, progress Progress) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.progress = append(r.progress, progress)
+}
+
+func (r *progressRecorder) last() (Progress, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if len(r.progress) == 0 {
+		return Progress{}, false
+	}
+
+	return r.progress[len(r.progress)-1], true
+}
+
+func newTestInterceptor(t *testing.T, options ...Option) (*Interceptor, *wire, interceptor.Connection) {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+	message.MustRegister(registry, testProtocol, message.TypeFactory[testMessage]())
+
+	i, err := NewInterceptorFactory(options...).NewInterceptor(context.Background(), "test", registry)
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	w := &wire{registry: registry}
+	connection := &testConnection{}
+
+	if _, _, err := i.BindSocketConnection(connection, w, w); err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+	t.Cleanup(func() { i.UnBindSocketConnection(connection) })
+
+	return i.(*Interceptor), w, connection
+}
+
+func TestInterceptor_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantFragments int
+		reverse       bool
+	}{
+		{
+			name:          "small message is not fragmented",
+			text:          "hello",
+			wantFragments: 0,
+		},
+		{
+			name:          "large message is fragmented",
+			text:          strings.Repeat("x", 1000),
+			wantFragments: 9,
+		},
+		{
+			name:          "fragments arriving out of order",
+			text:          strings.Repeat("y", 1000),
+			wantFragments: 9,
+			reverse:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &progressRecorder{}
+			i, w, connection := newTestInterceptor(t, WithThreshold(256), WithFragmentSize(128), WithProgress(recorder.record))
+
+			writer := i.InterceptSocketWriter(w)
+			reader := i.InterceptSocketReader(w)
+
+			if err := writer.Write(context.Background(), connection, newTestMessage(t, tt.text)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			if tt.wantFragments == 0 && len(w.frames) != 1 {
+				t.Fatalf("frames = %d, want 1", len(w.frames))
+			}
+
+			if tt.wantFragments > 0 && len(w.frames) != tt.wantFragments {
+				t.Fatalf("frames = %d, want %d", len(w.frames), tt.wantFragments)
+			}
+
+			if tt.reverse {
+				for l, r := 0, len(w.frames)-1; l < r; l, r = l+1, r-1 {
+					w.frames[l], w.frames[r] = w.frames[r], w.frames[l]
+				}
+			}
+
+			msg, err := reader.Read(context.Background(), connection)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			m, ok := msg.(*testMessage)
+			if !ok || m.Text != tt.text {
+				t.Fatalf("Read() = %+v, want *testMessage with the original text", msg)
+			}
+
+			if tt.wantFragments == 0 {
+				return
+			}
+
+			progress, _ := recorder.last()
+			if !progress.Done() || progress.Direction != Inbound || progress.Bytes != progress.TotalBytes {
+				t.Errorf("last progress = %+v, want a completed inbound transfer", progress)
+			}
+		})
+	}
+}
+
+func TestInterceptor_MemoryCap(t *testing.T) {
+	i, w, connection := newTestInterceptor(t, WithThreshold(256), WithFragmentSize(128), WithMaxBufferedBytes(512))
+
+	if err := i.InterceptSocketWriter(w).Write(context.Background(), connection, newTestMessage(t, strings.Repeat("x", 1000))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if _, err := i.InterceptSocketReader(w).Read(context.Background(), connection); !errors.Is(err, ErrMemoryCapExceeded) {
+		t.Errorf("Read() error = %v, want %v", err, ErrMemoryCapExceeded)
+	}
+}
+
+func TestInterceptor_ReassemblyTimeout(t *testing.T) {
+	recorder := &progressRecorder{}
+	i, w, connection := newTestInterceptor(t, WithThreshold(256), WithFragmentSize(128), WithReassemblyTimeout(10*time.Millisecond), WithProgress(recorder.record))
+
+	if err := i.InterceptSocketWriter(w).Write(context.Background(), connection, newTestMessage(t, strings.Repeat("x", 1000))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// NOTE: ONLY THE FIRST FRAGMENT IS DELIVERED
+	w.frames = w.frames[:1]
+	if _, err := i.InterceptSocketReader(w).Read(context.Background(), connection); err == nil {
+		t.Fatalf("Read() expected error after the frames ran out, got nil")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if progress, ok := recorder.last(); ok && errors.Is(progress.Err, ErrTransferTimeout) {
+			r, err := i.getReassembler(connection)
+			if err != nil {
+				t.Fatalf("getReassembler() error = %v", err)
+			}
+
+			r.mux.Lock()
+			buffered := r.buffered
+			r.mux.Unlock()
+
+			if buffered != 0 {
+				t.Errorf("buffered = %d, want 0 after expiry", buffered)
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Errorf("transfer did not expire")
+}
+
+func TestInterceptor_HugeTotal(t *testing.T) {
+	i, w, connection := newTestInterceptor(t)
+
+	// NOTE: A FEW BYTES CLAIMING BILLIONS OF FRAGMENTS MUST NOT BE ALLOCATED FOR
+	huge, err := NewFragment("huge", 0, 4294967295, 1, testProtocol, []byte("x"))
+	if err != nil {
+		t.Fatalf("NewFragment() error = %v", err)
+	}
+
+	if err := huge.Validate(); err == nil {
+		t.Errorf("Validate() error = nil, want an error for a total above the size")
+	}
+
+	frame, err := message.Marshal(huge)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	w.frames = append(w.frames, frame)
+
+	if _, err := i.InterceptSocketReader(w).Read(context.Background(), connection); err == nil {
+		t.Errorf("Read() error = nil, want the fragment rejected")
+	}
+
+	// NOTE: THE REASSEMBLER BOUNDS THE FRAGMENTS ON ITS OWN, FOR THE FRAGMENTS NOT DECODED BY THE REGISTRY
+	r, err := i.getReassembler(connection)
+	if err != nil {
+		t.Fatalf("getReassembler() error = %v", err)
+	}
+
+	for name, fragment := range map[string]*Fragment{
+		"total above size": {TransferID: "a", Total: 4294967295, Size: 1, Protocol: testProtocol, Data: []byte("x")},
+		"total above max":  {TransferID: "b", Total: MaxFragments + 1, Size: 1 << 20, Protocol: testProtocol, Data: []byte("x")},
+	} {
+		if _, _, err := r.add(fragment); !errors.Is(err, ErrInvalidFragment) {
+			t.Errorf("%s: add() error = %v, want %v", name, err, ErrInvalidFragment)
+		}
+	}
+
+	if len(r.transfers) != 0 || r.buffered != 0 {
+		t.Errorf("reassembler kept %d transfers and %d bytes, want none", len(r.transfers), r.buffered)
+	}
+
+	small := newReassembler(8, time.Second, nil)
+	if _, _, err := small.add(&Fragment{TransferID: "c", Total: 16, Size: 32, Protocol: testProtocol, Data: []byte("x")}); !errors.Is(err, ErrInvalidFragment) {
+		t.Errorf("add() error = %v, want %v for more fragments than the memory cap holds", err, ErrInvalidFragment)
+	}
+}
+
+func TestInterceptor_TooManyFragments(t *testing.T) {
+	i, w, connection := newTestInterceptor(t, WithThreshold(1), WithFragmentSize(1))
+
+	err := i.InterceptSocketWriter(w).Write(context.Background(), connection, newTestMessage(t, strings.Repeat("x", MaxFragments)))
+	if !errors.Is(err, ErrTooManyFragments) {
+		t.Errorf("Write() error = %v, want %v", err, ErrTooManyFragments)
+	}
+}
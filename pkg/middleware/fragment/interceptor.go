@@ -0,0 +1,190 @@
+package fragment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor splits the messages whose serialized form is larger than the threshold into
+// Fragment messages and reassembles the received fragments back into the original message.
+// It is transparent to the interceptors above it; to fragment everything (including
+// encrypted envelopes) it should be the interceptor closest to the socket.
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	threshold    int
+	fragmentSize int
+	timeout      time.Duration
+	maxBuffered  int
+	progress     ProgressFunc
+	reassemblers map[interceptor.Connection]*reassembler
+	mux          sync.RWMutex
+}
+
+func (i *Interceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if _, exists := i.reassemblers[connection]; exists {
+		return nil, nil, interceptor.ErrConnectionExists
+	}
+
+	i.reassemblers[connection] = newReassembler(i.maxBuffered, i.timeout, func(progress Progress) {
+		i.report(connection, progress)
+	})
+
+	return writer, reader, nil
+}
+
+func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		if msg == nil {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		var data []byte
+		if err := message.Encode(msg, func(p []byte) error {
+			if len(p) > i.threshold {
+				data = append([]byte(nil), p...)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if data == nil {
+			return writer.Write(ctx, connection, msg)
+		}
+
+		return i.writeFragments(ctx, connection, writer, msg, data)
+	})
+}
+
+func (i *Interceptor) writeFragments(ctx context.Context, connection interceptor.Connection, writer interceptor.Writer, msg message.Message, data []byte) error {
+	if n := (len(data) + i.fragmentSize - 1) / i.fragmentSize; n > MaxFragments {
+		return fmt.Errorf("error while fragmenting message of %d bytes into %d fragments; err: %w", len(data), n, ErrTooManyFragments)
+	}
+
+	var (
+		id       = uuid.NewString()
+		total    = uint32((len(data) + i.fragmentSize - 1) / i.fragmentSize)
+		header   = msg.GetCurrentHeader()
+		progress = Progress{
+			TransferID: id,
+			Protocol:   msg.GetProtocol(),
+			Direction:  Outbound,
+			Total:      total,
+			TotalBytes: len(data),
+		}
+	)
+
+	for index := uint32(0); index < total; index++ {
+		start := int(index) * i.fragmentSize
+		end := min(start+i.fragmentSize, len(data))
+
+		fragment, err := NewFragment(id, index, total, len(data), msg.GetProtocol(), data[start:end])
+		if err != nil {
+			return err
+		}
+
+		fragment.SetSender(header.Sender)
+		fragment.SetReceiver(header.Receiver)
+		fragment.SetExpiry(header.Expiry)
+		fragment.SetTraceContext(header.TraceContext())
+		interceptor.InheritPriority(fragment, msg)
+
+		if err := writer.Write(ctx, connection, fragment); err != nil {
+			progress.Err = err
+			i.report(connection, progress)
+			return fmt.Errorf("error while writing fragment %d/%d of transfer %s; err: %w", index+1, total, id, err)
+		}
+
+		progress.Fragments++
+		progress.Bytes += end - start
+		i.report(connection, progress)
+	}
+
+	return nil
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			fragment, ok := msg.(*Fragment)
+			if !ok {
+				return msg, nil
+			}
+
+			r, err := i.getReassembler(connection)
+			if err != nil {
+				return nil, err
+			}
+
+			data, progress, err := r.add(fragment)
+			if err != nil {
+				i.report(connection, Progress{TransferID: fragment.TransferID, Protocol: fragment.Protocol, Direction: Inbound, Total: fragment.Total, TotalBytes: fragment.Size, Err: err})
+				return nil, err
+			}
+
+			i.report(connection, progress)
+
+			if data == nil {
+				// NOTE: TRANSFER INCOMPLETE; KEEP READING SO THAT THE UPPER INTERCEPTORS ONLY SEE WHOLE MESSAGES
+				continue
+			}
+
+			return i.GetMessageRegistry().Unmarshal(fragment.Protocol, data)
+		}
+	})
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if r, exists := i.reassemblers[connection]; exists {
+		r.close()
+		delete(i.reassemblers, connection)
+	}
+}
+
+func (i *Interceptor) Close() error {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	for connection, r := range i.reassemblers {
+		r.close()
+		delete(i.reassemblers, connection)
+	}
+
+	return nil
+}
+
+func (i *Interceptor) getReassembler(connection interceptor.Connection) (*reassembler, error) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	r, exists := i.reassemblers[connection]
+	if !exists {
+		return nil, ErrNotBound
+	}
+
+	return r, nil
+}
+
+func (i *Interceptor) report(connection interceptor.Connection, progress Progress) {
+	if i.progress != nil {
+		i.progress(connection, progress)
+	}
+}
@@ -0,0 +1,99 @@
+package fragment
+
+import (
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const FragmentProtocol message.Protocol = "fragment:fragment"
+
+// MaxFragments bounds the number of fragments of a transfer; with DefaultFragmentSize, messages of up to
+// 1GiB can be fragmented
+const MaxFragments = 1 << 16
+
+// Fragment carries a part of a serialized message which was too big to be sent at once.
+// All the fragments of a message share the TransferID; the receiver reassembles them
+// (in any order) using Index and Total and decodes the message using Protocol.
+type Fragment struct {
+	interceptor.BaseMessage
+	TransferID string           `json:"transfer_id"`
+	Index      uint32           `json:"index"`
+	Total      uint32           `json:"total"`
+	Size       int              `json:"size"`     // Size is the length of the complete serialized message
+	Protocol   message.Protocol `json:"fragment"` // Protocol is the protocol of the fragmented message
+	Data       []byte           `json:"data"`
+}
+
+func NewFragment(transferID string, index uint32, total uint32, size int, protocol message.Protocol, data []byte) (*Fragment, error) {
+	msg := &Fragment{
+		TransferID: transferID,
+		Index:      index,
+		Total:      total,
+		Size:       size,
+		Protocol:   protocol,
+		Data:       data,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Fragment) GetProtocol() message.Protocol {
+	return FragmentProtocol
+}
+
+func (m *Fragment) Validate() error {
+	if err := message.RequireNotEmpty("transfer_id", m.TransferID); err != nil {
+		return err
+	}
+
+	if err := message.RequireNotEmpty("fragment", m.Protocol); err != nil {
+		return err
+	}
+
+	if m.Total == 0 {
+		return message.NewValidationError("total", "must be positive")
+	}
+
+	if m.Total > MaxFragments {
+		return message.NewValidationError("total", fmt.Sprintf("must not exceed %d", MaxFragments))
+	}
+
+	if m.Index >= m.Total {
+		return message.NewValidationError("index", "must be less than total")
+	}
+
+	if err := message.RequirePositive("size", m.Size); err != nil {
+		return err
+	}
+
+	// NOTE: EVERY FRAGMENT CARRIES AT LEAST A BYTE OF THE MESSAGE
+	if int64(m.Total) > int64(m.Size) {
+		return message.NewValidationError("total", "must not exceed size")
+	}
+
+	if len(m.Data) == 0 || len(m.Data) > m.Size {
+		return message.NewValidationError("data", "must not be empty or longer than size")
+	}
+
+	return nil
+}
+
+// Registrations returns the fragment message paired with its protocol.
+func Registrations() []message.Registration {
+	return []message.Registration{
+		message.Type[Fragment](FragmentProtocol),
+	}
+}
+
+// RegisterMessages registers the fragment message in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
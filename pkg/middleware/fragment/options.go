@@ -0,0 +1,112 @@
+package fragment
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// NOTE: THE DEFAULTS KEEP EVERY FRAME BELOW THE 32KiB READ LIMIT OF github.com/coder/websocket
+const (
+	DefaultThreshold        = 24 * 1024
+	DefaultFragmentSize     = 16 * 1024
+	DefaultReassemblyTime   = 30 * time.Second
+	DefaultMaxBufferedBytes = 16 * 1024 * 1024
+)
+
+// Direction tells if a transfer is being sent or received
+type Direction uint8
+
+const (
+	Outbound Direction = iota
+	Inbound
+)
+
+func (d Direction) String() string {
+	if d == Outbound {
+		return "outbound"
+	}
+	return "inbound"
+}
+
+// Progress describes the state of a transfer after a fragment was sent or received.
+// Err is set if the transfer failed; no further progress is reported for it.
+type Progress struct {
+	TransferID string
+	Protocol   message.Protocol
+	Direction  Direction
+	Fragments  uint32 // Fragments sent or received so far
+	Total      uint32
+	Bytes      int // Bytes sent or received so far
+	TotalBytes int
+	Err        error
+}
+
+// Done reports if all the fragments of the transfer were sent or received
+func (p Progress) Done() bool {
+	return p.Err == nil && p.Fragments == p.Total
+}
+
+// ProgressFunc is called from the reader or writer goroutine of the connection; it must not block.
+type ProgressFunc func(interceptor.Connection, Progress)
+
+type Option = func(*Interceptor) error
+
+// WithThreshold sets the size (in bytes of the serialized message) above which messages are fragmented.
+func WithThreshold(threshold int) Option {
+	return func(i *Interceptor) error {
+		if threshold <= 0 {
+			return fmt.Errorf("fragmentation threshold must be positive; got %d", threshold)
+		}
+
+		i.threshold = threshold
+		return nil
+	}
+}
+
+// WithFragmentSize sets the number of bytes of the message carried by each fragment.
+// NOTE: THE DATA IS BASE64 ENCODED ON THE WIRE; A FRAGMENT IS ~4/3 OF THIS SIZE PLUS THE ENVELOPE
+func WithFragmentSize(size int) Option {
+	return func(i *Interceptor) error {
+		if size <= 0 {
+			return fmt.Errorf("fragment size must be positive; got %d", size)
+		}
+
+		i.fragmentSize = size
+		return nil
+	}
+}
+
+// WithReassemblyTimeout sets the time given to a transfer to complete after its first fragment arrived.
+func WithReassemblyTimeout(timeout time.Duration) Option {
+	return func(i *Interceptor) error {
+		if timeout <= 0 {
+			return fmt.Errorf("reassembly timeout must be positive; got %s", timeout)
+		}
+
+		i.timeout = timeout
+		return nil
+	}
+}
+
+// WithMaxBufferedBytes caps the memory used by incomplete transfers of a single connection.
+func WithMaxBufferedBytes(max int) Option {
+	return func(i *Interceptor) error {
+		if max <= 0 {
+			return fmt.Errorf("max buffered bytes must be positive; got %d", max)
+		}
+
+		i.maxBuffered = max
+		return nil
+	}
+}
+
+// WithProgress registers a callback for the progress of fragmented transfers.
+func WithProgress(f ProgressFunc) Option {
+	return func(i *Interceptor) error {
+		i.progress = f
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package fragment
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// transfer holds the fragments of a single message being received
+type transfer struct {
+	protocol  message.Protocol
+	size      int
+	total     uint32
+	fragments [][]byte
+	received  uint32
+	bytes     int
+	timer     *time.Timer
+}
+
+func (t *transfer) progress(id string) Progress {
+	return Progress{
+		TransferID: id,
+		Protocol:   t.protocol,
+		Direction:  Inbound,
+		Fragments:  t.received,
+		Total:      t.total,
+		Bytes:      t.bytes,
+		TotalBytes: t.size,
+	}
+}
+
+// reassembler collects the fragments received on a connection. The complete size of a
+// transfer is reserved against the memory cap when its first fragment arrives, so a peer
+// cannot make the receiver buffer more than maxBuffered bytes.
+type reassembler struct {
+	transfers   map[string]*transfer
+	buffered    int
+	maxBuffered int
+	timeout     time.Duration
+	onExpire    func(Progress)
+	mux         sync.Mutex
+}
+
+func newReassembler(maxBuffered int, timeout time.Duration, onExpire func(Progress)) *reassembler {
+	return &reassembler{
+		transfers:   make(map[string]*transfer),
+		maxBuffered: maxBuffered,
+		timeout:     timeout,
+		onExpire:    onExpire,
+	}
+}
+
+// add stores the fragment. Once the transfer is complete, the reassembled message is returned.
+func (r *reassembler) add(f *Fragment) ([]byte, Progress, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	t, exists := r.transfers[f.TransferID]
+	if !exists {
+		// NOTE: THE FRAGMENTS ARE BOUNDED BEFORE THEY ARE ALLOCATED; EVERY FRAGMENT CARRIES AT LEAST A BYTE, SO
+		// NOTE: A TRANSFER CANNOT HAVE MORE FRAGMENTS THAN BYTES, OR THAN THE MEMORY CAP COULD EVER HOLD
+		if f.Total == 0 || f.Total > MaxFragments || int64(f.Total) > int64(f.Size) || int64(f.Total) > int64(r.maxBuffered) {
+			return nil, Progress{}, fmt.Errorf("error while reassembling transfer %s of %d fragments; err: %w", f.TransferID, f.Total, ErrInvalidFragment)
+		}
+
+		if f.Size > r.maxBuffered-r.buffered {
+			return nil, Progress{}, fmt.Errorf("error while reassembling transfer %s of %d bytes; err: %w", f.TransferID, f.Size, ErrMemoryCapExceeded)
+		}
+
+		t = &transfer{
+			protocol:  f.Protocol,
+			size:      f.Size,
+			total:     f.Total,
+			fragments: make([][]byte, f.Total),
+		}
+
+		id := f.TransferID
+		t.timer = time.AfterFunc(r.timeout, func() {
+			r.expire(id)
+		})
+
+		r.transfers[id] = t
+		r.buffered += t.size
+	}
+
+	if f.Protocol != t.protocol || f.Size != t.size || f.Total != t.total {
+		r.drop(f.TransferID, t)
+		return nil, Progress{}, fmt.Errorf("error while reassembling transfer %s; err: %w", f.TransferID, ErrInvalidFragment)
+	}
+
+	if t.fragments[f.Index] != nil {
+		// NOTE: DUPLICATE FRAGMENT; THE FIRST COPY WINS
+		return nil, t.progress(f.TransferID), nil
+	}
+
+	if t.bytes+len(f.Data) > t.size {
+		r.drop(f.TransferID, t)
+		return nil, Progress{}, fmt.Errorf("error while reassembling transfer %s; err: %w", f.TransferID, ErrInvalidFragment)
+	}
+
+	t.fragments[f.Index] = f.Data
+	t.received++
+	t.bytes += len(f.Data)
+
+	progress := t.progress(f.TransferID)
+	if t.received < t.total {
+		return nil, progress, nil
+	}
+
+	r.drop(f.TransferID, t)
+
+	if t.bytes != t.size {
+		return nil, Progress{}, fmt.Errorf("error while reassembling transfer %s; err: %w", f.TransferID, ErrInvalidFragment)
+	}
+
+	data := make([]byte, 0, t.size)
+	for _, fragment := range t.fragments {
+		data = append(data, fragment...)
+	}
+
+	return data, progress, nil
+}
+
+// expire drops the transfer if it is still incomplete after the reassembly timeout
+func (r *reassembler) expire(id string) {
+	r.mux.Lock()
+	t, exists := r.transfers[id]
+	if !exists {
+		r.mux.Unlock()
+		return
+	}
+
+	r.drop(id, t)
+	progress := t.progress(id)
+	progress.Err = ErrTransferTimeout
+	r.mux.Unlock()
+
+	if r.onExpire != nil {
+		r.onExpire(progress)
+	}
+}
+
+// drop removes the transfer and releases its memory reservation; the caller must hold the lock
+func (r *reassembler) drop(id string, t *transfer) {
+	t.timer.Stop()
+	delete(r.transfers, id)
+	r.buffered -= t.size
+}
+
+// close drops all the incomplete transfers
+func (r *reassembler) close() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for id, t := range r.transfers {
+		r.drop(id, t)
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket. Reservations take a token even if none is available, leaving the bucket
+// in debt; the returned wait is the time until the debt is paid back.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+	mux    sync.Mutex
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+		b.last = now
+	}
+}
+
+// reserve takes a token and returns the time to wait until it is actually available
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.refill(now)
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// cancel gives back a token taken by reserve
+func (b *bucket) cancel() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.tokens = min(float64(b.limit.Burst), b.tokens+1)
+}
+
+// full reports if the bucket refilled completely, that is, it forgot about its past reservations
+func (b *bucket) full(now time.Time) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.refill(now)
+	return b.tokens >= float64(b.limit.Burst)
+}
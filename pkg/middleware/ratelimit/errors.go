@@ -0,0 +1,8 @@
+package ratelimit
+
+import "errors"
+
+var (
+	ErrRateLimited = errors.New("rate limit exceeded")
+	ErrNotBound    = errors.New("connection not bound to the rate limit interceptor")
+)
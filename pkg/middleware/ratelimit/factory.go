@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultMaxDelay is the longest a message is held by ActionDelay
+const DefaultMaxDelay = time.Second
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// InterceptorName names the rate limit interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.RateLimit
+
+// Descriptor places ratelimit beneath chat and stream, so that their messages are limited before they are
+// processed, and above encrypt and fragment, so that the decrypted, reassembled messages are counted.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Before:   []string{names.Encrypt, names.Fragment},
+		After:    []string{names.Chat, names.Stream},
+		Optional: []string{names.Encrypt, names.Fragment, names.Chat, names.Stream},
+	}
+}
+
+// NewInterceptor creates the rate limit interceptor. The interceptor only writes the Exceeded replies; it is
+// the peers reading them which register the rate limit messages (see RegisterMessages).
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		protocolLimits:  make(map[message.Protocol]Limit),
+		clientLimits:    make(map[interceptor.ClientID]Limit),
+		maxDelay:        DefaultMaxDelay,
+		closeCode:       DefaultCloseCode,
+		now:             time.Now,
+		connections:     make(map[interceptor.Connection]*limiter),
+		clients:         make(map[interceptor.ClientID]*bucket),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
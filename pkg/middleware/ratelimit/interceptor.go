@@ -0,0 +1,314 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor limits the rate of the messages read from its connections. Limits apply per connection,
+// per protocol (on every connection) and per client (over all the connections of the client); a message
+// must fit in all of them.
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	connectionLimit *Limit
+	protocolLimits  map[message.Protocol]Limit
+	clientLimit     *Limit
+	clientLimits    map[interceptor.ClientID]Limit
+	resolver        ClientResolver
+	action          Action
+	maxDelay        time.Duration
+	closeCode       int
+	now             func() time.Time
+	connections     map[interceptor.Connection]*limiter
+	clients         map[interceptor.ClientID]*bucket
+	stats           counters
+	mux             sync.RWMutex
+}
+
+// limiter is the state of a single connection
+type limiter struct {
+	writer     interceptor.Writer
+	connection *bucket
+	protocols  map[message.Protocol]*bucket
+	mux        sync.Mutex
+}
+
+// Stats counts the messages read by the interceptor
+type Stats struct {
+	Allowed      uint64 `json:"allowed"` // Allowed includes the delayed messages
+	Delayed      uint64 `json:"delayed"`
+	Dropped      uint64 `json:"dropped"`
+	Replied      uint64 `json:"replied"`
+	Disconnected uint64 `json:"disconnected"`
+	// Limited counts the messages which exceeded a limit (and were not delayed), by protocol
+	Limited map[message.Protocol]uint64 `json:"limited"`
+}
+
+type counters struct {
+	allowed      atomic.Uint64
+	delayed      atomic.Uint64
+	dropped      atomic.Uint64
+	replied      atomic.Uint64
+	disconnected atomic.Uint64
+	limited      map[message.Protocol]uint64
+	mux          sync.Mutex
+}
+
+func (c *counters) limit(protocol message.Protocol) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.limited == nil {
+		c.limited = make(map[message.Protocol]uint64)
+	}
+	c.limited[protocol]++
+}
+
+func (i *Interceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if _, exists := i.connections[connection]; exists {
+		return nil, nil, interceptor.ErrConnectionExists
+	}
+
+	l := &limiter{
+		writer:    writer,
+		protocols: make(map[message.Protocol]*bucket),
+	}
+
+	if i.connectionLimit != nil {
+		l.connection = newBucket(*i.connectionLimit, i.now())
+	}
+
+	i.connections[connection] = l
+
+	return writer, reader, nil
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			l, err := i.getLimiter(connection)
+			if err != nil {
+				return nil, err
+			}
+
+			admitted, err := i.admit(ctx, connection, l, msg)
+			if err != nil {
+				return nil, err
+			}
+
+			if admitted {
+				return msg, nil
+			}
+			// NOTE: MESSAGE DROPPED; KEEP READING
+		}
+	})
+}
+
+// admit reports if the message fits in the limits, applying the action otherwise
+func (i *Interceptor) admit(ctx context.Context, connection interceptor.Connection, l *limiter, msg message.Message) (bool, error) {
+	protocol := msg.GetProtocol()
+
+	reserved, wait, scope := i.reserve(connection, l, protocol)
+	if wait == 0 {
+		i.stats.allowed.Add(1)
+		return true, nil
+	}
+
+	if i.action == ActionDelay && wait <= i.maxDelay {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			cancel(reserved)
+			return false, ctx.Err()
+		case <-timer.C:
+			i.stats.delayed.Add(1)
+			i.stats.allowed.Add(1)
+			return true, nil
+		}
+	}
+
+	// NOTE: THE MESSAGE IS NOT DELIVERED; IT MUST NOT COUNT AGAINST THE LIMITS
+	cancel(reserved)
+	i.stats.limit(protocol)
+
+	switch i.action {
+	case ActionReply:
+		i.stats.replied.Add(1)
+		reply, err := NewExceeded(protocol, scope, wait)
+		if err != nil {
+			return false, fmt.Errorf("error while replying rate limit on %s; err: %w", protocol, err)
+		}
+
+		if err := l.writer.Write(ctx, connection, reply); err != nil {
+			return false, fmt.Errorf("error while replying rate limit on %s; err: %w", protocol, err)
+		}
+
+		return false, nil
+	case ActionDisconnect:
+		i.stats.disconnected.Add(1)
+		if closer, ok := connection.(interceptor.CodeCloser); ok {
+			_ = closer.CloseWithCode(i.closeCode, ErrRateLimited.Error())
+		} else {
+			_ = connection.Close()
+		}
+
+		return false, fmt.Errorf("error while reading %s; %s limit; err: %w", protocol, scope, ErrRateLimited)
+	default:
+		i.stats.dropped.Add(1)
+		return false, nil
+	}
+}
+
+// reserve takes a token from every bucket the message counts against and returns the buckets, the longest
+// wait and the scope of the limit causing it
+func (i *Interceptor) reserve(connection interceptor.Connection, l *limiter, protocol message.Protocol) ([]*bucket, time.Duration, Scope) {
+	var (
+		now      = i.now()
+		reserved = make([]*bucket, 0, 3)
+		longest  time.Duration
+		scope    Scope
+	)
+
+	take := func(b *bucket, s Scope) {
+		if b == nil {
+			return
+		}
+
+		reserved = append(reserved, b)
+		if wait := b.reserve(now); wait > longest {
+			longest, scope = wait, s
+		}
+	}
+
+	take(l.connection, ScopeConnection)
+	take(i.protocolBucket(l, protocol, now), ScopeProtocol)
+	take(i.clientBucket(connection, now), ScopeClient)
+
+	return reserved, longest, scope
+}
+
+func cancel(reserved []*bucket) {
+	for _, b := range reserved {
+		b.cancel()
+	}
+}
+
+func (i *Interceptor) protocolBucket(l *limiter, protocol message.Protocol, now time.Time) *bucket {
+	limit, ok := i.protocolLimits[protocol]
+	if !ok {
+		return nil
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	b, exists := l.protocols[protocol]
+	if !exists {
+		b = newBucket(limit, now)
+		l.protocols[protocol] = b
+	}
+
+	return b
+}
+
+func (i *Interceptor) clientBucket(connection interceptor.Connection, now time.Time) *bucket {
+	if i.resolver == nil {
+		return nil
+	}
+
+	id, ok := i.resolver(connection)
+	if !ok {
+		return nil
+	}
+
+	limit, ok := i.clientLimits[id]
+	if !ok {
+		if i.clientLimit == nil {
+			return nil
+		}
+		limit = *i.clientLimit
+	}
+
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	b, exists := i.clients[id]
+	if !exists {
+		b = newBucket(limit, now)
+		i.clients[id] = b
+	}
+
+	return b
+}
+
+func (i *Interceptor) getLimiter(connection interceptor.Connection) (*limiter, error) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	l, exists := i.connections[connection]
+	if !exists {
+		return nil, ErrNotBound
+	}
+
+	return l, nil
+}
+
+// Stats returns the counters of the interceptor
+func (i *Interceptor) Stats() Stats {
+	i.stats.mux.Lock()
+	limited := make(map[message.Protocol]uint64, len(i.stats.limited))
+	for protocol, count := range i.stats.limited {
+		limited[protocol] = count
+	}
+	i.stats.mux.Unlock()
+
+	return Stats{
+		Allowed:      i.stats.allowed.Load(),
+		Delayed:      i.stats.delayed.Load(),
+		Dropped:      i.stats.dropped.Load(),
+		Replied:      i.stats.replied.Load(),
+		Disconnected: i.stats.disconnected.Load(),
+		Limited:      limited,
+	}
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	delete(i.connections, connection)
+
+	// NOTE: A FULL BUCKET HOLDS NO HISTORY; IT IS RECREATED IF THE CLIENT COMES BACK
+	now := i.now()
+	for id, b := range i.clients {
+		if b.full(now) {
+			delete(i.clients, id)
+		}
+	}
+}
+
+func (i *Interceptor) Close() error {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	i.connections = make(map[interceptor.Connection]*limiter)
+	i.clients = make(map[interceptor.ClientID]*bucket)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const ExceededProtocol message.Protocol = "ratelimit:exceeded"
+
+// Exceeded tells the peer that one of its messages was dropped as it exceeded a rate limit
+type Exceeded struct {
+	interceptor.BaseMessage
+	LimitedProtocol message.Protocol `json:"limited_protocol"`
+	Scope           Scope            `json:"scope"`
+	RetryAfter      time.Duration    `json:"retry_after"` // RetryAfter is the time until the limit allows the next message
+}
+
+func NewExceeded(protocol message.Protocol, scope Scope, retryAfter time.Duration) (*Exceeded, error) {
+	msg := &Exceeded{
+		LimitedProtocol: protocol,
+		Scope:           scope,
+		RetryAfter:      retryAfter,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Exceeded) GetProtocol() message.Protocol {
+	return ExceededProtocol
+}
+
+func (m *Exceeded) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *Exceeded) Validate() error {
+	if err := message.RequireNotEmpty("limited_protocol", m.LimitedProtocol); err != nil {
+		return err
+	}
+
+	return message.RequireNotEmpty("scope", m.Scope)
+}
+
+// Registrations returns the rate limit messages paired with their protocols
+func Registrations() []message.Registration {
+	return []message.Registration{
+		message.Type[Exceeded](ExceededProtocol),
+	}
+}
+
+// RegisterMessages registers the rate limit messages in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultCloseCode is the WebSocket "policy violation" close code sent on disconnect
+const DefaultCloseCode = 1008
+
+// Limit is a token bucket: Rate tokens are added every second, up to Burst. Every message takes a token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerSecond allows n messages per second with bursts of n
+func PerSecond(n int) Limit {
+	return Limit{Rate: float64(n), Burst: n}
+}
+
+func (l Limit) validate() error {
+	if l.Rate <= 0 {
+		return fmt.Errorf("rate limit rate must be positive; got %f", l.Rate)
+	}
+
+	if l.Burst <= 0 {
+		return fmt.Errorf("rate limit burst must be positive; got %d", l.Burst)
+	}
+
+	return nil
+}
+
+// Action is what the interceptor does with a message exceeding a limit
+type Action uint8
+
+const (
+	// ActionDrop drops the message
+	ActionDrop Action = iota
+	// ActionDelay holds the message until the limit allows it, up to the max delay; longer waits are dropped
+	ActionDelay
+	// ActionReply drops the message and sends an Exceeded message to the peer
+	ActionReply
+	// ActionDisconnect closes the connection
+	ActionDisconnect
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionDrop:
+		return "drop"
+	case ActionDelay:
+		return "delay"
+	case ActionReply:
+		return "reply"
+	case ActionDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("action(%d)", uint8(a))
+	}
+}
+
+// Scope tells which limit a message exceeded
+type Scope string
+
+const (
+	ScopeConnection Scope = "connection"
+	ScopeProtocol   Scope = "protocol"
+	ScopeClient     Scope = "client"
+)
+
+// ClientResolver returns the client behind the connection; false if it is not known (yet).
+// For example, the chat interceptor learns the client IDs of its connections from the ident messages.
+type ClientResolver func(interceptor.Connection) (interceptor.ClientID, bool)
+
+type Option = func(*Interceptor) error
+
+// WithLimit limits the messages read from every connection, whatever their protocol
+func WithLimit(limit Limit) Option {
+	return func(i *Interceptor) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+
+		i.connectionLimit = &limit
+		return nil
+	}
+}
+
+// WithProtocolLimit limits the messages of the protocol read from every connection
+func WithProtocolLimit(protocol message.Protocol, limit Limit) Option {
+	return func(i *Interceptor) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+
+		i.protocolLimits[protocol] = limit
+		return nil
+	}
+}
+
+// WithClientLimit limits the messages read from all the connections of a client together.
+// Clients are resolved with the ClientResolver (see WithClientResolver); without it, the limit is not applied.
+func WithClientLimit(limit Limit) Option {
+	return func(i *Interceptor) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+
+		i.clientLimit = &limit
+		return nil
+	}
+}
+
+// WithClientLimitFor overrides the client limit for the given client
+func WithClientLimitFor(id interceptor.ClientID, limit Limit) Option {
+	return func(i *Interceptor) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+
+		i.clientLimits[id] = limit
+		return nil
+	}
+}
+
+// WithClientResolver sets how the client behind a connection is found
+func WithClientResolver(resolver ClientResolver) Option {
+	return func(i *Interceptor) error {
+		i.resolver = resolver
+		return nil
+	}
+}
+
+// WithAction sets the action on the messages exceeding a limit; ActionDrop by default
+func WithAction(action Action) Option {
+	return func(i *Interceptor) error {
+		if action > ActionDisconnect {
+			return fmt.Errorf("unknown rate limit action %s", action)
+		}
+
+		i.action = action
+		return nil
+	}
+}
+
+// WithMaxDelay caps the time a message is held by ActionDelay
+func WithMaxDelay(delay time.Duration) Option {
+	return func(i *Interceptor) error {
+		if delay <= 0 {
+			return fmt.Errorf("rate limit max delay must be positive; got %s", delay)
+		}
+
+		i.maxDelay = delay
+		return nil
+	}
+}
+
+// WithCloseCode sets the close code of ActionDisconnect
+func WithCloseCode(code int) Option {
+	return func(i *Interceptor) error {
+		i.closeCode = code
+		return nil
+	}
+}
@@ -0,0 +1,318 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	createProtocol  message.Protocol = "test:create"
+	forwardProtocol message.Protocol = "test:forward"
+)
+
+type testMessage struct {
+	interceptor.BaseMessage
+	protocol message.Protocol
+}
+
+func (m *testMessage) GetProtocol() message.Protocol {
+	return m.protocol
+}
+
+type testConnection struct {
+	name   string
+	closed int
+}
+
+func (c *testConnection) Write(_ context.Context, _ []byte) error { return nil }
+func (c *testConnection) Read(_ context.Context) ([]byte, error)  { return nil, nil }
+func (c *testConnection) Close() error                            { return nil }
+
+func (c *testConnection) CloseWithCode(code int, _ string) error {
+	c.closed = code
+	return nil
+}
+
+// feed is the reader and writer beneath the interceptor; reads return the queued messages in order
+type feed struct {
+	queue   []message.Message
+	written []message.Message
+	mux     sync.Mutex
+}
+
+func (f *feed) push(protocols ...message.Protocol) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	for _, protocol := range protocols {
+		f.queue = append(f.queue, &testMessage{protocol: protocol})
+	}
+}
+
+func (f *feed) Read(_ context.Context, _ interceptor.Connection) (message.Message, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if len(f.queue) == 0 {
+		return nil, nil
+	}
+
+	msg := f.queue[0]
+	f.queue = f.queue[1:]
+	return msg, nil
+}
+
+func (f *feed) Write(_ context.Context, _ interceptor.Connection, msg message.Message) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.written = append(f.written, msg)
+	return nil
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newInterceptor(t *testing.T, options ...Option) (*Interceptor, *clock) {
+	t.Helper()
+
+	i, err := NewInterceptorFactory(options...).NewInterceptor(context.Background(), "server", message.NewDefaultRegistry())
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+
+	c := &clock{now: time.Unix(0, 0)}
+	limiter := i.(*Interceptor)
+	limiter.now = c.Now
+
+	return limiter, c
+}
+
+func bind(t *testing.T, i *Interceptor, connection interceptor.Connection) (*feed, interceptor.Reader) {
+	t.Helper()
+
+	f := &feed{}
+	_, reader, err := i.BindSocketConnection(connection, f, f)
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	return f, i.InterceptSocketReader(reader)
+}
+
+// drain reads until the feed is empty and returns the protocols delivered
+func drain(t *testing.T, reader interceptor.Reader, connection interceptor.Connection) []message.Protocol {
+	t.Helper()
+
+	var delivered []message.Protocol
+	for {
+		msg, err := reader.Read(context.Background(), connection)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+
+		if msg == nil {
+			return delivered
+		}
+
+		delivered = append(delivered, msg.GetProtocol())
+	}
+}
+
+func TestInterceptor_ConnectionLimitDrops(t *testing.T) {
+	i, clock := newInterceptor(t, WithLimit(Limit{Rate: 1, Burst: 2}))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol, createProtocol)
+	if got := drain(t, reader, connection); len(got) != 2 {
+		t.Fatalf("delivered %d messages, want the burst of 2", len(got))
+	}
+
+	clock.advance(time.Second)
+	f.push(createProtocol, createProtocol)
+	if got := drain(t, reader, connection); len(got) != 1 {
+		t.Fatalf("delivered %d messages after 1s, want 1", len(got))
+	}
+
+	stats := i.Stats()
+	if stats.Allowed != 3 || stats.Dropped != 2 || stats.Limited[createProtocol] != 2 {
+		t.Errorf("Stats() = %+v, want 3 allowed and 2 dropped", stats)
+	}
+}
+
+func TestInterceptor_ProtocolLimit(t *testing.T) {
+	i, _ := newInterceptor(t, WithProtocolLimit(createProtocol, Limit{Rate: 1, Burst: 1}))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol, forwardProtocol, forwardProtocol)
+
+	got := drain(t, reader, connection)
+	want := []message.Protocol{createProtocol, forwardProtocol, forwardProtocol}
+	if len(got) != len(want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+
+	for index := range want {
+		if got[index] != want[index] {
+			t.Errorf("delivered %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestInterceptor_ClientLimitSharedByConnections(t *testing.T) {
+	var (
+		first  = &testConnection{name: "first"}
+		second = &testConnection{name: "second"}
+	)
+
+	i, _ := newInterceptor(t,
+		WithClientLimit(Limit{Rate: 1, Burst: 2}),
+		WithClientLimitFor("vip", Limit{Rate: 1, Burst: 10}),
+		WithClientResolver(func(connection interceptor.Connection) (interceptor.ClientID, bool) {
+			return "client", true
+		}),
+	)
+
+	f1, r1 := bind(t, i, first)
+	f2, r2 := bind(t, i, second)
+
+	f1.push(createProtocol, createProtocol)
+	f2.push(createProtocol)
+
+	if got := len(drain(t, r1, first)) + len(drain(t, r2, second)); got != 2 {
+		t.Errorf("delivered %d messages over both connections, want the client burst of 2", got)
+	}
+}
+
+func TestInterceptor_ActionReply(t *testing.T) {
+	i, _ := newInterceptor(t, WithLimit(Limit{Rate: 1, Burst: 1}), WithAction(ActionReply))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol)
+	drain(t, reader, connection)
+
+	if len(f.written) != 1 {
+		t.Fatalf("written %d messages, want 1 reply", len(f.written))
+	}
+
+	reply, ok := f.written[0].(*Exceeded)
+	if !ok {
+		t.Fatalf("written %T, want *Exceeded", f.written[0])
+	}
+
+	if reply.LimitedProtocol != createProtocol || reply.Scope != ScopeConnection || reply.RetryAfter != time.Second {
+		t.Errorf("reply = %+v", reply)
+	}
+
+	if err := reply.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestInterceptor_ActionDisconnect(t *testing.T) {
+	i, _ := newInterceptor(t, WithLimit(Limit{Rate: 1, Burst: 1}), WithAction(ActionDisconnect))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol)
+
+	if _, err := reader.Read(context.Background(), connection); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if _, err := reader.Read(context.Background(), connection); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Read() error = %v, want %v", err, ErrRateLimited)
+	}
+
+	if connection.closed != DefaultCloseCode {
+		t.Errorf("closed with %d, want %d", connection.closed, DefaultCloseCode)
+	}
+}
+
+func TestInterceptor_ActionDelay(t *testing.T) {
+	i, err := NewInterceptorFactory(WithLimit(Limit{Rate: 50, Burst: 1}), WithAction(ActionDelay)).NewInterceptor(context.Background(), "server", message.NewDefaultRegistry())
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+	limiter := i.(*Interceptor)
+
+	connection := &testConnection{}
+	f, reader := bind(t, limiter, connection)
+
+	f.push(createProtocol, createProtocol)
+
+	start := time.Now()
+	if got := drain(t, reader, connection); len(got) != 2 {
+		t.Fatalf("delivered %d messages, want both", len(got))
+	}
+
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("second message delivered after %s, want ~20ms", elapsed)
+	}
+
+	if stats := limiter.Stats(); stats.Delayed != 1 || stats.Allowed != 2 {
+		t.Errorf("Stats() = %+v, want 1 delayed of 2 allowed", stats)
+	}
+}
+
+func TestInterceptor_ActionDelayBeyondMaxDrops(t *testing.T) {
+	i, _ := newInterceptor(t, WithLimit(Limit{Rate: 1, Burst: 1}), WithAction(ActionDelay), WithMaxDelay(10*time.Millisecond))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol)
+	if got := drain(t, reader, connection); len(got) != 1 {
+		t.Fatalf("delivered %d messages, want 1", len(got))
+	}
+
+	if stats := i.Stats(); stats.Dropped != 1 {
+		t.Errorf("Stats() = %+v, want 1 dropped", stats)
+	}
+}
+
+func TestInterceptor_DroppedMessagesDoNotCount(t *testing.T) {
+	i, clock := newInterceptor(t, WithLimit(Limit{Rate: 1, Burst: 1}))
+	connection := &testConnection{}
+	f, reader := bind(t, i, connection)
+
+	f.push(createProtocol, createProtocol, createProtocol, createProtocol)
+	drain(t, reader, connection)
+
+	clock.advance(time.Second)
+	f.push(createProtocol)
+	if got := drain(t, reader, connection); len(got) != 1 {
+		t.Errorf("delivered %d messages after the refill, want 1; dropped messages must not leave the bucket in debt", len(got))
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	for _, option := range []Option{
+		WithLimit(Limit{Rate: 0, Burst: 1}),
+		WithProtocolLimit(createProtocol, Limit{Rate: 1, Burst: 0}),
+		WithAction(Action(42)),
+		WithMaxDelay(0),
+	} {
+		if _, err := NewInterceptorFactory(option).NewInterceptor(context.Background(), "server", message.NewDefaultRegistry()); err == nil {
+			t.Errorf("NewInterceptor() with invalid option expected error, got nil")
+		}
+	}
+}
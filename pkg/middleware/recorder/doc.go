@@ -0,0 +1,23 @@
+// Package recorder records the messages of interceptor chains and replays the recordings.
+//
+// # Format
+//
+// A recording is a JSON Lines file: every line is a Record.
+//
+//	{"seq":1,"time":"2025-01-02T15:04:05.000000001Z","connection":"f3c1","direction":"inbound","point":"wire","protocol":"room:create_room","message":{}}
+//
+// The fields are:
+//   - seq is the position of the record in the recording, starting at 1
+//   - time is when the message was recorded (RFC 3339 with nanoseconds)
+//   - connection identifies the connection; it is the socket's connection ID when known
+//   - direction is "inbound" (read from the peer) or "outbound" (written to the peer)
+//   - point is where the message was recorded: "application" is the end of the chain facing the application,
+//     "wire" the end facing the socket. Outbound messages are recorded at the application point before
+//     interception and at the wire point after it; inbound messages the other way around.
+//   - protocol is the protocol of the message
+//   - message is the message as serialized on the wire (see message.Marshal)
+//   - error is set, instead of message, if the message could not be serialized
+//
+// Recordings rotate when they reach their maximum size: the file is renamed with the suffix ".1",
+// the previous ".1" becomes ".2", and so on, up to the number of files kept.
+package recorder
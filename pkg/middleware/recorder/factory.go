@@ -0,0 +1,25 @@
+package recorder
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type InterceptorFactory struct {
+	recorder *Recorder
+	point    Point
+}
+
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return descriptor(f.point)
+}
+
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	return &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		recorder:        f.recorder,
+		point:           f.point,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// rotatingFile appends lines to a file, rotating it when it exceeds its maximum size
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+	mux      sync.Mutex
+}
+
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error while opening recording %s; err: %w", f.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error while opening recording %s; err: %w", f.path, err)
+	}
+
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// writeLine writes the line, followed by a newline, rotating the file first if the line does not fit
+func (f *rotatingFile) writeLine(line []byte) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+
+	if f.size > 0 && f.size+int64(len(line))+1 > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(append(line, '\n'))
+	f.size += int64(n)
+
+	return err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error while rotating recording %s; err: %w", f.path, err)
+	}
+	f.file = nil
+
+	// NOTE: path.N-1 -> path.N, ..., path -> path.1; THE OLDEST FILE IS OVERWRITTEN
+	for i := f.maxFiles - 1; i >= 0; i-- {
+		from := f.path
+		if i > 0 {
+			from = fmt.Sprintf("%s.%d", f.path, i)
+		}
+
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error while rotating recording %s; err: %w", f.path, err)
+		}
+	}
+
+	return f.open()
+}
+
+func (f *rotatingFile) close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
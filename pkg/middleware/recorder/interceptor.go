@@ -0,0 +1,37 @@
+package recorder
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor records the messages passing through its point of the chain
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	recorder *Recorder
+	point    Point
+}
+
+func (i *Interceptor) InterceptSocketWriter(writer interceptor.Writer) interceptor.Writer {
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		i.recorder.record(connection, Outbound, i.point, msg)
+		return writer.Write(ctx, connection, msg)
+	})
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		msg, err := reader.Read(ctx, connection)
+		if err == nil {
+			i.recorder.record(connection, Inbound, i.point, msg)
+		}
+
+		return msg, err
+	})
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.recorder.forget(connection)
+}
@@ -0,0 +1,36 @@
+package recorder
+
+import (
+	"fmt"
+)
+
+const (
+	DefaultMaxSize  = 64 * 1024 * 1024
+	DefaultMaxFiles = 5
+)
+
+type Option = func(*Recorder) error
+
+// WithMaxSize sets the size in bytes at which the recording is rotated
+func WithMaxSize(size int64) Option {
+	return func(r *Recorder) error {
+		if size <= 0 {
+			return fmt.Errorf("recording max size must be positive; got %d", size)
+		}
+
+		r.maxSize = size
+		return nil
+	}
+}
+
+// WithMaxFiles sets the number of rotated files kept besides the current one
+func WithMaxFiles(files int) Option {
+	return func(r *Recorder) error {
+		if files <= 0 {
+			return fmt.Errorf("recording max files must be positive; got %d", files)
+		}
+
+		r.maxFiles = files
+		return nil
+	}
+}
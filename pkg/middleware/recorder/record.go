@@ -0,0 +1,71 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Direction tells if a message was read from or written to the peer
+type Direction string
+
+const (
+	Inbound  Direction = "inbound"
+	Outbound Direction = "outbound"
+)
+
+// Point tells at which end of the interceptor chain a message was recorded
+type Point string
+
+const (
+	PointApplication Point = "application"
+	PointWire        Point = "wire"
+)
+
+// Record is a line of a recording
+type Record struct {
+	Sequence   uint64           `json:"seq"`
+	Time       time.Time        `json:"time"`
+	Connection string           `json:"connection"`
+	Direction  Direction        `json:"direction"`
+	Point      Point            `json:"point"`
+	Protocol   message.Protocol `json:"protocol"`
+	Message    json.RawMessage  `json:"message,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// ReadRecords reads a recording
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var (
+		records = make([]Record, 0)
+		scanner = bufio.NewScanner(r)
+		line    = 0
+	)
+
+	// NOTE: MESSAGES MAY BE LARGER THAN THE DEFAULT 64KiB TOKEN LIMIT OF THE SCANNER
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error while reading record at line %d; err: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading recording; err: %w", err)
+	}
+
+	return records, nil
+}
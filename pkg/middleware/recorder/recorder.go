@@ -0,0 +1,147 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	// ApplicationInterceptorName names the recording interceptor at the application end of the chain
+	ApplicationInterceptorName = "recorder:application"
+	// WireInterceptorName names the recording interceptor at the wire end of the chain
+	WireInterceptorName = "recorder:wire"
+)
+
+// identified is implemented by the connections which carry an ID, like the socket's connections
+type identified interface {
+	ID() string
+}
+
+// Recorder writes the messages seen by its interceptors to a rotating recording (see the package documentation).
+// Register both ApplicationFactory and WireFactory to record the messages before and after interception.
+type Recorder struct {
+	maxSize     int64
+	maxFiles    int
+	file        *rotatingFile
+	sequence    atomic.Uint64
+	now         func() time.Time
+	connections map[interceptor.Connection]string
+	anonymous   uint64
+	mux         sync.Mutex
+}
+
+// Open starts a recording at the path; an existing recording is appended to
+func Open(path string, options ...Option) (*Recorder, error) {
+	r := &Recorder{
+		maxSize:     DefaultMaxSize,
+		maxFiles:    DefaultMaxFiles,
+		now:         time.Now,
+		connections: make(map[interceptor.Connection]string),
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := openRotatingFile(path, r.maxSize, r.maxFiles)
+	if err != nil {
+		return nil, err
+	}
+	r.file = file
+
+	return r, nil
+}
+
+// ApplicationFactory creates the interceptors recording at the application end of the chain
+func (r *Recorder) ApplicationFactory() interceptor.Factory {
+	return &InterceptorFactory{recorder: r, point: PointApplication}
+}
+
+// WireFactory creates the interceptors recording at the wire end of the chain
+func (r *Recorder) WireFactory() interceptor.Factory {
+	return &InterceptorFactory{recorder: r, point: PointWire}
+}
+
+// connectionID returns the ID of the connection, naming the connections without one in order of appearance
+func (r *Recorder) connectionID(connection interceptor.Connection) string {
+	if c, ok := connection.(identified); ok {
+		return c.ID()
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	id, exists := r.connections[connection]
+	if !exists {
+		r.anonymous++
+		id = "connection-" + strconv.FormatUint(r.anonymous, 10)
+		r.connections[connection] = id
+	}
+
+	return id
+}
+
+func (r *Recorder) forget(connection interceptor.Connection) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	delete(r.connections, connection)
+}
+
+// record writes the message to the recording. Failures are reported but never fail the message.
+func (r *Recorder) record(connection interceptor.Connection, direction Direction, point Point, msg message.Message) {
+	if msg == nil {
+		return
+	}
+
+	record := Record{
+		Sequence:   r.sequence.Add(1),
+		Time:       r.now(),
+		Connection: r.connectionID(connection),
+		Direction:  direction,
+		Point:      point,
+		Protocol:   msg.GetProtocol(),
+	}
+
+	data, err := message.Marshal(msg)
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Message = data
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		fmt.Println("error while recording message; err:", err.Error())
+		return
+	}
+
+	if err := r.file.writeLine(line); err != nil {
+		fmt.Println("error while recording message; err:", err.Error())
+	}
+}
+
+// Close stops the recording
+func (r *Recorder) Close() error {
+	return r.file.close()
+}
+
+// descriptor keeps the application interceptor first and the wire interceptor last on the write path:
+// with no constraints, the lowest priority is chained first and the highest last
+func descriptor(point Point) interceptor.Descriptor {
+	if point == PointApplication {
+		return interceptor.Descriptor{Name: ApplicationInterceptorName, Priority: math.MinInt}
+	}
+
+	return interceptor.Descriptor{Name: WireInterceptorName, Priority: math.MaxInt}
+}
@@ -0,0 +1,309 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/transport/pipe"
+)
+
+const (
+	pingProtocol message.Protocol = "test:ping"
+	pongProtocol message.Protocol = "test:pong"
+)
+
+type ping struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *ping) GetProtocol() message.Protocol {
+	return pingProtocol
+}
+
+type pong struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *pong) GetProtocol() message.Protocol {
+	return pongProtocol
+}
+
+func newPing(t *testing.T, text string) *ping {
+	t.Helper()
+
+	msg := &ping{Text: text}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	return msg
+}
+
+// echo answers every ping with a pong through the layers beneath it, like a server process would
+type echo struct {
+	interceptor.NoOpInterceptor
+	writer interceptor.Writer
+}
+
+func (e *echo) BindSocketConnection(_ interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	e.writer = writer
+	return writer, reader, nil
+}
+
+func (e *echo) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		msg, err := reader.Read(ctx, connection)
+		if err != nil {
+			return nil, err
+		}
+
+		if p, ok := msg.(*ping); ok {
+			reply := &pong{Text: p.Text}
+			bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, reply)
+			if err != nil {
+				return nil, err
+			}
+			reply.BaseMessage = bmsg
+
+			if err := e.writer.Write(ctx, connection, reply); err != nil {
+				return nil, err
+			}
+		}
+
+		return msg, nil
+	})
+}
+
+type echoFactory struct{}
+
+func (echoFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	return &echo{NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry)}, nil
+}
+
+func newMessageRegistry(t *testing.T) message.Registry {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	if err := message.RegisterAll(registry, message.Type[ping](pingProtocol), message.Type[pong](pongProtocol)); err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+
+	return registry
+}
+
+func buildChain(t *testing.T, factories ...interceptor.Factory) interceptor.Interceptor {
+	t.Helper()
+
+	registry := interceptor.NewRegistry()
+	for _, factory := range factories {
+		registry.Register(factory)
+	}
+
+	chain, err := registry.Build(context.Background(), "server")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	return chain
+}
+
+func readRecording(t *testing.T, path string) []Record {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+
+	records, err := ReadRecords(file)
+	if err != nil {
+		t.Fatalf("ReadRecords() error = %v", err)
+	}
+
+	return records
+}
+
+func points(records []Record) []string {
+	got := make([]string, 0, len(records))
+	for _, record := range records {
+		got = append(got, string(record.Direction)+"/"+string(record.Point)+"/"+string(record.Protocol))
+	}
+
+	return got
+}
+
+func TestRecorder_OrderInChain(t *testing.T) {
+	rec, err := Open(filepath.Join(t.TempDir(), "recording.jsonl"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer rec.Close()
+
+	registry := interceptor.NewRegistry()
+	registry.Register(rec.WireFactory())
+	registry.Register(interceptor.Describe(echoFactory{}, interceptor.Descriptor{Name: "echo"}))
+	registry.Register(rec.ApplicationFactory())
+
+	order, err := registry.Order()
+	if err != nil {
+		t.Fatalf("Order() error = %v", err)
+	}
+
+	if want := []string{ApplicationInterceptorName, "echo", WireInterceptorName}; !slices.Equal(order, want) {
+		t.Errorf("Order() = %v, want %v", order, want)
+	}
+}
+
+// record runs a ping through a chain with the recorder and the echo and returns the recording
+func record(t *testing.T) []Record {
+	t.Helper()
+
+	var (
+		ctx      = context.Background()
+		path     = filepath.Join(t.TempDir(), "recording.jsonl")
+		messages = newMessageRegistry(t)
+	)
+
+	rec, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	chain := buildChain(t, rec.ApplicationFactory(), echoFactory{}, rec.WireFactory())
+
+	local, peer := pipe.New("connection-a")
+	_, reader, err := chain.BindSocketConnection(local, pipe.Writer(), pipe.Reader(messages))
+	if err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	if err := pipe.Writer().Write(ctx, peer, newPing(t, "hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if _, err := reader.Read(ctx, local); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	chain.UnBindSocketConnection(local)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return readRecording(t, path)
+}
+
+func TestRecorder_RecordsBothEnds(t *testing.T) {
+	records := record(t)
+
+	want := []string{
+		"inbound/wire/test:ping",
+		"outbound/wire/test:pong",
+		"inbound/application/test:ping",
+	}
+
+	if got := points(records); !slices.Equal(got, want) {
+		t.Fatalf("recorded %v, want %v", got, want)
+	}
+
+	for index, r := range records {
+		if r.Sequence != uint64(index+1) || r.Connection != "connection-a" || r.Time.IsZero() || len(r.Message) == 0 {
+			t.Errorf("record %d = %+v", index, r)
+		}
+	}
+}
+
+func TestReplay_ReproducesRecording(t *testing.T) {
+	records := record(t)
+	messages := newMessageRegistry(t)
+
+	for run := 0; run < 3; run++ {
+		chain := buildChain(t, echoFactory{})
+
+		result, err := Replay(context.Background(), records, chain, messages)
+		if err != nil {
+			t.Fatalf("Replay() error = %v", err)
+		}
+
+		if d, diverged := result.Divergence(); diverged {
+			t.Fatalf("run %d: %s", run, d)
+		}
+
+		if len(result.Connections) != 1 || len(result.Connections[0].Delivered) != 1 || len(result.Connections[0].Sent) != 1 {
+			t.Fatalf("run %d: result = %+v", run, result)
+		}
+	}
+}
+
+func TestReplay_ReportsDivergence(t *testing.T) {
+	records := record(t)
+
+	// NOTE: WITHOUT THE ECHO, THE PONG IS NEVER SENT
+	result, err := Replay(context.Background(), records, buildChain(t, interceptor.Describe(&noop{}, interceptor.Descriptor{Name: "noop"})), newMessageRegistry(t))
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	d, diverged := result.Divergence()
+	if !diverged {
+		t.Fatalf("Divergence() = none, want the missing pong")
+	}
+
+	if d.Direction != Outbound || d.Index != 0 || d.Recorded != pongProtocol || d.Replayed != "" {
+		t.Errorf("Divergence() = %s", d)
+	}
+}
+
+type noop struct{}
+
+func (*noop) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := interceptor.NewNoOpInterceptor(ctx, id, registry)
+	return &i, nil
+}
+
+func TestRecorder_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+
+	rec, err := Open(path, WithMaxSize(1024), WithMaxFiles(2))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	connection, _ := pipe.New("connection-a")
+	for i := 0; i < 20; i++ {
+		rec.record(connection, Outbound, PointApplication, newPing(t, "a message long enough to fill the file quickly"))
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Stat(%s) error = %v", name, err)
+		}
+
+		if info.Size() > 1024 {
+			t.Errorf("%s is %d bytes, want at most 1024", name, info.Size())
+		}
+
+		readRecording(t, name)
+	}
+
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(%s.3) error = %v, want only 2 rotated files", path, err)
+	}
+}
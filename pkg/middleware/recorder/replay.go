@@ -0,0 +1,245 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/transport/pipe"
+)
+
+// Result is the outcome of a replay, by connection in order of appearance in the recording
+type Result struct {
+	Connections []ConnectionResult
+}
+
+// ConnectionResult compares what the chain did with a connection during the replay to the recording.
+// Delivered are the inbound messages at the application point, Sent the outbound messages at the wire point.
+type ConnectionResult struct {
+	Connection        string
+	RecordedDelivered []Record
+	Delivered         []Record
+	RecordedSent      []Record
+	Sent              []Record
+	// Err is the error which stopped reading through the chain, if any
+	Err error
+}
+
+// Divergence is the first message of a connection where the replay differs from the recording
+type Divergence struct {
+	Connection string
+	Direction  Direction
+	Index      int              // Index of the message among the delivered (inbound) or sent (outbound) messages
+	Recorded   message.Protocol // Recorded is empty if the replay produced more messages
+	Replayed   message.Protocol // Replayed is empty if the replay produced fewer messages
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("connection %s: %s message #%d is %q in the recording and %q in the replay", d.Connection, d.Direction, d.Index, d.Recorded, d.Replayed)
+}
+
+// Divergence returns the first difference between the protocols of the recorded and replayed messages.
+// NOTE: ONLY THE PROTOCOLS ARE COMPARED AS MESSAGES CARRY TIMESTAMPS, NONCES AND OTHER VOLATILE FIELDS
+func (r Result) Divergence() (Divergence, bool) {
+	for _, c := range r.Connections {
+		if d, ok := diverge(c.Connection, Inbound, c.RecordedDelivered, c.Delivered); ok {
+			return d, true
+		}
+
+		if d, ok := diverge(c.Connection, Outbound, c.RecordedSent, c.Sent); ok {
+			return d, true
+		}
+	}
+
+	return Divergence{}, false
+}
+
+func diverge(connection string, direction Direction, recorded, replayed []Record) (Divergence, bool) {
+	for index := 0; index < max(len(recorded), len(replayed)); index++ {
+		d := Divergence{Connection: connection, Direction: direction, Index: index}
+		if index < len(recorded) {
+			d.Recorded = recorded[index].Protocol
+		}
+		if index < len(replayed) {
+			d.Replayed = replayed[index].Protocol
+		}
+
+		if d.Recorded != d.Replayed {
+			return d, true
+		}
+	}
+
+	return Divergence{}, false
+}
+
+// Replay feeds a recording into the chain over in-process pipes, one connection after the other.
+// The inbound messages recorded at the wire point are written by the peer in their recorded order, and the
+// outbound messages recorded at the application point are written through the chain, interleaved as recorded.
+// Records without a message (see Record.Error) are skipped.
+// Timestamps are ignored, so the replay runs as fast as the chain allows and in the same order every time.
+//
+// The chain is bound to every connection but not initialised (see interceptor.Interceptor.Init), as the
+// handshakes are part of the recording. The registry must know every recorded protocol.
+func Replay(ctx context.Context, records []Record, chain interceptor.Interceptor, registry message.Registry) (Result, error) {
+	var (
+		order  = make([]string, 0)
+		groups = make(map[string][]Record)
+	)
+
+	for _, record := range records {
+		if _, exists := groups[record.Connection]; !exists {
+			order = append(order, record.Connection)
+		}
+		groups[record.Connection] = append(groups[record.Connection], record)
+	}
+
+	result := Result{Connections: make([]ConnectionResult, 0, len(order))}
+	for _, connection := range order {
+		c, err := replayConnection(ctx, connection, groups[connection], chain, registry)
+		if err != nil {
+			return result, fmt.Errorf("error while replaying connection %s; err: %w", connection, err)
+		}
+
+		result.Connections = append(result.Connections, c)
+	}
+
+	return result, nil
+}
+
+func replayConnection(ctx context.Context, id string, records []Record, chain interceptor.Interceptor, registry message.Registry) (ConnectionResult, error) {
+	result := ConnectionResult{Connection: id}
+
+	local, peer := pipe.New(id)
+	defer local.Close()
+
+	writer, reader, err := chain.BindSocketConnection(local, pipe.Writer(), pipe.Reader(registry))
+	if err != nil {
+		return result, err
+	}
+	defer chain.UnBindSocketConnection(local)
+
+	var (
+		delivered = make(chan struct{})
+		sent      = make(chan struct{})
+		readErr   error
+	)
+
+	go func() {
+		defer close(delivered)
+
+		for {
+			msg, err := reader.Read(ctx, local)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+					// NOTE: THE CHAIN STOPPED READING; DISCARD THE REST SO THAT THE PEER NEVER BLOCKS
+					for {
+						if _, err := local.Read(ctx); err != nil {
+							return
+						}
+					}
+				}
+				return
+			}
+
+			if msg == nil {
+				continue
+			}
+
+			result.Delivered = append(result.Delivered, replayedRecord(id, len(result.Delivered)+1, Inbound, PointApplication, msg))
+		}
+	}()
+
+	go func() {
+		defer close(sent)
+
+		for {
+			data, err := peer.Read(ctx)
+			if err != nil {
+				return
+			}
+
+			var header struct {
+				Protocol message.Protocol `json:"protocol"`
+			}
+			_ = json.Unmarshal(data, &header)
+
+			result.Sent = append(result.Sent, Record{
+				Sequence:   uint64(len(result.Sent) + 1),
+				Connection: id,
+				Direction:  Outbound,
+				Point:      PointWire,
+				Protocol:   header.Protocol,
+				Message:    data,
+			})
+		}
+	}()
+
+	feedErr := feed(ctx, records, &result, local, peer, writer, registry)
+
+	// NOTE: THE PEER STOPS WRITING SO THAT THE CHAIN READS EVERYTHING AND THEN io.EOF; ITS REPLIES STILL GO THROUGH
+	_ = peer.CloseWrite()
+	<-delivered
+
+	// NOTE: ONCE THE CHAIN READ EVERYTHING, ITS DIRECTION IS CLOSED TOO AND THE PEER DRAINS THE REPLIES
+	_ = local.CloseWrite()
+	<-sent
+
+	result.Err = readErr
+	return result, feedErr
+}
+
+// feed writes the recorded input of the connection and collects the recorded output to compare to
+func feed(ctx context.Context, records []Record, result *ConnectionResult, local, peer *pipe.Conn, writer interceptor.Writer, registry message.Registry) error {
+	for _, record := range records {
+		if len(record.Message) == 0 {
+			// NOTE: THE MESSAGE COULD NOT BE SERIALIZED WHEN IT WAS RECORDED (SEE Record.Error)
+			continue
+		}
+
+		switch {
+		case record.Direction == Inbound && record.Point == PointWire:
+			if err := peer.Write(ctx, record.Message); err != nil {
+				return fmt.Errorf("error while replaying record %d; err: %w", record.Sequence, err)
+			}
+		case record.Direction == Outbound && record.Point == PointApplication:
+			msg, err := registry.UnmarshalRaw(message.Payload(record.Message))
+			if err != nil {
+				return fmt.Errorf("error while replaying record %d; err: %w", record.Sequence, err)
+			}
+
+			if err := writer.Write(ctx, local, msg); err != nil {
+				return fmt.Errorf("error while replaying record %d; err: %w", record.Sequence, err)
+			}
+		case record.Direction == Inbound && record.Point == PointApplication:
+			result.RecordedDelivered = append(result.RecordedDelivered, record)
+		case record.Direction == Outbound && record.Point == PointWire:
+			result.RecordedSent = append(result.RecordedSent, record)
+		}
+	}
+
+	return nil
+}
+
+func replayedRecord(connection string, sequence int, direction Direction, point Point, msg message.Message) Record {
+	record := Record{
+		Sequence:   uint64(sequence),
+		Connection: connection,
+		Direction:  direction,
+		Point:      point,
+		Protocol:   msg.GetProtocol(),
+	}
+
+	data, err := message.Marshal(msg)
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Message = data
+	}
+
+	return record
+}
@@ -0,0 +1,26 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotBound         = errors.New("connection not bound to the stream interceptor")
+	ErrStreamClosed     = errors.New("stream closed")
+	ErrStreamExists     = errors.New("stream already exists")
+	ErrTooManyStreams   = errors.New("too many open streams")
+	ErrFlowControl      = errors.New("stream flow control window exceeded")
+	ErrInvalidOffset    = errors.New("stream data out of order")
+	ErrConnectionClosed = errors.New("connection closed")
+)
+
+// AbortError is returned by the reads and writes of a stream which was aborted by the peer
+type AbortError struct {
+	StreamID string
+	Reason   string
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("stream %s aborted by peer; reason: %s", e.StreamID, e.Reason)
+}
@@ -0,0 +1,53 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/names"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+type InterceptorFactory struct {
+	options []Option
+}
+
+func NewInterceptorFactory(options ...Option) *InterceptorFactory {
+	return &InterceptorFactory{
+		options: options,
+	}
+}
+
+// InterceptorName names the stream interceptor in the interceptor chain (see interceptor.Descriptor)
+const InterceptorName = names.Stream
+
+// Descriptor places stream before encrypt and fragment so that the chunks are encrypted and, when
+// the chunk size exceeds the fragment threshold, fragmented on their way out.
+func (f *InterceptorFactory) Descriptor() interceptor.Descriptor {
+	return interceptor.Descriptor{
+		Name:     InterceptorName,
+		Before:   []string{names.Encrypt, names.Fragment},
+		Optional: []string{names.Encrypt, names.Fragment},
+	}
+}
+
+// NewInterceptor creates the stream interceptor. The stream messages are read by the socket before the
+// interceptor consumes them, so both ends register them in the registry of their socket (see RegisterMessages).
+func (f *InterceptorFactory) NewInterceptor(ctx context.Context, id interceptor.ClientID, registry message.Registry) (interceptor.Interceptor, error) {
+	i := &Interceptor{
+		NoOpInterceptor: interceptor.NewNoOpInterceptor(ctx, id, registry),
+		chunkSize:       DefaultChunkSize,
+		window:          DefaultWindow,
+		maxWindow:       DefaultMaxWindow,
+		maxStreams:      DefaultMaxStreams,
+		sessions:        make(map[interceptor.Connection]*session),
+	}
+
+	for _, option := range f.options {
+		if err := option(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
@@ -0,0 +1,268 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// Interceptor multiplexes binary streams (files, blobs) over the connection alongside the
+// regular messages. Every stream has its own flow control window and writes its data in its
+// own lane (see interceptor.WithLane), so that a big transfer does not delay the control traffic.
+// The stream messages are consumed by the interceptor and never reach the interceptors above it.
+//
+// Outgoing streams are created with Open; incoming streams are handed to the AcceptFunc.
+// As the interceptor is built by the interceptor registry, a custom Option can be used to keep
+// a reference to it:
+//
+//	var streams *stream.Interceptor
+//	factory := stream.NewInterceptorFactory(func(i *stream.Interceptor) error { streams = i; return nil })
+type Interceptor struct {
+	interceptor.NoOpInterceptor
+	chunkSize    int
+	window       uint32
+	maxWindow    uint32
+	maxStreams   int
+	accept       AcceptFunc
+	replyWrapper ReplyWrapperFunc
+	sessions     map[interceptor.Connection]*session
+	mux          sync.RWMutex
+}
+
+func (i *Interceptor) BindSocketConnection(connection interceptor.Connection, writer interceptor.Writer, reader interceptor.Reader) (interceptor.Writer, interceptor.Reader, error) {
+	i.mux.Lock()
+	defer i.mux.Unlock()
+
+	if _, exists := i.sessions[connection]; exists {
+		return nil, nil, interceptor.ErrConnectionExists
+	}
+
+	i.sessions[connection] = newSession(connection, writer)
+
+	return writer, reader, nil
+}
+
+func (i *Interceptor) InterceptSocketReader(reader interceptor.Reader) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		for {
+			msg, err := reader.Read(ctx, connection)
+			if err != nil || msg == nil {
+				return msg, err
+			}
+
+			handled, err := i.handle(ctx, connection, msg)
+			if err != nil {
+				return nil, err
+			}
+
+			if !handled {
+				return msg, nil
+			}
+			// NOTE: STREAM MESSAGE CONSUMED; KEEP READING SO THAT THE UPPER INTERCEPTORS ONLY SEE REGULAR MESSAGES
+		}
+	})
+}
+
+func (i *Interceptor) UnBindSocketConnection(connection interceptor.Connection) {
+	i.mux.Lock()
+	s, exists := i.sessions[connection]
+	delete(i.sessions, connection)
+	i.mux.Unlock()
+
+	if exists {
+		s.close(ErrConnectionClosed)
+	}
+}
+
+func (i *Interceptor) Close() error {
+	i.mux.Lock()
+	sessions := i.sessions
+	i.sessions = make(map[interceptor.Connection]*session)
+	i.mux.Unlock()
+
+	for _, s := range sessions {
+		s.close(ErrConnectionClosed)
+	}
+
+	return nil
+}
+
+// Connections returns the connections currently bound to the interceptor
+func (i *Interceptor) Connections() []interceptor.Connection {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	connections := make([]interceptor.Connection, 0, len(i.sessions))
+	for connection := range i.sessions {
+		connections = append(connections, connection)
+	}
+
+	return connections
+}
+
+// Open starts a new outgoing stream on the connection. The context bounds the lifetime of the stream;
+// writes block until the receiver accepts the stream and grants credit.
+func (i *Interceptor) Open(ctx context.Context, connection interceptor.Connection, options ...OpenOption) (*SendStream, error) {
+	s, err := i.getSession(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := newSendStream(ctx, uuid.NewString(), i, s)
+	for _, option := range options {
+		if err := option(stream); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.addSender(stream); err != nil {
+		return nil, err
+	}
+
+	open, err := NewOpen(stream.id, stream.name, stream.contentType, stream.size, stream.requested, stream.via)
+	if err != nil {
+		s.removeSender(stream.id)
+		return nil, err
+	}
+
+	// NOTE: OPEN IS WRITTEN IN THE LANE OF THE STREAM SO THAT IT IS NEVER OVERTAKEN BY THE CLOSE OF THE STREAM
+	if err := i.write(stream.laneCtx(), s, stream.wrap, open); err != nil {
+		s.removeSender(stream.id)
+		return nil, fmt.Errorf("error while opening stream %s; err: %w", stream.id, err)
+	}
+
+	return stream, nil
+}
+
+func (i *Interceptor) handle(ctx context.Context, connection interceptor.Connection, msg message.Message) (bool, error) {
+	switch msg.(type) {
+	case *Open, *Data, *WindowUpdate, *Close, *Abort:
+	default:
+		return false, nil
+	}
+
+	s, err := i.getSession(connection)
+	if err != nil {
+		return true, err
+	}
+
+	switch m := msg.(type) {
+	case *Open:
+		i.onOpen(ctx, connection, s, m)
+	case *Data:
+		if r := s.receiver(m.StreamID); r != nil {
+			if err := r.push(m); err != nil {
+				r.abort(ctx, err)
+			}
+		}
+		// NOTE: DATA OF UNKNOWN STREAMS (ABORTED OR REJECTED) IS DROPPED
+	case *WindowUpdate:
+		if stream := s.sender(m.StreamID); stream != nil {
+			stream.grant(m.Credit)
+		}
+	case *Close:
+		if r := s.receiver(m.StreamID); r != nil {
+			r.finish()
+		}
+	case *Abort:
+		err := &AbortError{StreamID: m.StreamID, Reason: m.Reason}
+		if stream := s.removeSender(m.StreamID); stream != nil {
+			stream.fail(err)
+		}
+		if r := s.removeReceiver(m.StreamID); r != nil {
+			r.fail(err)
+		}
+	}
+
+	return true, nil
+}
+
+func (i *Interceptor) onOpen(ctx context.Context, connection interceptor.Connection, s *session, open *Open) {
+	window := i.window
+	if open.Window > 0 {
+		window = open.Window
+	}
+	window = min(window, i.maxWindow)
+
+	var wrap Wrapper
+	if open.Via != "" && i.replyWrapper != nil {
+		wrap = i.replyWrapper(open)
+	}
+
+	r := newReceiveStream(i, s, open, window, wrap)
+
+	if i.accept == nil {
+		i.reject(ctx, s, wrap, open.StreamID, "streams are not accepted")
+		return
+	}
+
+	if err := s.addReceiver(r, i.maxStreams); err != nil {
+		i.reject(ctx, s, wrap, open.StreamID, err.Error())
+		return
+	}
+
+	if err := i.accept(connection, r); err != nil {
+		s.removeReceiver(r.id)
+		i.reject(ctx, s, wrap, open.StreamID, err.Error())
+		return
+	}
+
+	update, err := NewWindowUpdate(r.id, window)
+	if err != nil {
+		r.abort(ctx, err)
+		return
+	}
+
+	if err := i.write(ctx, s, wrap, update); err != nil {
+		r.abort(ctx, err)
+	}
+}
+
+func (i *Interceptor) reject(ctx context.Context, s *session, wrap Wrapper, streamID string, reason string) {
+	abort, err := NewAbort(streamID, reason)
+	if err != nil {
+		fmt.Println("error while rejecting stream; err:", err.Error())
+		return
+	}
+
+	if err := i.write(ctx, s, wrap, abort); err != nil {
+		fmt.Println("error while rejecting stream; err:", err.Error())
+	}
+}
+
+// write sends a stream message through the writer of the session, wrapping it if needed
+func (i *Interceptor) write(ctx context.Context, s *session, wrap Wrapper, msg interceptor.Message) error {
+	msg.SetSender(message.Sender(i.ID()))
+
+	var out message.Message = msg
+	if wrap != nil {
+		wrapped, err := wrap(msg)
+		if err != nil {
+			return fmt.Errorf("error while wrapping stream message; err: %w", err)
+		}
+
+		if m, ok := wrapped.(interceptor.Message); ok {
+			m.SetSender(message.Sender(i.ID()))
+		}
+		out = wrapped
+	}
+
+	return s.writer.Write(ctx, s.connection, out)
+}
+
+func (i *Interceptor) getSession(connection interceptor.Connection) (*session, error) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	s, exists := i.sessions[connection]
+	if !exists {
+		return nil, ErrNotBound
+	}
+
+	return s, nil
+}
@@ -0,0 +1,239 @@
+package stream
+
+import (
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	OpenProtocol   message.Protocol = "stream:open"
+	DataProtocol   message.Protocol = "stream:data"
+	WindowProtocol message.Protocol = "stream:window"
+	CloseProtocol  message.Protocol = "stream:close"
+	AbortProtocol  message.Protocol = "stream:abort"
+)
+
+// Open announces a new stream to the receiver. The sender waits for the first WindowUpdate
+// (the stream was accepted) or an Abort (the stream was rejected) before sending any Data.
+type Open struct {
+	interceptor.BaseMessage
+	StreamID    string `json:"stream_id"`
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`   // Size is the total length of the stream if known
+	Window      uint32 `json:"window,omitempty"` // Window is the flow control window requested by the sender
+	Via         string `json:"via,omitempty"`    // Via tells the receiver how the stream is relayed (for example, a room)
+}
+
+func NewOpen(streamID string, name string, contentType string, size int64, window uint32, via string) (*Open, error) {
+	msg := &Open{
+		StreamID:    streamID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Window:      window,
+		Via:         via,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Open) GetProtocol() message.Protocol {
+	return OpenProtocol
+}
+
+// NOTE: OPEN, DATA AND CLOSE SHARE THE BULK CLASS AS ORDER IS ONLY KEPT WITHIN A CLASS AND LANE
+func (m *Open) Priority() interceptor.Priority {
+	return interceptor.PriorityBulk
+}
+
+func (m *Open) Validate() error {
+	if err := message.RequireNotEmpty("stream_id", m.StreamID); err != nil {
+		return err
+	}
+
+	if m.Size < 0 {
+		return message.NewValidationError("size", "must not be negative")
+	}
+
+	return nil
+}
+
+// Data carries the bytes of a stream starting at Offset. The sender never sends more
+// bytes than the credit granted by the receiver.
+type Data struct {
+	interceptor.BaseMessage
+	StreamID string `json:"stream_id"`
+	Offset   int64  `json:"offset"`
+	Data     []byte `json:"data"`
+}
+
+func NewData(streamID string, offset int64, data []byte) (*Data, error) {
+	msg := &Data{
+		StreamID: streamID,
+		Offset:   offset,
+		Data:     data,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Data) GetProtocol() message.Protocol {
+	return DataProtocol
+}
+
+func (m *Data) Priority() interceptor.Priority {
+	return interceptor.PriorityBulk
+}
+
+func (m *Data) Validate() error {
+	if err := message.RequireNotEmpty("stream_id", m.StreamID); err != nil {
+		return err
+	}
+
+	if m.Offset < 0 {
+		return message.NewValidationError("offset", "must not be negative")
+	}
+
+	if len(m.Data) == 0 {
+		return message.NewValidationError("data", "must not be empty")
+	}
+
+	return nil
+}
+
+// WindowUpdate grants the sender Credit more bytes of the stream
+type WindowUpdate struct {
+	interceptor.BaseMessage
+	StreamID string `json:"stream_id"`
+	Credit   uint32 `json:"credit"`
+}
+
+func NewWindowUpdate(streamID string, credit uint32) (*WindowUpdate, error) {
+	msg := &WindowUpdate{
+		StreamID: streamID,
+		Credit:   credit,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *WindowUpdate) GetProtocol() message.Protocol {
+	return WindowProtocol
+}
+
+func (m *WindowUpdate) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *WindowUpdate) Validate() error {
+	if err := message.RequireNotEmpty("stream_id", m.StreamID); err != nil {
+		return err
+	}
+
+	if m.Credit == 0 {
+		return message.NewValidationError("credit", "must be positive")
+	}
+
+	return nil
+}
+
+// Close tells the receiver that all the Data of the stream was sent
+type Close struct {
+	interceptor.BaseMessage
+	StreamID string `json:"stream_id"`
+}
+
+func NewClose(streamID string) (*Close, error) {
+	msg := &Close{
+		StreamID: streamID,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Close) GetProtocol() message.Protocol {
+	return CloseProtocol
+}
+
+func (m *Close) Priority() interceptor.Priority {
+	return interceptor.PriorityBulk
+}
+
+func (m *Close) Validate() error {
+	return message.RequireNotEmpty("stream_id", m.StreamID)
+}
+
+// Abort cancels the stream; it can be sent by either side at any time
+type Abort struct {
+	interceptor.BaseMessage
+	StreamID string `json:"stream_id"`
+	Reason   string `json:"reason"`
+}
+
+func NewAbort(streamID string, reason string) (*Abort, error) {
+	msg := &Abort{
+		StreamID: streamID,
+		Reason:   reason,
+	}
+
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.BaseMessage = bmsg
+
+	return msg, nil
+}
+
+func (m *Abort) GetProtocol() message.Protocol {
+	return AbortProtocol
+}
+
+func (m *Abort) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func (m *Abort) Validate() error {
+	return message.RequireNotEmpty("stream_id", m.StreamID)
+}
+
+// Registrations returns the stream messages paired with their protocols.
+func Registrations() []message.Registration {
+	return []message.Registration{
+		message.Type[Open](OpenProtocol),
+		message.Type[Data](DataProtocol),
+		message.Type[WindowUpdate](WindowProtocol),
+		message.Type[Close](CloseProtocol),
+		message.Type[Abort](AbortProtocol),
+	}
+}
+
+// RegisterMessages registers the stream messages in the given registry.
+func RegisterMessages(registry message.Registry) error {
+	return message.RegisterAll(registry, Registrations()...)
+}
@@ -0,0 +1,152 @@
+package stream
+
+import (
+	"fmt"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// NOTE: THE CHUNK SIZE KEEPS EVERY DATA MESSAGE BELOW THE 32KiB READ LIMIT OF github.com/coder/websocket
+const (
+	DefaultChunkSize = 16 * 1024
+	DefaultWindow    = 256 * 1024
+	DefaultMaxWindow = 4 * 1024 * 1024
+	// DefaultMaxStreams is the number of incoming streams a connection may have open at once
+	DefaultMaxStreams = 64
+)
+
+// AcceptFunc is called from the reader goroutine of the connection when the peer opens a stream.
+// It must not block; the stream is usually handed over to another goroutine which reads it.
+// A non-nil error rejects the stream and is sent to the peer as the reason.
+type AcceptFunc func(interceptor.Connection, *ReceiveStream) error
+
+// Wrapper wraps every message of a stream before it is written. It allows streams to be relayed
+// by other middlewares, for example by forwarding them to a peer through a chat room.
+type Wrapper func(message.Message) (message.Message, error)
+
+// ReplyWrapperFunc builds the Wrapper for the replies (window updates, aborts) of an incoming stream.
+type ReplyWrapperFunc func(*Open) Wrapper
+
+type Option = func(*Interceptor) error
+
+// WithAcceptFunc sets the handler of the incoming streams. Without one, every incoming stream is rejected.
+func WithAcceptFunc(f AcceptFunc) Option {
+	return func(i *Interceptor) error {
+		i.accept = f
+		return nil
+	}
+}
+
+// WithChunkSize sets the maximum number of bytes carried by a single Data message.
+// NOTE: THE DATA IS BASE64 ENCODED ON THE WIRE; A DATA MESSAGE IS ~4/3 OF THIS SIZE PLUS THE ENVELOPE
+func WithChunkSize(size int) Option {
+	return func(i *Interceptor) error {
+		if size <= 0 {
+			return fmt.Errorf("stream chunk size must be positive; got %d", size)
+		}
+
+		i.chunkSize = size
+		return nil
+	}
+}
+
+// WithWindow sets the flow control window granted to incoming streams which did not request one.
+func WithWindow(window uint32) Option {
+	return func(i *Interceptor) error {
+		if window == 0 {
+			return fmt.Errorf("stream window must be positive")
+		}
+
+		i.window = window
+		return nil
+	}
+}
+
+// WithMaxWindow caps the flow control window granted to a single incoming stream, which is the
+// most memory an incoming stream can use before it is read.
+func WithMaxWindow(window uint32) Option {
+	return func(i *Interceptor) error {
+		if window == 0 {
+			return fmt.Errorf("stream max window must be positive")
+		}
+
+		i.maxWindow = window
+		return nil
+	}
+}
+
+// WithMaxStreams caps the number of incoming streams a connection may have open at once; the streams
+// opened above it are rejected. Together with WithMaxWindow, it bounds the memory a peer can make the
+// interceptor use.
+func WithMaxStreams(n int) Option {
+	return func(i *Interceptor) error {
+		if n <= 0 {
+			return fmt.Errorf("stream max streams must be positive; got %d", n)
+		}
+
+		i.maxStreams = n
+		return nil
+	}
+}
+
+// WithReplyWrapper sets how the replies of relayed incoming streams (see WithVia) are wrapped.
+// It is called once per incoming stream with a non-empty Open.Via.
+func WithReplyWrapper(f ReplyWrapperFunc) Option {
+	return func(i *Interceptor) error {
+		i.replyWrapper = f
+		return nil
+	}
+}
+
+type OpenOption = func(*SendStream) error
+
+// WithName sets the name (for example, a file name) announced to the receiver
+func WithName(name string) OpenOption {
+	return func(s *SendStream) error {
+		s.name = name
+		return nil
+	}
+}
+
+// WithContentType sets the content type announced to the receiver
+func WithContentType(contentType string) OpenOption {
+	return func(s *SendStream) error {
+		s.contentType = contentType
+		return nil
+	}
+}
+
+// WithSize announces the total length of the stream to the receiver
+func WithSize(size int64) OpenOption {
+	return func(s *SendStream) error {
+		if size < 0 {
+			return fmt.Errorf("stream size must not be negative; got %d", size)
+		}
+
+		s.size = size
+		return nil
+	}
+}
+
+// WithRequestedWindow asks the receiver for a flow control window; the receiver may grant less.
+func WithRequestedWindow(window uint32) OpenOption {
+	return func(s *SendStream) error {
+		s.requested = window
+		return nil
+	}
+}
+
+// WithVia relays the stream through another middleware. Every message of the stream is wrapped
+// by the wrapper and via is announced to the receiver so that it can wrap its replies (see WithReplyWrapper).
+func WithVia(via string, wrapper Wrapper) OpenOption {
+	return func(s *SendStream) error {
+		if wrapper == nil {
+			return fmt.Errorf("stream wrapper must not be nil")
+		}
+
+		s.via = via
+		s.wrap = wrapper
+		return nil
+	}
+}
@@ -0,0 +1,252 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// ReceiveStream is the reading side of a stream. It implements io.ReadCloser; Read returns
+// io.EOF after all the data was read and the sender closed the stream. Consuming the data
+// grants the sender more credit, so an unread stream holds at most its window in memory.
+type ReceiveStream struct {
+	id          string
+	name        string
+	contentType string
+	size        int64
+	via         string
+	sender      message.Sender
+	window      uint32
+	wrap        Wrapper
+
+	interceptor *Interceptor
+	session     *session
+
+	chunks   [][]byte
+	received int64  // received is the offset expected for the next data
+	credit   int64  // credit is what the sender may still send
+	consumed uint32 // consumed is what was read since the last window update
+	finished bool   // finished is set once the sender closed the stream
+	err      error
+	// changed is closed and replaced whenever new data arrives or the stream ends
+	changed chan struct{}
+	mux     sync.Mutex
+}
+
+func newReceiveStream(i *Interceptor, s *session, open *Open, window uint32, wrap Wrapper) *ReceiveStream {
+	return &ReceiveStream{
+		id:          open.StreamID,
+		name:        open.Name,
+		contentType: open.ContentType,
+		size:        open.Size,
+		via:         open.Via,
+		sender:      open.GetCurrentHeader().Sender,
+		window:      window,
+		wrap:        wrap,
+		interceptor: i,
+		session:     s,
+		credit:      int64(window),
+		changed:     make(chan struct{}),
+	}
+}
+
+func (r *ReceiveStream) ID() string {
+	return r.id
+}
+
+func (r *ReceiveStream) Name() string {
+	return r.name
+}
+
+func (r *ReceiveStream) ContentType() string {
+	return r.contentType
+}
+
+// Size returns the length announced by the sender; zero if unknown
+func (r *ReceiveStream) Size() int64 {
+	return r.size
+}
+
+func (r *ReceiveStream) Via() string {
+	return r.via
+}
+
+func (r *ReceiveStream) Sender() message.Sender {
+	return r.sender
+}
+
+// Read reads the next bytes of the stream, blocking until data arrives or the stream ends.
+func (r *ReceiveStream) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		r.mux.Lock()
+		if len(r.chunks) > 0 {
+			n := r.consume(p)
+
+			var update uint32
+			if r.consumed >= r.window/2 {
+				update = r.consumed
+				r.consumed = 0
+				r.credit += int64(update)
+			}
+			r.mux.Unlock()
+
+			if update > 0 {
+				r.sendWindowUpdate(update)
+			}
+
+			return n, nil
+		}
+
+		if r.finished {
+			r.mux.Unlock()
+			return 0, io.EOF
+		}
+
+		if r.err != nil {
+			err := r.err
+			r.mux.Unlock()
+			return 0, err
+		}
+
+		changed := r.changed
+		r.mux.Unlock()
+
+		select {
+		case <-changed:
+		case <-r.interceptor.Ctx().Done():
+			return 0, r.interceptor.Ctx().Err()
+		}
+	}
+}
+
+// Close stops reading the stream. If the sender did not finish yet, the stream is aborted.
+func (r *ReceiveStream) Close() error {
+	r.mux.Lock()
+	done := r.finished || r.err != nil
+	r.mux.Unlock()
+
+	r.session.removeReceiver(r.id)
+	if done {
+		return nil
+	}
+
+	r.fail(ErrStreamClosed)
+
+	msg, err := NewAbort(r.id, "receiver closed the stream")
+	if err != nil {
+		return err
+	}
+
+	return r.interceptor.write(r.interceptor.Ctx(), r.session, r.wrap, msg)
+}
+
+// consume copies the buffered data into p; the caller must hold mux
+func (r *ReceiveStream) consume(p []byte) int {
+	n := 0
+	for n < len(p) && len(r.chunks) > 0 {
+		c := copy(p[n:], r.chunks[0])
+		n += c
+
+		if c == len(r.chunks[0]) {
+			r.chunks[0] = nil
+			r.chunks = r.chunks[1:]
+		} else {
+			r.chunks[0] = r.chunks[0][c:]
+		}
+	}
+
+	r.consumed += uint32(n)
+	return n
+}
+
+func (r *ReceiveStream) sendWindowUpdate(credit uint32) {
+	msg, err := NewWindowUpdate(r.id, credit)
+	if err != nil {
+		fmt.Println("error while updating stream window; err:", err.Error())
+		return
+	}
+
+	if err := r.interceptor.write(r.interceptor.Ctx(), r.session, r.wrap, msg); err != nil {
+		fmt.Println("error while updating stream window; err:", err.Error())
+	}
+}
+
+func (r *ReceiveStream) push(data *Data) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.finished || r.err != nil {
+		return nil
+	}
+
+	if data.Offset != r.received {
+		return ErrInvalidOffset
+	}
+
+	if int64(len(data.Data)) > r.credit {
+		return ErrFlowControl
+	}
+
+	r.chunks = append(r.chunks, data.Data)
+	r.received += int64(len(data.Data))
+	r.credit -= int64(len(data.Data))
+	r.notify()
+
+	return nil
+}
+
+func (r *ReceiveStream) finish() {
+	r.session.removeReceiver(r.id)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	r.finished = true
+	r.notify()
+}
+
+// abort fails the stream locally and tells the sender why
+func (r *ReceiveStream) abort(ctx context.Context, err error) {
+	r.session.removeReceiver(r.id)
+	r.fail(err)
+
+	msg, merr := NewAbort(r.id, err.Error())
+	if merr != nil {
+		fmt.Println("error while aborting stream; err:", merr.Error())
+		return
+	}
+
+	if werr := r.interceptor.write(ctx, r.session, r.wrap, msg); werr != nil {
+		fmt.Println("error while aborting stream; err:", werr.Error())
+	}
+}
+
+func (r *ReceiveStream) fail(err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.err != nil || r.finished {
+		return
+	}
+
+	r.err = err
+	r.chunks = nil
+	r.notify()
+}
+
+// notify wakes up the waiting reader; the caller must hold mux
+func (r *ReceiveStream) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
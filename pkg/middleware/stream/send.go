@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// SendStream is the writing side of a stream. It implements io.WriteCloser; Write blocks
+// while the flow control window granted by the receiver is exhausted.
+type SendStream struct {
+	id          string
+	name        string
+	contentType string
+	size        int64
+	requested   uint32
+	via         string
+	wrap        Wrapper
+
+	ctx         context.Context
+	interceptor *Interceptor
+	session     *session
+
+	offset int64 // guarded by writeMux
+	credit int64
+	err    error
+	// changed is closed and replaced whenever the credit or err changes
+	changed  chan struct{}
+	mux      sync.Mutex
+	writeMux sync.Mutex
+}
+
+func newSendStream(ctx context.Context, id string, i *Interceptor, s *session) *SendStream {
+	return &SendStream{
+		id:          id,
+		ctx:         ctx,
+		interceptor: i,
+		session:     s,
+		changed:     make(chan struct{}),
+	}
+}
+
+func (s *SendStream) ID() string {
+	return s.id
+}
+
+// Lane returns the lane in which the data of the stream is written
+func (s *SendStream) Lane() interceptor.Lane {
+	return interceptor.Lane("stream:" + s.id)
+}
+
+func (s *SendStream) laneCtx() context.Context {
+	return interceptor.WithLane(s.ctx, s.Lane())
+}
+
+// Write sends p to the receiver in chunks, waiting for credit as needed.
+func (s *SendStream) Write(p []byte) (int, error) {
+	s.writeMux.Lock()
+	defer s.writeMux.Unlock()
+
+	written := 0
+	for len(p) > 0 {
+		n, err := s.reserve(min(len(p), s.interceptor.chunkSize))
+		if err != nil {
+			return written, err
+		}
+
+		// NOTE: THE CHUNK IS COPIED AS io.Writer MUST NOT RETAIN p
+		data, err := NewData(s.id, s.offset, append([]byte(nil), p[:n]...))
+		if err != nil {
+			return written, err
+		}
+
+		if err := s.interceptor.write(s.laneCtx(), s.session, s.wrap, data); err != nil {
+			return written, fmt.Errorf("error while writing to stream %s; err: %w", s.id, err)
+		}
+
+		s.offset += int64(n)
+		written += n
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// Close tells the receiver that all the data was sent. The data already written is delivered
+// before the close as both are written in the lane of the stream.
+func (s *SendStream) Close() error {
+	s.writeMux.Lock()
+	defer s.writeMux.Unlock()
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	s.session.removeSender(s.id)
+	s.fail(ErrStreamClosed)
+
+	msg, err := NewClose(s.id)
+	if err != nil {
+		return err
+	}
+
+	return s.interceptor.write(s.laneCtx(), s.session, s.wrap, msg)
+}
+
+// Abort cancels the stream; the receiver gets an AbortError with the reason.
+// NOTE: THE ABORT IS WRITTEN IN THE DEFAULT LANE AND MAY OVERTAKE THE DATA ALREADY WRITTEN
+func (s *SendStream) Abort(reason string) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	s.session.removeSender(s.id)
+	s.fail(ErrStreamClosed)
+
+	msg, err := NewAbort(s.id, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.interceptor.write(s.ctx, s.session, s.wrap, msg)
+}
+
+// Err returns the reason the stream stopped or nil if it is still open
+func (s *SendStream) Err() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.err
+}
+
+// reserve waits until there is credit and takes up to max bytes of it
+func (s *SendStream) reserve(max int) (int, error) {
+	for {
+		s.mux.Lock()
+		if s.err != nil {
+			err := s.err
+			s.mux.Unlock()
+			return 0, err
+		}
+
+		if s.credit > 0 {
+			n := int(min(s.credit, int64(max)))
+			s.credit -= int64(n)
+			s.mux.Unlock()
+			return n, nil
+		}
+
+		changed := s.changed
+		s.mux.Unlock()
+
+		select {
+		case <-changed:
+		case <-s.ctx.Done():
+			return 0, s.ctx.Err()
+		}
+	}
+}
+
+func (s *SendStream) grant(credit uint32) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.credit += int64(credit)
+	s.notify()
+}
+
+func (s *SendStream) fail(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.err != nil {
+		return
+	}
+
+	s.err = err
+	s.notify()
+}
+
+// notify wakes up the waiting writer; the caller must hold mux
+func (s *SendStream) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
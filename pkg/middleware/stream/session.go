@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// session holds the streams of a single connection
+type session struct {
+	connection interceptor.Connection
+	writer     interceptor.Writer
+	senders    map[string]*SendStream
+	receivers  map[string]*ReceiveStream
+	mux        sync.Mutex
+}
+
+func newSession(connection interceptor.Connection, writer interceptor.Writer) *session {
+	return &session{
+		connection: connection,
+		writer:     writer,
+		senders:    make(map[string]*SendStream),
+		receivers:  make(map[string]*ReceiveStream),
+	}
+}
+
+func (s *session) addSender(stream *SendStream) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, exists := s.senders[stream.id]; exists {
+		return ErrStreamExists
+	}
+
+	s.senders[stream.id] = stream
+	return nil
+}
+
+func (s *session) sender(id string) *SendStream {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.senders[id]
+}
+
+func (s *session) removeSender(id string) *SendStream {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stream := s.senders[id]
+	delete(s.senders, id)
+
+	return stream
+}
+
+// addReceiver adds the incoming stream unless the session already has limit incoming streams
+func (s *session) addReceiver(stream *ReceiveStream, limit int) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, exists := s.receivers[stream.id]; exists {
+		return ErrStreamExists
+	}
+
+	if len(s.receivers) >= limit {
+		return ErrTooManyStreams
+	}
+
+	s.receivers[stream.id] = stream
+	return nil
+}
+
+func (s *session) receiver(id string) *ReceiveStream {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.receivers[id]
+}
+
+func (s *session) removeReceiver(id string) *ReceiveStream {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	stream := s.receivers[id]
+	delete(s.receivers, id)
+
+	return stream
+}
+
+// close fails every stream of the session without notifying the peer
+func (s *session) close(err error) {
+	s.mux.Lock()
+	senders, receivers := s.senders, s.receivers
+	s.senders = make(map[string]*SendStream)
+	s.receivers = make(map[string]*ReceiveStream)
+	s.mux.Unlock()
+
+	for _, stream := range senders {
+		stream.fail(err)
+	}
+
+	for _, stream := range receivers {
+		stream.fail(err)
+	}
+}
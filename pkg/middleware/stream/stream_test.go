@@ -0,0 +1,442 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+const (
+	testProtocol     message.Protocol = "test:message"
+	envelopeProtocol message.Protocol = "test:envelope"
+)
+
+type testMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *testMessage) GetProtocol() message.Protocol {
+	return testProtocol
+}
+
+// envelope stands in for a relaying middleware (like room forwarding)
+type envelope struct {
+	interceptor.BaseMessage
+	Via string `json:"via"`
+}
+
+func (m *envelope) GetProtocol() message.Protocol {
+	return envelopeProtocol
+}
+
+func wrapIn(via string, count *atomic.Int32) Wrapper {
+	return func(msg message.Message) (message.Message, error) {
+		count.Add(1)
+
+		e := &envelope{Via: via}
+		bmsg, err := interceptor.NewBaseMessage(msg.GetProtocol(), msg, e)
+		if err != nil {
+			return nil, err
+		}
+		e.BaseMessage = bmsg
+
+		return e, nil
+	}
+}
+
+type testConnection struct{ name string }
+
+func (c *testConnection) Write(_ context.Context, _ []byte) error { return nil }
+func (c *testConnection) Read(_ context.Context) ([]byte, error)  { return nil, nil }
+func (c *testConnection) Close() error                            { return nil }
+
+// wire serializes the messages written on one side and decodes them on the other side, like a socket would.
+// Envelopes are opened on read.
+type wire struct {
+	registry message.Registry
+	frames   chan []byte
+}
+
+func (w *wire) Write(ctx context.Context, _ interceptor.Connection, msg message.Message) error {
+	data, err := message.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case w.frames <- data:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *wire) Read(ctx context.Context, _ interceptor.Connection) (message.Message, error) {
+	select {
+	case frame := <-w.frames:
+		msg, err := w.registry.UnmarshalRaw(frame)
+		if err != nil {
+			return nil, err
+		}
+
+		if e, ok := msg.(*envelope); ok {
+			return e.GetNext(w.registry)
+		}
+
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type peer struct {
+	interceptor *Interceptor
+	connection  interceptor.Connection
+	writer      interceptor.Writer
+	messages    chan message.Message
+}
+
+func newTestRegistry(t *testing.T) message.Registry {
+	t.Helper()
+
+	registry := message.NewDefaultRegistry()
+	if err := RegisterMessages(registry); err != nil {
+		t.Fatalf("RegisterMessages() error = %v", err)
+	}
+
+	if err := message.RegisterAll(registry, message.Type[testMessage](testProtocol), message.Type[envelope](envelopeProtocol)); err != nil {
+		t.Fatalf("RegisterAll() error = %v", err)
+	}
+
+	return registry
+}
+
+func newPeer(t *testing.T, ctx context.Context, id interceptor.ClientID, registry message.Registry, out *wire, in *wire, options ...Option) *peer {
+	t.Helper()
+
+	_i, err := NewInterceptorFactory(options...).NewInterceptor(ctx, id, registry)
+	if err != nil {
+		t.Fatalf("NewInterceptor() error = %v", err)
+	}
+	i := _i.(*Interceptor)
+
+	p := &peer{
+		interceptor: i,
+		connection:  &testConnection{name: string(id)},
+		messages:    make(chan message.Message, 16),
+	}
+
+	p.writer = i.InterceptSocketWriter(out)
+	reader := i.InterceptSocketReader(in)
+
+	if _, _, err := i.BindSocketConnection(p.connection, p.writer, reader); err != nil {
+		t.Fatalf("BindSocketConnection() error = %v", err)
+	}
+
+	go func() {
+		for {
+			msg, err := reader.Read(ctx, p.connection)
+			if err != nil {
+				return
+			}
+			p.messages <- msg
+		}
+	}()
+
+	return p
+}
+
+// newPair connects two peers back to back; the receiver accepts the streams with the given options
+func newPair(t *testing.T, senderOptions []Option, receiverOptions []Option) (*peer, *peer) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	registry := newTestRegistry(t)
+	aToB := &wire{registry: registry, frames: make(chan []byte, 1024)}
+	bToA := &wire{registry: registry, frames: make(chan []byte, 1024)}
+
+	a := newPeer(t, ctx, "a", registry, aToB, bToA, senderOptions...)
+	b := newPeer(t, ctx, "b", registry, bToA, aToB, receiverOptions...)
+
+	return a, b
+}
+
+func acceptInto(streams chan *ReceiveStream) Option {
+	return WithAcceptFunc(func(_ interceptor.Connection, r *ReceiveStream) error {
+		streams <- r
+		return nil
+	})
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("rand.Read() error = %v", err)
+	}
+
+	return data
+}
+
+func TestStream_Transfer(t *testing.T) {
+	streams := make(chan *ReceiveStream, 1)
+	a, b := newPair(t, []Option{WithChunkSize(4 * 1024)}, []Option{acceptInto(streams), WithWindow(32 * 1024)})
+
+	payload := randomBytes(t, 512*1024)
+
+	s, err := a.interceptor.Open(context.Background(), a.connection, WithName("blob.bin"), WithContentType("application/octet-stream"), WithSize(int64(len(payload))))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		if _, err := s.Write(payload); err != nil {
+			writeErr <- err
+			return
+		}
+		writeErr <- s.Close()
+	}()
+
+	r := <-streams
+	if r.Name() != "blob.bin" || r.ContentType() != "application/octet-stream" || r.Size() != int64(len(payload)) {
+		t.Errorf("stream metadata = (%s, %s, %d), want (blob.bin, application/octet-stream, %d)", r.Name(), r.ContentType(), r.Size(), len(payload))
+	}
+
+	if r.Sender() != "a" {
+		t.Errorf("Sender() = %s, want a", r.Sender())
+	}
+
+	received, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if !bytes.Equal(received, payload) {
+		t.Errorf("received %d bytes which do not match the %d sent bytes", len(received), len(payload))
+	}
+
+	if err := <-writeErr; err != nil {
+		t.Errorf("Write() or Close() error = %v", err)
+	}
+
+	// regular messages still go through the interceptor
+	msg := &testMessage{Text: "hello"}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	if err := a.writer.Write(context.Background(), a.connection, msg); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	select {
+	case got := <-b.messages:
+		if m, ok := got.(*testMessage); !ok || m.Text != "hello" {
+			t.Errorf("Read() = %+v, want the test message", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("regular message not delivered")
+	}
+}
+
+func TestStream_FlowControl(t *testing.T) {
+	const window = 16 * 1024
+
+	streams := make(chan *ReceiveStream, 1)
+	a, _ := newPair(t, []Option{WithChunkSize(1024)}, []Option{acceptInto(streams), WithMaxWindow(window)})
+
+	s, err := a.interceptor.Open(context.Background(), a.connection, WithRequestedWindow(1024*1024))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	payload := randomBytes(t, 4*window)
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := s.Write(payload)
+		written <- err
+	}()
+
+	r := <-streams
+
+	// the receiver does not read; the sender must stop at the window
+	time.Sleep(100 * time.Millisecond)
+
+	select {
+	case err := <-written:
+		t.Fatalf("Write() returned (%v) before the receiver granted credit", err)
+	default:
+	}
+
+	r.mux.Lock()
+	received := r.received
+	r.mux.Unlock()
+
+	if received != window {
+		t.Errorf("received %d bytes without reading, want exactly the window (%d)", received, window)
+	}
+
+	got, err := io.ReadAll(io.LimitReader(r, int64(len(payload))))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if !bytes.Equal(got, payload) {
+		t.Errorf("received data does not match the sent data")
+	}
+
+	if err := <-written; err != nil {
+		t.Errorf("Write() error = %v", err)
+	}
+}
+
+func TestStream_Rejected(t *testing.T) {
+	a, _ := newPair(t, nil, nil)
+
+	s, err := a.interceptor.Open(context.Background(), a.connection)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	var abortErr *AbortError
+	if _, err := s.Write([]byte("data")); !errors.As(err, &abortErr) {
+		t.Fatalf("Write() error = %v, want *AbortError", err)
+	}
+}
+
+func TestStream_MaxStreams(t *testing.T) {
+	streams := make(chan *ReceiveStream, 2)
+	a, _ := newPair(t, nil, []Option{acceptInto(streams), WithMaxStreams(1)})
+
+	first, err := a.interceptor.Open(context.Background(), a.connection)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	<-streams
+
+	second, err := a.interceptor.Open(context.Background(), a.connection)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	var abortErr *AbortError
+	if _, err := second.Write([]byte("data")); !errors.As(err, &abortErr) || abortErr.Reason != ErrTooManyStreams.Error() {
+		t.Fatalf("Write() error = %v, want the stream above the limit rejected", err)
+	}
+
+	if _, err := first.Write([]byte("data")); err != nil {
+		t.Errorf("Write() error = %v, want the accepted stream kept open", err)
+	}
+}
+
+func TestStream_AbortedByReceiver(t *testing.T) {
+	streams := make(chan *ReceiveStream, 1)
+	a, _ := newPair(t, nil, []Option{acceptInto(streams), WithWindow(1024)})
+
+	s, err := a.interceptor.Open(context.Background(), a.connection)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := s.Write(make([]byte, 64*1024))
+		written <- err
+	}()
+
+	r := <-streams
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case err := <-written:
+		var abortErr *AbortError
+		if !errors.As(err, &abortErr) {
+			t.Errorf("Write() error = %v, want *AbortError", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Write() did not return after the receiver aborted")
+	}
+
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Read() after Close() error = %v, want %v", err, ErrStreamClosed)
+	}
+}
+
+func TestStream_UnBindFailsStreams(t *testing.T) {
+	a, _ := newPair(t, nil, nil)
+
+	s, err := a.interceptor.Open(context.Background(), a.connection)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	a.interceptor.UnBindSocketConnection(a.connection)
+
+	if _, err := s.Write([]byte("data")); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Write() error = %v, want %v", err, ErrConnectionClosed)
+	}
+
+	if _, err := a.interceptor.Open(context.Background(), a.connection); !errors.Is(err, ErrNotBound) {
+		t.Errorf("Open() error = %v, want %v", err, ErrNotBound)
+	}
+}
+
+func TestStream_Via(t *testing.T) {
+	var sent, replied atomic.Int32
+
+	streams := make(chan *ReceiveStream, 1)
+	a, _ := newPair(t, nil, []Option{
+		acceptInto(streams),
+		WithWindow(1024),
+		WithReplyWrapper(func(open *Open) Wrapper {
+			return wrapIn(open.Via, &replied)
+		}),
+	})
+
+	s, err := a.interceptor.Open(context.Background(), a.connection, WithVia("room", wrapIn("room", &sent)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	payload := randomBytes(t, 8*1024)
+	go func() {
+		_, _ = s.Write(payload)
+		_ = s.Close()
+	}()
+
+	r := <-streams
+	if r.Via() != "room" {
+		t.Errorf("Via() = %q, want room", r.Via())
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if !bytes.Equal(got, payload) {
+		t.Errorf("received data does not match the sent data")
+	}
+
+	if sent.Load() == 0 || replied.Load() == 0 {
+		t.Errorf("wrapped %d sent and %d replied messages, want both to be wrapped", sent.Load(), replied.Load())
+	}
+}
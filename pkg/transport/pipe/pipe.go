@@ -0,0 +1,143 @@
+// Package pipe is an in-process transport: a pair of connected interceptor.Connection ends.
+// It is meant for tests and for replaying recordings (see the recorder middleware).
+package pipe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+// DefaultCapacity is the number of messages buffered in each direction
+const DefaultCapacity = 64
+
+var ErrClosed = errors.New("pipe closed")
+
+// direction carries the messages written by one end to the other
+type direction struct {
+	ch   chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func newDirection(capacity int) *direction {
+	return &direction{
+		ch:   make(chan []byte, capacity),
+		done: make(chan struct{}),
+	}
+}
+
+func (d *direction) close() {
+	d.once.Do(func() {
+		close(d.done)
+	})
+}
+
+// Conn is one end of a pipe
+type Conn struct {
+	id  string
+	in  *direction
+	out *direction
+}
+
+// New creates the two ends of a pipe; both are identified by id
+func New(id string) (*Conn, *Conn) {
+	return NewWithCapacity(id, DefaultCapacity)
+}
+
+// NewWithCapacity is like New with the given number of messages buffered in each direction
+func NewWithCapacity(id string, capacity int) (*Conn, *Conn) {
+	var (
+		ab = newDirection(capacity)
+		ba = newDirection(capacity)
+	)
+
+	return &Conn{id: id, in: ba, out: ab}, &Conn{id: id, in: ab, out: ba}
+}
+
+// ID returns the identifier of the pipe
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Write sends a copy of p to the other end. It blocks while the buffer of the direction is full.
+func (c *Conn) Write(ctx context.Context, p []byte) error {
+	select {
+	case <-c.out.done:
+		return ErrClosed
+	default:
+	}
+
+	data := append([]byte(nil), p...)
+
+	select {
+	case c.out.ch <- data:
+		return nil
+	case <-c.out.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Read returns the next message written by the other end. Once the other end stopped writing
+// (see CloseWrite and Close), the buffered messages are returned and then io.EOF.
+func (c *Conn) Read(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-c.in.ch:
+		return data, nil
+	default:
+	}
+
+	select {
+	case data := <-c.in.ch:
+		return data, nil
+	case <-c.in.done:
+		select {
+		case data := <-c.in.ch:
+			return data, nil
+		default:
+			return nil, io.EOF
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CloseWrite stops the writes of this end; the other end reads the buffered messages and then io.EOF
+func (c *Conn) CloseWrite() error {
+	c.out.close()
+	return nil
+}
+
+// Close stops both directions
+func (c *Conn) Close() error {
+	c.out.close()
+	c.in.close()
+	return nil
+}
+
+// Writer returns the writer serializing the messages on the connection it is given, like the socket does
+func Writer() interceptor.Writer {
+	return interceptor.WriterFunc(func(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+		return message.Encode(msg, func(data []byte) error {
+			return connection.Write(ctx, data)
+		})
+	})
+}
+
+// Reader returns the reader deserializing the messages of the connection it is given with the registry
+func Reader(registry message.Registry) interceptor.Reader {
+	return interceptor.ReaderFunc(func(ctx context.Context, connection interceptor.Connection) (message.Message, error) {
+		data, err := connection.Read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return registry.UnmarshalRaw(data)
+	})
+}
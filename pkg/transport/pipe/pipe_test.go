@@ -0,0 +1,69 @@
+package pipe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestPipe_RoundTrip(t *testing.T) {
+	a, b := New("test")
+	ctx := context.Background()
+
+	data := []byte("hello")
+	if err := a.Write(ctx, data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data[0] = 'j'
+
+	got, err := b.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if string(got) != "hello" {
+		t.Errorf("Read() = %q, want %q; the pipe must not retain the written buffer", got, "hello")
+	}
+}
+
+func TestPipe_CloseWrite(t *testing.T) {
+	a, b := New("test")
+	ctx := context.Background()
+
+	if err := a.Write(ctx, []byte("last")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if err := a.CloseWrite(); err != nil {
+		t.Fatalf("CloseWrite() error = %v", err)
+	}
+
+	if got, err := b.Read(ctx); err != nil || string(got) != "last" {
+		t.Fatalf("Read() = %q, %v; want the buffered message", got, err)
+	}
+
+	if _, err := b.Read(ctx); !errors.Is(err, io.EOF) {
+		t.Errorf("Read() error = %v, want %v", err, io.EOF)
+	}
+
+	if err := a.Write(ctx, []byte("late")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write() after CloseWrite error = %v, want %v", err, ErrClosed)
+	}
+
+	// NOTE: THE OTHER DIRECTION IS STILL OPEN
+	if err := b.Write(ctx, []byte("reply")); err != nil {
+		t.Errorf("Write() on the open direction error = %v", err)
+	}
+}
+
+func TestPipe_ReadCancelled(t *testing.T) {
+	_, b := New("test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := b.Read(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Read() error = %v, want %v", err, context.Canceled)
+	}
+}
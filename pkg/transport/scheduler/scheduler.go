@@ -0,0 +1,244 @@
+// Package scheduler provides the queue used in the write path of a connection. Messages are
+// queued in priority classes which are served in proportion to their weights; within a class,
+// messages are queued in independent lanes which are served round-robin, so a lane with a
+// large backlog (for example, a file being streamed) cannot delay the messages of the other lanes.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrClosed       = errors.New("scheduler closed")
+	ErrUnknownClass = errors.New("unknown scheduler class")
+)
+
+// DefaultLaneCapacity is the number of elements a lane holds before Push blocks
+const DefaultLaneCapacity = 64
+
+// class is a set of lanes served round-robin
+type class[K comparable, T any] struct {
+	weight  int
+	current int // current is the smooth weighted round-robin credit of the class
+	queued  int
+	lanes   map[K][]T
+	active  []K // active lanes (with queued elements) in round-robin order
+	cursor  int
+}
+
+// Scheduler is a multi-class, multi-lane FIFO queue. Elements of the same class and lane are
+// popped in the order they were pushed; lanes of a class are served one element at a time in
+// round-robin order. Classes with queued elements are served using smooth weighted round-robin,
+// so a class with weight 4 gets four elements popped for every element of a class with weight 1,
+// and no class starves. Lanes are created on their first Push and forgotten as soon as they are empty.
+type Scheduler[K comparable, T any] struct {
+	capacity int
+	classes  []*class[K, T]
+	closed   bool
+	changed  chan struct{} // closed and replaced whenever the state changes
+	mux      sync.Mutex
+}
+
+// New creates a scheduler whose lanes hold at most capacity elements each. Every weight creates
+// a class (numbered from zero in the given order); without weights, the scheduler has a single class 0.
+func New[K comparable, T any](capacity int, weights ...int) *Scheduler[K, T] {
+	if capacity <= 0 {
+		capacity = DefaultLaneCapacity
+	}
+
+	if len(weights) == 0 {
+		weights = []int{1}
+	}
+
+	classes := make([]*class[K, T], 0, len(weights))
+	for _, weight := range weights {
+		classes = append(classes, &class[K, T]{
+			weight: max(weight, 1),
+			lanes:  make(map[K][]T),
+			active: make([]K, 0),
+		})
+	}
+
+	return &Scheduler[K, T]{
+		capacity: capacity,
+		classes:  classes,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Push appends the element to the lane of the class. It blocks while the lane is full, until the
+// context is done or the scheduler is closed.
+func (s *Scheduler[K, T]) Push(ctx context.Context, class int, lane K, element T) error {
+	if class < 0 || class >= len(s.classes) {
+		return fmt.Errorf("error while pushing to class %d; err: %w", class, ErrUnknownClass)
+	}
+
+	for {
+		s.mux.Lock()
+		if s.closed {
+			s.mux.Unlock()
+			return ErrClosed
+		}
+
+		c := s.classes[class]
+		queue, exists := c.lanes[lane]
+		if len(queue) < s.capacity {
+			if !exists {
+				c.active = append(c.active, lane)
+			}
+			c.lanes[lane] = append(queue, element)
+			c.queued++
+			s.notify()
+			s.mux.Unlock()
+			return nil
+		}
+
+		wait := s.changed
+		s.mux.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Pop removes the next element in weighted round-robin order. It blocks while the scheduler is empty,
+// until the context is done or the scheduler is closed.
+func (s *Scheduler[K, T]) Pop(ctx context.Context) (T, error) {
+	var zero T
+
+	for {
+		s.mux.Lock()
+		if s.closed {
+			s.mux.Unlock()
+			return zero, ErrClosed
+		}
+
+		if c := s.next(); c != nil {
+			element := c.pop()
+			s.notify()
+			s.mux.Unlock()
+			return element, nil
+		}
+
+		wait := s.changed
+		s.mux.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Len returns the number of queued elements in the lane of the class
+func (s *Scheduler[K, T]) Len(class int, lane K) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if class < 0 || class >= len(s.classes) {
+		return 0
+	}
+
+	return len(s.classes[class].lanes[lane])
+}
+
+// Queued returns the number of queued elements in all the lanes of the class
+func (s *Scheduler[K, T]) Queued(class int) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if class < 0 || class >= len(s.classes) {
+		return 0
+	}
+
+	return s.classes[class].queued
+}
+
+// Close wakes up all the blocked callers; subsequent calls return ErrClosed.
+func (s *Scheduler[K, T]) Close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	for _, c := range s.classes {
+		c.lanes = make(map[K][]T)
+		c.active = c.active[:0]
+		c.queued = 0
+	}
+	s.notify()
+}
+
+// next picks the class to pop from using smooth weighted round-robin; the caller must hold the lock
+func (s *Scheduler[K, T]) next() *class[K, T] {
+	var (
+		chosen *class[K, T]
+		total  int
+	)
+
+	for _, c := range s.classes {
+		if c.queued == 0 {
+			continue
+		}
+
+		c.current += c.weight
+		total += c.weight
+
+		if chosen == nil || c.current > chosen.current {
+			chosen = c
+		}
+	}
+
+	if chosen != nil {
+		chosen.current -= total
+	}
+
+	return chosen
+}
+
+// pop takes the head of the lane under the cursor; the caller must hold the lock
+func (c *class[K, T]) pop() T {
+	if c.cursor >= len(c.active) {
+		c.cursor = 0
+	}
+
+	lane := c.active[c.cursor]
+	queue := c.lanes[lane]
+
+	element := queue[0]
+	var zero T
+	queue[0] = zero // NOTE: RELEASE THE REFERENCE FOR THE GC
+	c.queued--
+
+	if c.queued == 0 {
+		// NOTE: AN IDLE CLASS DOES NOT KEEP CREDIT FROM ITS BUSY PERIOD
+		c.current = 0
+	}
+
+	if len(queue) == 1 {
+		delete(c.lanes, lane)
+		c.active = append(c.active[:c.cursor], c.active[c.cursor+1:]...)
+		// NOTE: THE CURSOR NOW POINTS TO THE LANE AFTER THE REMOVED ONE
+		return element
+	}
+
+	c.lanes[lane] = queue[1:]
+	c.cursor++
+	return element
+}
+
+// notify wakes up the goroutines waiting for a state change; the caller must hold the lock
+func (s *Scheduler[K, T]) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduler_RoundRobin(t *testing.T) {
+	s := New[string, string](0)
+	ctx := context.Background()
+
+	// NOTE: A BULK LANE WITH A BACKLOG IS QUEUED BEFORE A SINGLE CONTROL MESSAGE
+	for _, element := range []string{"b1", "b2", "b3"} {
+		if err := s.Push(ctx, 0, "bulk", element); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	if err := s.Push(ctx, 0, "control", "c1"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	want := []string{"b1", "c1", "b2", "b3"}
+	for i, w := range want {
+		got, err := s.Pop(ctx)
+		if err != nil {
+			t.Fatalf("Pop() error = %v", err)
+		}
+
+		if got != w {
+			t.Errorf("Pop() #%d = %s, want %s", i, got, w)
+		}
+	}
+
+	if s.Len(0, "bulk") != 0 || s.Len(0, "control") != 0 {
+		t.Errorf("lanes not empty after draining")
+	}
+}
+
+func TestScheduler_PushBlocksWhenLaneIsFull(t *testing.T) {
+	s := New[string, int](1)
+
+	if err := s.Push(context.Background(), 0, "bulk", 1); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	// NOTE: A FULL LANE DOES NOT BLOCK THE OTHER LANES
+	if err := s.Push(context.Background(), 0, "control", 2); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := s.Push(ctx, 0, "bulk", 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push() on full lane error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestScheduler_CloseWakesPop(t *testing.T) {
+	s := New[string, int](0)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := s.Pop(context.Background())
+		errs <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Pop() error = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Pop() did not return after Close()")
+	}
+}
+
+func TestScheduler_WeightedClasses(t *testing.T) {
+	const (
+		control = iota
+		bulk
+	)
+
+	s := New[string, string](0, 3, 1)
+	ctx := context.Background()
+
+	// NOTE: THE BULK BACKLOG IS QUEUED FIRST; CONTROL STILL GETS THREE POPS FOR EVERY BULK POP
+	for _, element := range []string{"b1", "b2", "b3"} {
+		if err := s.Push(ctx, bulk, "", element); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	for _, element := range []string{"c1", "c2", "c3", "c4", "c5", "c6"} {
+		if err := s.Push(ctx, control, "", element); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	if s.Queued(control) != 6 || s.Queued(bulk) != 3 {
+		t.Fatalf("Queued() = (%d, %d), want (6, 3)", s.Queued(control), s.Queued(bulk))
+	}
+
+	want := []string{"c1", "c2", "b1", "c3", "c4", "c5", "b2", "c6", "b3"}
+	for i, w := range want {
+		got, err := s.Pop(ctx)
+		if err != nil {
+			t.Fatalf("Pop() error = %v", err)
+		}
+
+		if got != w {
+			t.Errorf("Pop() #%d = %s, want %s", i, got, w)
+		}
+	}
+}
+
+func TestScheduler_UnknownClass(t *testing.T) {
+	s := New[string, int](0, 1, 1)
+
+	if err := s.Push(context.Background(), 2, "", 1); !errors.Is(err, ErrUnknownClass) {
+		t.Errorf("Push() error = %v, want %v", err, ErrUnknownClass)
+	}
+}
@@ -3,6 +3,7 @@ package socket
 import (
 	"context"
 
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 )
@@ -10,6 +11,7 @@ import (
 type API struct {
 	interceptorRegistry *interceptor.Registry
 	messagesRegistry    message.Registry
+	events              *events.Bus
 }
 
 type APIOption = func(*API) error
@@ -37,19 +39,32 @@ func NewAPI(options ...APIOption) (*API, error) {
 		}
 	}
 
+	if api.events == nil {
+		api.events = events.NewBus()
+	}
+
 	return api, nil
 }
 
+// Events returns the bus the sockets created by the API, and their interceptors, publish lifecycle events to
+func (a *API) Events() *events.Bus {
+	return a.events
+}
+
 // TODO: MAKE REGISTRIES TO NON POINTERS
 
 func (a *API) NewSocket(ctx context.Context, options ...Option) (*Socket, error) {
-	s := NewSocket(NewDefaultSettings(), a.messagesRegistry)
+	s := NewSocket(events.WithBus(ctx, a.events), NewDefaultSettings(), a.messagesRegistry)
 
-	interceptors, err := a.interceptorRegistry.Build(s.ctx, string(s.ID))
+	interceptors, err := a.interceptorRegistry.Build(s.ctx, interceptor.ClientID(s.ID))
 	if err != nil {
 		return nil, err
 	}
 
+	if chain, ok := interceptors.(*interceptor.Chain); ok {
+		chain.SetSelector(selectInterceptors(interceptor.SelectorFromContext(ctx)))
+	}
+
 	s.interceptor = interceptors
 
 	for _, option := range options {
@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/transport/scheduler"
 )
 
 var (
@@ -18,18 +22,21 @@ var (
 type adaptor struct {
 	connectionSettings
 	id         string
+	version    message.Version
 	conn       *websocket.Conn
 	readQ      Buffer[[]byte]
-	writeQ     Buffer[[]byte]
+	writeQ     *scheduler.Scheduler[interceptor.Lane, queued]
+	counters   []classCounters // counters of every priority class, indexed by interceptor.Priority
 	ctx        context.Context
 	cancel     context.CancelFunc
 	closeOnce  sync.Once
 	wg         sync.WaitGroup
 	closeErr   error
 	closeErrMu sync.Mutex
+	selects    func(name string) bool // decides the interceptors enabled for the connection; nil enables all
 }
 
-func newAdaptor(ctx context.Context, id string, conn *websocket.Conn, readTimeout time.Duration, writeTimeout time.Duration) *adaptor {
+func newAdaptor(ctx context.Context, id string, version message.Version, conn *websocket.Conn, readTimeout time.Duration, writeTimeout time.Duration, weights map[interceptor.Priority]int) *adaptor {
 	// Create a child context with cancellation
 	childCtx, cancel := context.WithCancel(ctx)
 
@@ -38,14 +45,32 @@ func newAdaptor(ctx context.Context, id string, conn *websocket.Conn, readTimeou
 			ReadTimeout:  readTimeout,
 			WriteTimeout: writeTimeout,
 		},
-		ctx:    childCtx,
-		cancel: cancel,
-		id:     id,
-		conn:   conn,
+		ctx:      childCtx,
+		cancel:   cancel,
+		id:       id,
+		version:  version,
+		conn:     conn,
+		readQ:    NewLimitKillBuffer[[]byte](DefaultReadQueueCapacity),
+		writeQ:   scheduler.New[interceptor.Lane, queued](scheduler.DefaultLaneCapacity, schedulerWeights(weights)...),
+		counters: make([]classCounters, len(interceptor.Priorities)),
 	}
 }
 
-// Write pushes the message of the type '[]byte' to the WriteQ, which will be later sent through the socket
+// ID returns the identifier of the connection
+func (a *adaptor) ID() string {
+	return a.id
+}
+
+// PeerVersion returns the message version negotiated with the peer during the upgrade
+func (a *adaptor) PeerVersion() message.Version {
+	return a.version
+}
+
+// Write pushes the message of the type '[]byte' to the WriteQ, which will be later sent through the socket.
+// The message is copied, so the caller is free to reuse p (see message.Encode).
+// The message is queued in the priority class and lane carried by the context (see interceptor.WithPriority
+// and interceptor.WithLane); classes are written to the socket by weight and lanes in round-robin order.
+// If the context carries an expiry (see interceptor.WithExpiry), the message is dropped if it is still queued then.
 func (a *adaptor) Write(ctx context.Context, p []byte) error {
 	if ctx == nil {
 		ctx = context.Background()
@@ -55,25 +80,50 @@ func (a *adaptor) Write(ctx context.Context, p []byte) error {
 	msgCopy := make([]byte, len(p))
 	copy(msgCopy, p)
 
-	return a.writeQ.Push(ctx, msgCopy)
+	priority, ok := interceptor.PriorityFromContext(ctx)
+	if !ok {
+		// NOTE: THE ZERO PRIORITY IS PriorityControl; UNCLASSIFIED WRITES ARE INTERACTIVE
+		priority = interceptor.PriorityInteractive
+	}
+	element := queued{data: msgCopy, priority: priority, at: time.Now(), expiry: interceptor.ExpiryFromContext(ctx)}
+
+	return a.writeQ.Push(ctx, int(priority), interceptor.LaneFromContext(ctx), element)
+}
+
+// PriorityMetrics returns the write path statistics of every priority class of the connection
+func (a *adaptor) PriorityMetrics() []ClassMetrics {
+	metrics := make([]ClassMetrics, 0, len(interceptor.Priorities))
+	for _, priority := range interceptor.Priorities {
+		counters := &a.counters[priority]
+
+		m := ClassMetrics{
+			Priority: priority,
+			Queued:   a.writeQ.Queued(int(priority)),
+			Written:  counters.written.Load(),
+			Bytes:    counters.bytes.Load(),
+			Expired:  counters.expired.Load(),
+		}
+
+		if m.Written > 0 {
+			m.AverageWait = time.Duration(counters.wait.Load() / int64(m.Written))
+		}
+
+		metrics = append(metrics, m)
+	}
+
+	return metrics
 }
 
 // Read reads a message of the type '[]byte' from the ReadQ, which was read from the websocket.
+// NOTE: THE BUFFER IS NOT COPIED; EVERY BUFFER READ FROM THE WEBSOCKET IS FRESHLY ALLOCATED AND POPPED
+// NOTE: EXACTLY ONCE, SO ITS OWNERSHIP MOVES TO THE CALLER (THE DECODED MESSAGES ALIAS IT). FOR THE SAME
+// NOTE: REASON THE READ BUFFERS ARE NOT POOLED: NOTHING TELLS WHEN THE LAST MESSAGE ALIASING ONE IS DROPPED
 func (a *adaptor) Read(ctx context.Context) ([]byte, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	data, err := a.readQ.Pop(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Return a copy to prevent data races
-	dataCopy := make([]byte, len(data))
-	copy(dataCopy, data)
-
-	return dataCopy, nil
+	return a.readQ.Pop(ctx)
 }
 
 func (a *adaptor) StartReaderWriter() {
@@ -93,7 +143,7 @@ func (a *adaptor) Writer() {
 		default:
 			// Use a timeout context for the write operation
 			writeCtx, cancel := context.WithTimeout(a.ctx, a.WriteTimeout)
-			p, err := a.writeQ.Pop(writeCtx)
+			element, err := a.writeQ.Pop(writeCtx)
 			cancel()
 
 			if err != nil {
@@ -112,15 +162,28 @@ func (a *adaptor) Writer() {
 				continue
 			}
 
+			waited := time.Since(element.at)
+
+			if !element.expiry.IsZero() && time.Now().After(element.expiry) {
+				// NOTE: THE MESSAGE EXPIRED WHILE QUEUED; IT IS DROPPED WITHOUT BEING WRITTEN
+				a.counters[element.priority].expired.Add(1)
+				continue
+			}
+
 			// Use a timeout context for the websocket write
 			writeCtx, cancel = context.WithTimeout(a.ctx, a.WriteTimeout)
-			err = a.conn.Write(writeCtx, websocket.MessageText, p)
+			err = a.conn.Write(writeCtx, websocket.MessageText, element.data)
 			cancel()
 
 			if err != nil {
 				fmt.Printf("Error while writing message to socket; err: %s\n", err.Error())
 				return
 			}
+
+			counters := &a.counters[element.priority]
+			counters.written.Add(1)
+			counters.bytes.Add(uint64(len(element.data)))
+			counters.wait.Add(int64(waited))
 		}
 	}
 }
@@ -161,13 +224,19 @@ func (a *adaptor) Reader() {
 
 // Close initiates a graceful shutdown of the connection
 func (a *adaptor) Close() error {
+	return a.CloseWithCode(int(websocket.StatusNormalClosure), "connection closed")
+}
+
+// CloseWithCode shuts the connection down, sending the close code and reason to the peer
+// (see interceptor.CodeCloser). Only the first call closes the connection.
+func (a *adaptor) CloseWithCode(code int, reason string) error {
 	var err error
 	a.closeOnce.Do(func() {
 		// Cancel the context to signal all goroutines to stop
 		a.cancel()
 
 		// Try to send a close message to the peer
-		closeErr := a.conn.Close(websocket.StatusNormalClosure, "connection closed")
+		closeErr := a.conn.Close(websocket.StatusCode(code), reason)
 		if closeErr != nil {
 			err = closeErr
 		}
@@ -0,0 +1,110 @@
+package socket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+func newTestAdaptor(t *testing.T) *adaptor {
+	t.Helper()
+
+	a := newAdaptor(context.Background(), "connection", message.CurrentVersion, nil, time.Second, time.Second, nil)
+	t.Cleanup(func() {
+		a.cancel()
+		a.readQ.Close()
+		a.writeQ.Close()
+	})
+
+	return a
+}
+
+func TestAdaptor_ReadQueue(t *testing.T) {
+	a := newTestAdaptor(t)
+
+	if err := a.readQ.Push(context.Background(), []byte("first")); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	data, err := a.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if string(data) != "first" {
+		t.Errorf("Read() = %q, want %q", data, "first")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := a.Read(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Read() error = %v, want %v on an empty queue", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLimitKillBuffer(t *testing.T) {
+	b := NewLimitKillBuffer[int](1)
+
+	killed, kill := context.WithCancel(context.Background())
+	if err := b.Push(killed, 1); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	// NOTE: FULL; THE PUSH BLOCKS UNTIL ITS CONTEXT IS DONE
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := b.Push(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push() error = %v, want %v on a full buffer", err, context.DeadlineExceeded)
+	}
+
+	kill()
+	go func() {
+		_ = b.Push(context.Background(), 3)
+	}()
+
+	got, err := b.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop() error = %v", err)
+	}
+
+	if got != 3 {
+		t.Errorf("Pop() = %d, want the killed element dropped", got)
+	}
+
+	b.Close()
+
+	if _, err := b.Pop(context.Background()); !errors.Is(err, ErrBufferClosed) {
+		t.Errorf("Pop() error = %v, want %v", err, ErrBufferClosed)
+	}
+
+	if err := b.Push(context.Background(), 4); !errors.Is(err, ErrBufferClosed) {
+		t.Errorf("Push() error = %v, want %v", err, ErrBufferClosed)
+	}
+}
+
+func TestAdaptor_WriteQueuesByPriority(t *testing.T) {
+	a := newTestAdaptor(t)
+
+	if err := a.Write(interceptor.WithPriority(context.Background(), interceptor.PriorityControl), []byte("control")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if err := a.Write(context.Background(), []byte("interactive")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	queued := make(map[interceptor.Priority]int)
+	for _, m := range a.PriorityMetrics() {
+		queued[m.Priority] = m.Queued
+	}
+
+	if queued[interceptor.PriorityControl] != 1 || queued[interceptor.PriorityInteractive] != 1 || queued[interceptor.PriorityBulk] != 0 {
+		t.Errorf("queued = %v, want one control and one interactive message", queued)
+	}
+}
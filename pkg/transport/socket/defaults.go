@@ -1,17 +1,21 @@
 package socket
 
 import (
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 )
 
 func WithDefaultInterceptorRegistry(registry *interceptor.Registry) error {
-	registry.Register(nil)
-	// TODO: IMPLEMENT THIS
+	// TODO: IMPLEMENT THIS; REGISTER THE DEFAULT FACTORIES. Build FAILS ON NIL FACTORIES (SEE interceptor.ErrNilFactory)
 	return nil
 }
 
 func WithDefaultMessageRegistry(registry message.Registry) error {
+	if err := message.RegisterAll(registry, message.Registrations()...); err != nil {
+		return err
+	}
+
 	// TODO: IMPLEMENT THIS
 	return nil
 }
@@ -38,3 +42,11 @@ func WithMessageRegistry(registry message.Registry) APIOption {
 		return nil
 	}
 }
+
+// WithEventBus sets the bus the sockets publish lifecycle events to; by default the API creates its own
+func WithEventBus(bus *events.Bus) APIOption {
+	return func(api *API) error {
+		api.events = bus
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package socket
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+)
+
+// DefaultPriorityWeights serves sixteen control and four interactive messages for every bulk message
+var DefaultPriorityWeights = map[interceptor.Priority]int{
+	interceptor.PriorityControl:     16,
+	interceptor.PriorityInteractive: 4,
+	interceptor.PriorityBulk:        1,
+}
+
+// queued is an element of the write queue of a connection
+type queued struct {
+	data     []byte
+	priority interceptor.Priority
+	at       time.Time
+	expiry   time.Time // expiry is the zero time if the message never expires
+}
+
+// classCounters holds the write path statistics of a priority class of a connection
+type classCounters struct {
+	written atomic.Uint64
+	bytes   atomic.Uint64
+	expired atomic.Uint64
+	wait    atomic.Int64 // wait is the total time spent in the queue, in nanoseconds
+}
+
+// ClassMetrics holds the write path statistics of a priority class
+type ClassMetrics struct {
+	Priority    interceptor.Priority `json:"-"`
+	Queued      int                  `json:"queued"`
+	Written     uint64               `json:"written"`
+	Bytes       uint64               `json:"bytes"`
+	Expired     uint64               `json:"expired"` // Expired counts the messages dropped in the queue as they expired
+	AverageWait time.Duration        `json:"average_wait_ns"`
+}
+
+// add accumulates the statistics of another connection
+func (m *ClassMetrics) add(other ClassMetrics) {
+	totalWait := m.AverageWait*time.Duration(m.Written) + other.AverageWait*time.Duration(other.Written)
+
+	m.Queued += other.Queued
+	m.Written += other.Written
+	m.Bytes += other.Bytes
+	m.Expired += other.Expired
+
+	if m.Written > 0 {
+		m.AverageWait = totalWait / time.Duration(m.Written)
+	}
+}
+
+// prioritized is implemented by the connections which queue their writes by priority class
+type prioritized interface {
+	PriorityMetrics() []ClassMetrics
+}
+
+// schedulerWeights returns the weight of every priority class, in the order of interceptor.Priorities
+func schedulerWeights(weights map[interceptor.Priority]int) []int {
+	result := make([]int, 0, len(interceptor.Priorities))
+	for _, priority := range interceptor.Priorities {
+		weight, ok := weights[priority]
+		if !ok || weight <= 0 {
+			weight = DefaultPriorityWeights[priority]
+		}
+
+		result = append(result, weight)
+	}
+
+	return result
+}
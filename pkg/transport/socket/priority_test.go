@@ -0,0 +1,79 @@
+package socket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+	"github.com/harshabose/socket-comm/pkg/middleware/encrypt/encryptor"
+)
+
+type controlMessage struct {
+	interceptor.BaseMessage
+	Text string `json:"text"`
+}
+
+func (m *controlMessage) GetProtocol() message.Protocol {
+	return "test:control"
+}
+
+func (m *controlMessage) Priority() interceptor.Priority {
+	return interceptor.PriorityControl
+}
+
+func newControlMessage(t *testing.T) *controlMessage {
+	t.Helper()
+
+	msg := &controlMessage{Text: "keep alive"}
+	bmsg, err := interceptor.NewBaseMessage(message.NoneProtocol, nil, msg)
+	if err != nil {
+		t.Fatalf("NewBaseMessage() error = %v", err)
+	}
+	msg.BaseMessage = bmsg
+
+	return msg
+}
+
+func TestSocket_WrappedControlMessageIsControl(t *testing.T) {
+	s := NewSocket(context.Background(), NewDefaultSettings(), message.NewDefaultRegistry())
+	t.Cleanup(s.cancel)
+
+	a := newTestAdaptor(t)
+
+	inner, err := encryptor.NewEncryptedMessage(newControlMessage(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedMessage() error = %v", err)
+	}
+
+	// NOTE: WRAPPED TWICE; THE PRIORITY IS CARRIED BY EVERY ENVELOPE
+	outer, err := encryptor.NewEncryptedMessage(inner)
+	if err != nil {
+		t.Fatalf("NewEncryptedMessage() error = %v", err)
+	}
+
+	if err := s.Write(context.Background(), a, outer); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if n := a.writeQ.Queued(int(interceptor.PriorityControl)); n != 1 {
+		t.Errorf("control class queued %d messages, want the wrapped control message", n)
+	}
+}
+
+func TestResolvePriority_Inherited(t *testing.T) {
+	wrapped, err := encryptor.NewEncryptedMessage(newControlMessage(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedMessage() error = %v", err)
+	}
+
+	if got := interceptor.ResolvePriority(context.Background(), wrapped, nil); got != interceptor.PriorityControl {
+		t.Errorf("ResolvePriority() = %s, want %s", got, interceptor.PriorityControl)
+	}
+
+	// NOTE: THE CONTEXT STILL WINS
+	ctx := interceptor.WithPriority(context.Background(), interceptor.PriorityBulk)
+	if got := interceptor.ResolvePriority(ctx, wrapped, nil); got != interceptor.PriorityBulk {
+		t.Errorf("ResolvePriority() = %s, want %s", got, interceptor.PriorityBulk)
+	}
+}
@@ -2,11 +2,14 @@ package socket
 
 import (
 	"context"
+	"errors"
+	"sync"
 )
 
-// TODO: LIMIT AND SELF KILL BUFFER.
-// ELEMENT IS RESPONSIBLE TO KILL AND FREE ITS MEMORY. IT SHOULD ALSO DELETE ITSELF FROM THE PARENT
-// HARD LIMIT ON NUMBER OF BUFFERS
+// DefaultReadQueueCapacity is the number of messages read from the websocket which are buffered until read
+const DefaultReadQueueCapacity = 256
+
+var ErrBufferClosed = errors.New("buffer closed")
 
 type Buffered[T any] struct {
 	element T
@@ -19,22 +22,62 @@ type Buffer[T any] interface {
 	Close()
 }
 
+// LimitKillBuffer is a Buffer holding a limited number of elements; Push blocks while the buffer is full.
+// Every element is pushed along with a context; an element whose context is done before it is popped
+// kills itself and is dropped by Pop.
 type LimitKillBuffer[T any] struct {
 	buffer chan Buffered[T]
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewLimitKillBuffer[T any](limit int) *LimitKillBuffer[T] {
+	return &LimitKillBuffer[T]{
+		buffer: make(chan Buffered[T], limit),
+		done:   make(chan struct{}),
+	}
 }
 
 func (b *LimitKillBuffer[T]) Pop(ctx context.Context) (T, error) {
+	var zero T
+
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-b.done:
+			return zero, ErrBufferClosed
+		case buffered := <-b.buffer:
+			if buffered.ctx != nil && buffered.ctx.Err() != nil {
+				// NOTE: KILLED WHILE BUFFERED; DROPPED
+				continue
+			}
 
+			return buffered.element, nil
+		}
+	}
 }
 
 func (b *LimitKillBuffer[T]) Push(ctx context.Context, element T) error {
+	select {
+	case <-b.done:
+		return ErrBufferClosed
+	default:
+	}
 
+	select {
+	case b.buffer <- Buffered[T]{element: element, ctx: ctx}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.done:
+		return ErrBufferClosed
+	}
 }
 
+// Close wakes up the blocked Push and Pop calls; the buffered elements are dropped
 func (b *LimitKillBuffer[T]) Close() {
-
-}
-
-func (b *LimitKillBuffer[T]) manager() {
-
+	b.once.Do(func() {
+		close(b.done)
+	})
 }
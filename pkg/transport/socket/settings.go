@@ -4,7 +4,12 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
 )
 
 var ErrSettingsInvalid = errors.New("server settings invalid")
@@ -31,11 +36,70 @@ type Settings struct {
 
 	PopMessageTimeout time.Duration
 	PushMessageTimout time.Duration
+
+	// NOTE: PRIORITIES ASSIGNS PRIORITY CLASSES TO PROTOCOLS; SEE interceptor.ResolvePriority FOR THE PRECEDENCE
+	Priorities      map[message.Protocol]interceptor.Priority
+	PriorityWeights map[interceptor.Priority]int
+
+	// NOTE: IF SET, THE SENDER OF A MESSAGE WHICH EXPIRED BEFORE IT WAS READ GETS A message.ExpiredMessage
+	ReportExpired bool
+
+	// NOTE: IF SET, DECIDES AT THE UPGRADE WHICH INTERCEPTORS ARE ENABLED FOR THE CONNECTION (FOR EXAMPLE, FROM
+	// NOTE: THE AUTH CLAIMS OF THE REQUEST); SEE interceptor.Selector. EVERY INTERCEPTOR IS ENABLED OTHERWISE
+	SelectInterceptors func(request *http.Request, name string) bool
 }
 
+// Validate returns an error wrapping ErrSettingsInvalid if a setting is invalid. Zero durations other
+// than the message timeouts disable the corresponding timeout of the http.Server; missing or zero priority
+// weights fall back to DefaultPriorityWeights.
 func (s Settings) Validate() error {
-	// TODO: IMPLEMENT THIS
-	return ErrSettingsInvalid
+	if s.MaxConnections <= 0 {
+		return fmt.Errorf("error while validating settings; max connections must be positive, got %d; err: %w", s.MaxConnections, ErrSettingsInvalid)
+	}
+
+	for name, timeout := range map[string]time.Duration{
+		"pop message timeout":  s.PopMessageTimeout,
+		"push message timeout": s.PushMessageTimout,
+	} {
+		if timeout <= 0 {
+			return fmt.Errorf("error while validating settings; %s must be positive, got %s; err: %w", name, timeout, ErrSettingsInvalid)
+		}
+	}
+
+	for name, timeout := range map[string]time.Duration{
+		"read timeout":        s.ReadTimeout,
+		"write timeout":       s.WriteTimeout,
+		"read header timeout": s.ReadHeaderTimeout,
+		"idle timeout":        s.IdleTimout,
+		"shutdown timeout":    s.ShutdownTimout,
+		"connection timeout":  s.ConnectionTimeout,
+	} {
+		if timeout < 0 {
+			return fmt.Errorf("error while validating settings; %s must not be negative, got %s; err: %w", name, timeout, ErrSettingsInvalid)
+		}
+	}
+
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		return fmt.Errorf("error while validating settings; TLS needs both the cert and key files; err: %w", ErrSettingsInvalid)
+	}
+
+	for protocol, priority := range s.Priorities {
+		if !slices.Contains(interceptor.Priorities, priority) {
+			return fmt.Errorf("error while validating settings; unknown priority %d of %s; err: %w", priority, protocol, ErrSettingsInvalid)
+		}
+	}
+
+	for priority, weight := range s.PriorityWeights {
+		if !slices.Contains(interceptor.Priorities, priority) {
+			return fmt.Errorf("error while validating settings; weight of unknown priority %d; err: %w", priority, ErrSettingsInvalid)
+		}
+
+		if weight < 0 {
+			return fmt.Errorf("error while validating settings; weight of %s must not be negative, got %d; err: %w", priority, weight, ErrSettingsInvalid)
+		}
+	}
+
+	return nil
 }
 
 func NewDefaultSettings() Settings {
@@ -53,6 +117,12 @@ func NewDefaultSettings() Settings {
 		MaxConnections:    1000,
 		PopMessageTimeout: 30 * time.Second,
 		PushMessageTimout: 30 * time.Second,
+		Priorities: map[message.Protocol]interceptor.Priority{
+			message.ValidationErrorProtocol: interceptor.PriorityControl,
+			message.ExpiredProtocol:         interceptor.PriorityControl,
+			message.ErrorReportProtocol:     interceptor.PriorityControl,
+		},
+		PriorityWeights: DefaultPriorityWeights,
 	}
 }
 
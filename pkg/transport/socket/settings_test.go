@@ -0,0 +1,33 @@
+package socket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	if err := NewDefaultSettings().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want the default settings valid", err)
+	}
+
+	for name, change := range map[string]func(*Settings){
+		"max connections":      func(s *Settings) { s.MaxConnections = 0 },
+		"pop message timeout":  func(s *Settings) { s.PopMessageTimeout = 0 },
+		"push message timeout": func(s *Settings) { s.PushMessageTimout = -time.Second },
+		"idle timeout":         func(s *Settings) { s.IdleTimout = -time.Second },
+		"tls key only":         func(s *Settings) { s.TLSKeyFile = "key.pem" },
+		"priority":             func(s *Settings) { s.Priorities = map[message.Protocol]interceptor.Priority{"test:text": 42} },
+		"priority weight":      func(s *Settings) { s.PriorityWeights = map[interceptor.Priority]int{interceptor.PriorityBulk: -1} },
+	} {
+		settings := NewDefaultSettings()
+		change(&settings)
+
+		if err := settings.Validate(); !errors.Is(err, ErrSettingsInvalid) {
+			t.Errorf("%s: Validate() error = %v, want %v", name, err, ErrSettingsInvalid)
+		}
+	}
+}
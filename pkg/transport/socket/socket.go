@@ -2,6 +2,7 @@ package socket
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"github.com/coder/websocket"
 	"github.com/google/uuid"
 
+	"github.com/harshabose/socket-comm/pkg/events"
 	"github.com/harshabose/socket-comm/pkg/interceptor"
 	"github.com/harshabose/socket-comm/pkg/message"
 	"github.com/harshabose/socket-comm/pkg/transport/types"
@@ -26,9 +28,16 @@ type Metrics struct {
 	ActiveConnections int
 	TotalConnections  int
 	FailedConnections int
+	ExpiredOnWrite    int // ExpiredOnWrite counts the messages which expired before they were written
+	ExpiredOnRead     int // ExpiredOnRead counts the received messages which expired before they were read
 	mux               sync.RWMutex
 }
 
+// versioned is implemented by the connections which negotiated a message version with the peer
+type versioned interface {
+	PeerVersion() message.Version
+}
+
 type Socket struct {
 	ID              types.SocketID `json:"id"`
 	server          *http.Server
@@ -36,6 +45,7 @@ type Socket struct {
 	settings        Settings
 	interceptor     interceptor.Interceptor
 	connections     map[string]interceptor.Connection
+	writers         map[interceptor.Connection]interceptor.Writer // writers traverse the interceptor chain bound to the connections
 	metrics         *Metrics
 	messageRegistry message.Registry
 	cancel          context.CancelFunc
@@ -50,6 +60,7 @@ func NewSocket(ctx context.Context, settings Settings, registry message.Registry
 		settings:        settings,
 		messageRegistry: registry,
 		connections:     make(map[string]interceptor.Connection),
+		writers:         make(map[interceptor.Connection]interceptor.Writer),
 		metrics:         &Metrics{},
 		cancel:          cancel,
 		ctx:             ctx2,
@@ -91,6 +102,7 @@ func (s *Socket) Init() error {
 	s.router.HandleFunc("/ws", s.handleWebSocket)
 	s.router.HandleFunc("/health", s.handleHealth)
 	s.router.HandleFunc("/metrics", s.handleMetrics)
+	s.router.HandleFunc("/schema", s.handleSchema)
 	return nil
 }
 
@@ -127,24 +139,139 @@ func (s *Socket) Read(ctx context.Context, connection interceptor.Connection) (m
 	ctx, cancel := context.WithTimeout(s.ctx, s.settings.PopMessageTimeout)
 	defer cancel()
 
-	data, err := connection.Read(ctx)
+	for {
+		data, err := connection.Read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, err := s.messageRegistry.UnmarshalRaw(data)
+		if err != nil {
+			var validationErr *message.ValidationError
+			if errors.As(err, &validationErr) {
+				s.replyValidationError(connection, validationErr)
+			}
+			return nil, err
+		}
+
+		if message.IsExpired(msg) {
+			// NOTE: EXPIRED MESSAGES ARE DROPPED BEFORE THE INTERCEPTORS SEE THEM; KEEP READING
+			s.metrics.mux.Lock()
+			s.metrics.ExpiredOnRead++
+			s.metrics.mux.Unlock()
+
+			if s.settings.ReportExpired {
+				s.replyExpired(connection, msg)
+			}
+			continue
+		}
+
+		// NOTE: THE MESSAGE CARRIES THE READ SPAN SO THAT THE PROCESS SPANS BECOME ITS CHILDREN
+		_, span := interceptor.StartSpan(ctx, interceptor.StageRead, msg)
+		interceptor.InjectTrace(msg, span.SpanContext())
+		span.End()
+
+		return msg, nil
+	}
+}
+
+// replyExpired reports back to the sender that its message expired before it was read; like every other
+// message of the connection, the reply is written through the interceptors
+func (s *Socket) replyExpired(connection interceptor.Connection, msg message.Message) {
+	reply, err := message.NewExpiredMessage(msg, message.StageRead)
+	if err != nil {
+		fmt.Println("error while creating expired message; err:", err.Error())
+		return
+	}
+
+	if err := s.writerOf(connection).Write(s.ctx, connection, reply); err != nil {
+		fmt.Println("error while sending expired message; err:", err.Error())
+	}
+}
+
+// replyValidationError reports the validation error back to the sender of the rejected message.
+// NOTE: THE INTERCEPTORS NEVER SAW THE REJECTED MESSAGE, BUT THE REPLY IS WRITTEN THROUGH THEM, SO THAT IT IS
+// NOTE: ENCRYPTED, COMPRESSED, ETC. LIKE EVERY OTHER MESSAGE OF THE CONNECTION
+func (s *Socket) replyValidationError(connection interceptor.Connection, validationErr *message.ValidationError) {
+	reply, err := message.NewValidationErrorMessage(validationErr)
 	if err != nil {
-		return nil, err
+		fmt.Println("error while creating validation error message; err:", err.Error())
+		return
+	}
+
+	if err := s.writerOf(connection).Write(s.ctx, connection, reply); err != nil {
+		fmt.Println("error while sending validation error message; err:", err.Error())
+	}
+}
+
+// writerOf returns the writer of the interceptor chain bound to the connection, or the socket itself if the
+// connection is not bound
+func (s *Socket) writerOf(connection interceptor.Connection) interceptor.Writer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if writer, exists := s.writers[connection]; exists {
+		return writer
 	}
 
-	return s.messageRegistry.UnmarshalRaw(data)
+	return s
 }
 
 func (s *Socket) Write(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
-	ctx, cancel := context.WithTimeout(s.ctx, s.settings.PushMessageTimout)
-	defer cancel()
+	if ctx == nil {
+		ctx = s.ctx
+	}
 
-	data, err := msg.Marshal()
-	if err != nil {
+	ctx, span := interceptor.StartSpan(ctx, interceptor.StageWrite, msg)
+	defer span.End()
+
+	// NOTE: THE MESSAGE CARRIES THE WRITE SPAN SO THAT THE READ SPAN OF THE PEER BECOMES ITS CHILD
+	interceptor.InjectTrace(msg, span.SpanContext())
+
+	if err := s.write(ctx, connection, msg); err != nil {
+		span.RecordError(err)
 		return err
 	}
 
-	return connection.Write(ctx, data)
+	return nil
+}
+
+func (s *Socket) write(ctx context.Context, connection interceptor.Connection, msg message.Message) error {
+	// NOTE: THE CALLER'S CONTEXT IS KEPT AS IT CARRIES THE LANE (SEE interceptor.WithLane)
+	ctx, cancel := context.WithTimeout(ctx, s.settings.PushMessageTimout)
+	defer cancel()
+
+	ctx = interceptor.WithPriority(ctx, interceptor.ResolvePriority(ctx, msg, s.settings.Priorities))
+
+	if msg != nil {
+		header := msg.GetCurrentHeader()
+		if header.Expired(time.Now()) {
+			s.metrics.mux.Lock()
+			s.metrics.ExpiredOnWrite++
+			s.metrics.mux.Unlock()
+
+			return fmt.Errorf("error while writing message %s; err: %w", msg.GetProtocol(), message.ErrExpired)
+		}
+
+		if !header.Expiry.IsZero() {
+			// NOTE: THE CONNECTION DROPS THE MESSAGE IF IT EXPIRES WHILE QUEUED
+			ctx = interceptor.WithExpiry(ctx, header.Expiry)
+		}
+	}
+
+	// NOTE: THE MESSAGE IS SERIALIZED IN THE VERSION NEGOTIATED WITH THE PEER OF THE CONNECTION
+	if peer, ok := connection.(versioned); ok && peer.PeerVersion() != "" && peer.PeerVersion() != message.CurrentVersion {
+		data, err := s.messageRegistry.Downgrade(msg, peer.PeerVersion())
+		if err != nil {
+			return err
+		}
+
+		return connection.Write(ctx, data)
+	}
+
+	return message.Encode(msg, func(data []byte) error {
+		return connection.Write(ctx, data)
+	})
 }
 
 // registerConnection adds a new connection
@@ -174,6 +301,21 @@ func (s *Socket) unregisterConnection(id string) {
 	}
 }
 
+// bindWriter keeps the writer of the interceptor chain bound to the connection (see writerOf)
+func (s *Socket) bindWriter(connection interceptor.Connection, writer interceptor.Writer) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.writers[connection] = writer
+}
+
+func (s *Socket) unbindWriter(connection interceptor.Connection) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	delete(s.writers, connection)
+}
+
 // closeAllConnections closes all active connections
 func (s *Socket) closeAllConnections() {
 	s.mux.Lock()
@@ -197,6 +339,21 @@ func (s *Socket) handleWebSocket(writer http.ResponseWriter, request *http.Reque
 		return
 	}
 
+	// NOTE: THE PEER ADVERTISES THE MESSAGE VERSION IT UNDERSTANDS USING THE "version" QUERY PARAMETER
+	version := message.Version(request.URL.Query().Get("version"))
+	if version == "" {
+		version = message.CurrentVersion
+	}
+
+	if !s.messageRegistry.Supports(version) {
+		s.metrics.mux.Lock()
+		s.metrics.FailedConnections++
+		s.metrics.mux.Unlock()
+
+		http.Error(writer, fmt.Sprintf("unsupported message version %s", version), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := websocket.Accept(writer, request, nil)
 	if err != nil {
 		s.metrics.mux.Lock()
@@ -208,29 +365,102 @@ func (s *Socket) handleWebSocket(writer http.ResponseWriter, request *http.Reque
 	}
 
 	iD := uuid.NewString()
-	connection := newAdaptor(request.Context(), iD, conn, s.settings.PopMessageTimeout, s.settings.PushMessageTimout)
+	connection := newAdaptor(request.Context(), iD, version, conn, s.settings.PopMessageTimeout, s.settings.PushMessageTimout, s.settings.PriorityWeights)
+
+	if s.settings.SelectInterceptors != nil {
+		connection.selects = func(name string) bool {
+			return s.settings.SelectInterceptors(request, name)
+		}
+	}
 
 	s.registerConnection(iD, connection)
 	defer s.unregisterConnection(iD)
 
 	connection.StartReaderWriter()
 
-	if _, _, err := s.interceptor.BindSocketConnection(connection, s, s); err != nil {
+	chained, _, err := s.interceptor.BindSocketConnection(connection, s, s)
+	if err != nil {
 		fmt.Println(fmt.Errorf("error while binding socket to interceptors; err: %s", err.Error()))
 		fmt.Println("dropping client...")
 		return
 	}
 	defer s.interceptor.UnBindSocketConnection(connection)
 
+	if chained != nil {
+		s.bindWriter(connection, chained)
+		defer s.unbindWriter(connection)
+	}
+
 	if err := s.interceptor.Init(connection); err != nil {
 		fmt.Println("error while connection init; dropping client")
 		fmt.Println("dropping client...")
 		return
 	}
 
+	// NOTE: THE EVENTS ARE SOURCED FROM THE CONNECTION; THE SOCKET SERVES EVERY CONNECTION UNDER THE SAME ID
+	events.Publish(s.ctx, events.Event{Kind: events.Connected, Source: interceptor.ClientID(iD), Connection: connection})
+	defer func() {
+		client, _ := interceptor.Get(interceptor.AttributesOf(connection), interceptor.ClientIDKey)
+		events.Publish(s.ctx, events.Event{Kind: events.Disconnected, Source: interceptor.ClientID(iD), Connection: connection, Client: client})
+	}()
+
 	connection.WaitUntilClose()
 }
 
+// EnableInterceptor enables the named interceptor for the connection at runtime (see interceptor.Chain.Enable)
+func (s *Socket) EnableInterceptor(connectionID string, name string) error {
+	chain, connection, err := s.switchable(connectionID)
+	if err != nil {
+		return err
+	}
+
+	return chain.Enable(connection, name)
+}
+
+// DisableInterceptor disables the named interceptor for the connection at runtime (see interceptor.Chain.Disable)
+func (s *Socket) DisableInterceptor(connectionID string, name string) error {
+	chain, connection, err := s.switchable(connectionID)
+	if err != nil {
+		return err
+	}
+
+	return chain.Disable(connection, name)
+}
+
+func (s *Socket) switchable(connectionID string) (*interceptor.Chain, interceptor.Connection, error) {
+	chain, ok := s.interceptor.(*interceptor.Chain)
+	if !ok {
+		return nil, nil, interceptor.ErrInterceptorNotFound
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	connection, exists := s.connections[connectionID]
+	if !exists {
+		return nil, nil, interceptor.ErrConnectionNotFound
+	}
+
+	return chain, connection, nil
+}
+
+// selectInterceptors selects the interceptors of the connection with the selector of the chain's context, if
+// any, and the selection made at the upgrade (see Settings.SelectInterceptors)
+func selectInterceptors(selector interceptor.Selector) interceptor.Selector {
+	return func(connection interceptor.Connection, name string) bool {
+		if selector != nil && !selector(connection, name) {
+			return false
+		}
+
+		a, ok := connection.(*adaptor)
+		if !ok || a.selects == nil {
+			return true
+		}
+
+		return a.selects(name)
+	}
+}
+
 func (s *Socket) ShutDown(ctx context.Context) error {
 	ctx2, cancel := context.WithTimeout(ctx, s.settings.ShutdownTimout)
 	defer cancel()
@@ -252,6 +482,18 @@ func (s *Socket) handleHealth(w http.ResponseWriter, _ *http.Request) {
 
 // handleMetrics exposes server metrics
 func (s *Socket) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	priorities, err := json.Marshal(s.priorityMetrics())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	interceptorErrors, err := json.Marshal(s.errorMetrics())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	s.metrics.mux.RLock()
 	defer s.metrics.mux.RUnlock()
 
@@ -259,7 +501,81 @@ func (s *Socket) handleMetrics(w http.ResponseWriter, _ *http.Request) {
 	_, _ = fmt.Fprintf(w, `{
         "active_connections": %d,
         "total_connections": %d,
-        "failed_connections": %d
+        "failed_connections": %d,
+        "expired_on_write": %d,
+        "expired_on_read": %d,
+        "priorities": %s,
+        "interceptor_errors": %s,
+        "recovered_panics": %d
     }`, s.metrics.ActiveConnections, s.metrics.TotalConnections,
-		s.metrics.FailedConnections)
+		s.metrics.FailedConnections, s.metrics.ExpiredOnWrite, s.metrics.ExpiredOnRead, priorities, interceptorErrors, interceptor.Panics())
+}
+
+// errorMetrics returns the errors handled by the error policies of the interceptor chain, by interceptor
+func (s *Socket) errorMetrics() map[string]interceptor.ErrorStats {
+	chain, ok := s.interceptor.(*interceptor.Chain)
+	if !ok {
+		return map[string]interceptor.ErrorStats{}
+	}
+
+	return chain.ErrorStats()
+}
+
+// priorityMetrics sums the write path statistics of every priority class over all the connections
+func (s *Socket) priorityMetrics() map[string]ClassMetrics {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	metrics := make(map[string]ClassMetrics, len(interceptor.Priorities))
+	for _, priority := range interceptor.Priorities {
+		metrics[priority.String()] = ClassMetrics{Priority: priority}
+	}
+
+	for _, connection := range s.connections {
+		p, ok := connection.(prioritized)
+		if !ok {
+			continue
+		}
+
+		for _, class := range p.PriorityMetrics() {
+			m := metrics[class.Priority.String()]
+			m.add(class)
+			metrics[class.Priority.String()] = m
+		}
+	}
+
+	return metrics
+}
+
+// handleSchema exposes the JSON Schema of the registered messages.
+// An optional "protocol" query parameter limits the document to a single message.
+func (s *Socket) handleSchema(w http.ResponseWriter, r *http.Request) {
+	var (
+		schema *message.Schema
+		err    error
+	)
+
+	if protocol := r.URL.Query().Get("protocol"); protocol != "" {
+		schema, err = message.GenerateSchema(s.messageRegistry, message.Protocol(protocol))
+	} else {
+		schema, err = message.GenerateSchemas(s.messageRegistry)
+	}
+
+	if err != nil {
+		if errors.Is(err, message.ErrNoProtocolMatch) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := schema.Marshal()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	_, _ = w.Write(data)
 }
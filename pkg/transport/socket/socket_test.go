@@ -0,0 +1,105 @@
+package socket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/harshabose/socket-comm/pkg/events"
+	"github.com/harshabose/socket-comm/pkg/interceptor"
+	"github.com/harshabose/socket-comm/pkg/interceptor/interceptortest"
+	"github.com/harshabose/socket-comm/pkg/message"
+)
+
+func nextEvent(t *testing.T, s *events.Subscription) events.Event {
+	t.Helper()
+
+	select {
+	case event := <-s.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event published")
+		return events.Event{}
+	}
+}
+
+func TestSocket_ConnectionEventsAreSourcedFromTheConnection(t *testing.T) {
+	bus := events.NewBus()
+	t.Cleanup(func() { _ = bus.Close() })
+
+	subscription, err := bus.Subscribe(events.WithKinds(events.Connected, events.Disconnected))
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	ctx := events.WithBus(context.Background(), bus)
+	s := NewSocket(ctx, NewDefaultSettings(), message.NewDefaultRegistry())
+	t.Cleanup(s.cancel)
+
+	noop := interceptor.NewNoOpInterceptor(ctx, "test", message.NewDefaultRegistry())
+	s.interceptor = &noop
+
+	server := httptest.NewServer(http.HandlerFunc(s.handleWebSocket))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+
+	connected := nextEvent(t, subscription)
+	if connected.Kind != events.Connected || connected.Connection == nil {
+		t.Fatalf("event = %+v, want the connected event of the connection", connected)
+	}
+
+	if want := interceptor.ClientID(connected.Connection.(*adaptor).ID()); connected.Source != want || connected.Source == interceptor.ClientID(s.ID) {
+		t.Errorf("connected source = %q, want the connection id %q", connected.Source, want)
+	}
+
+	_ = conn.Close(websocket.StatusNormalClosure, "")
+
+	disconnected := nextEvent(t, subscription)
+	if disconnected.Kind != events.Disconnected || disconnected.Source != connected.Source {
+		t.Errorf("event = %+v, want the disconnected event sourced from %q", disconnected, connected.Source)
+	}
+}
+
+func TestSocket_RejectsUnsupportedVersion(t *testing.T) {
+	s := NewSocket(context.Background(), NewDefaultSettings(), message.NewDefaultRegistry())
+	t.Cleanup(s.cancel)
+
+	noop := interceptor.NewNoOpInterceptor(context.Background(), "test", message.NewDefaultRegistry())
+	s.interceptor = &noop
+
+	server := httptest.NewServer(http.HandlerFunc(s.handleWebSocket))
+	t.Cleanup(server.Close)
+
+	_, response, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"?version=v99.0", nil)
+	if err == nil {
+		t.Fatal("Dial() error = nil, want the unsupported version rejected")
+	}
+
+	if response == nil || response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Dial() response = %v, want %d", response, http.StatusBadRequest)
+	}
+}
+
+func TestSocket_RepliesThroughTheInterceptors(t *testing.T) {
+	s := NewSocket(context.Background(), NewDefaultSettings(), message.NewDefaultRegistry())
+	t.Cleanup(s.cancel)
+
+	connection := interceptortest.NewConnection("test")
+	chained := interceptortest.NewWriter()
+	s.bindWriter(connection, chained)
+
+	s.replyValidationError(connection, message.NewValidationError("field", "reason"))
+
+	if written := chained.Messages(); len(written) != 1 {
+		t.Errorf("written %d replies through the interceptors, want 1", len(written))
+	}
+}